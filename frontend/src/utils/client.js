@@ -35,6 +35,41 @@ export default class Client {
 		});
 	};
 
+	static getFailedTasks = async () => {
+		return await fetch(`${Client.apiUrl}/queue/failed`).then((response) => response.json());
+	};
+
+	static retryFailedTask = async (taskId) => {
+		return await fetch(`${Client.apiUrl}/queue/failed/${taskId}/retry`, {
+			method: 'POST',
+		});
+	};
+
+	static discardFailedTask = async (taskId) => {
+		return await fetch(`${Client.apiUrl}/queue/failed/${taskId}`, {
+			method: 'DELETE',
+		});
+	};
+
+	static bumpTask = async (taskId) => {
+		return await fetch(`${Client.apiUrl}/queue/tasks/${taskId}/bump`, {
+			method: 'POST',
+		});
+	};
+
+	static cancelTask = async (taskId) => {
+		return await fetch(`${Client.apiUrl}/queue/tasks/${taskId}`, {
+			method: 'DELETE',
+		});
+	};
+
+	static reorderTasks = async (taskIds, priority, position) => {
+		return await fetch(`${Client.apiUrl}/queue/reorder`, {
+			method: 'POST',
+			body: JSON.stringify({ tasks: taskIds, priority: priority, position: position }),
+		});
+	};
+
 	static continueProcessingTasks = async () => {
 		return await fetch(`${Client.apiUrl}/queue/continue`, {
 			method: 'POST',
@@ -101,7 +136,121 @@ export default class Client {
 	};
 
 	static getItems = async () => {
-		return await fetch(`${Client.apiUrl}/items`).then((response) => response.json());
+		return await fetch(`${Client.apiUrl}/items`)
+			.then((response) => response.json())
+			.then((page) => page.items);
+	};
+
+	static search = async (text, subtitles, limit) => {
+		const params = new URLSearchParams({ q: text, subtitles: !!subtitles, limit: limit || 0 });
+		return await fetch(`${Client.apiUrl}/search?${params}`).then((response) => response.json());
+	};
+
+	static reportProgress = async (itemId, positionSeconds, completed) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/progress`, {
+			method: 'POST',
+			body: JSON.stringify({ position_seconds: positionSeconds, completed: !!completed }),
+		});
+	};
+
+	static bulkItems = async (request) => {
+		return await fetch(`${Client.apiUrl}/items/bulk`, {
+			method: 'POST',
+			body: JSON.stringify(request),
+		}).then((response) => response.json());
+	};
+
+	static setItemRating = async (itemId, rating) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/rating`, {
+			method: 'POST',
+			body: JSON.stringify({ rating }),
+		});
+	};
+
+	static setItemFavorite = async (itemId, favorite) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/favorite`, {
+			method: 'POST',
+			body: JSON.stringify({ favorite: !!favorite }),
+		});
+	};
+
+	static setItemMetadata = async (itemId, metadata) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/metadata`, {
+			method: 'POST',
+			body: JSON.stringify(metadata),
+		});
+	};
+
+	static getScrapeCandidates = async (itemId, title, year) => {
+		let params = new URLSearchParams();
+		if (title) {
+			params.append('title', title);
+		}
+		if (year) {
+			params.append('year', year);
+		}
+		return await fetch(`${Client.apiUrl}/items/${itemId}/scrape/candidates?${params.toString()}`).then(
+			(response) => response.json()
+		);
+	};
+
+	static scrapeItem = async (itemId, provider, id) => {
+		let params = new URLSearchParams();
+		if (provider && id) {
+			params.append('provider', provider);
+			params.append('id', id);
+		}
+		return await fetch(`${Client.apiUrl}/items/${itemId}/scrape?${params.toString()}`, { method: 'POST' }).then(
+			(response) => response.json()
+		);
+	};
+
+	static scrapeMissing = async () => {
+		return await fetch(`${Client.apiUrl}/scraper/scrape-missing`, { method: 'POST' });
+	};
+
+	static importRatings = async (ratings) => {
+		return await fetch(`${Client.apiUrl}/ratings/import`, {
+			method: 'POST',
+			body: JSON.stringify(ratings),
+		}).then((response) => response.json());
+	};
+
+	static getDuplicates = async (maxDistance, durationTolerance) => {
+		let params = new URLSearchParams();
+		if (maxDistance !== undefined) {
+			params.append('maxDistance', maxDistance);
+		}
+		if (durationTolerance !== undefined) {
+			params.append('durationTolerance', durationTolerance);
+		}
+		return await fetch(`${Client.apiUrl}/duplicates?${params}`).then((response) => response.json());
+	};
+
+	static mergeDuplicates = async (keeperId, duplicates, deleteRealFiles) => {
+		return await fetch(`${Client.apiUrl}/duplicates/merge`, {
+			method: 'POST',
+			body: JSON.stringify({ keeperId, duplicates, deleteRealFiles }),
+		});
+	};
+
+	static hashAllItems = async (force) => {
+		return await fetch(`${Client.apiUrl}/duplicates/hash?force=${force}`, {
+			method: 'POST',
+		});
+	};
+
+	static getResumePosition = async (itemId) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/resume`).then((response) => response.json());
+	};
+
+	static getContinueWatching = async () => {
+		return await fetch(`${Client.apiUrl}/continue-watching`).then((response) => response.json());
+	};
+
+	static getWatchHistory = async (offset, limit) => {
+		const params = new URLSearchParams({ offset: offset || 0, limit: limit || 0 });
+		return await fetch(`${Client.apiUrl}/watch-history?${params}`).then((response) => response.json());
 	};
 
 	static getSubtitle = async (url) => {
@@ -139,8 +288,16 @@ export default class Client {
 		});
 	};
 
-	static getSuggestedItems = async (itemId) => {
-		return await fetch(`${Client.apiUrl}/items/${itemId}/suggestions`).then((response) => response.json());
+	static getSuggestions = async (itemId, count = 8) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/suggestions?count=${count}`).then((response) =>
+			response.json()
+		);
+	};
+
+	static getSuggestedItems = async (itemId, count = 8) => {
+		return await Client.getSuggestions(itemId, count).then((suggestions) =>
+			suggestions.map((suggestion) => suggestion.item)
+		);
 	};
 
 	static getItem = async (itemId) => {
@@ -333,6 +490,69 @@ export default class Client {
 		fetch(`${Client.apiUrl}/directories/scan`, { method: 'POST' });
 	}
 
+	static scanDirectory = async (path) => {
+		return await fetch(`${Client.apiUrl}/directories/scan?path=${encodeURIComponent(path)}`, { method: 'POST' });
+	};
+
+	static getSyncPlan = async (paths) => {
+		let query = (paths || []).map((path) => `path=${encodeURIComponent(path)}`).join('&');
+		return await fetch(`${Client.apiUrl}/fs/sync/plan?${query}`).then((response) => response.json());
+	};
+
+	static getHeldSyncPlan = async () => {
+		return await fetch(`${Client.apiUrl}/fs/sync/held`).then((response) => response.json());
+	};
+
+	static applySyncPlan = async (id) => {
+		return await fetch(`${Client.apiUrl}/fs/sync/held/${id}/apply`, { method: 'POST' });
+	};
+
+	static discardSyncPlan = async (id) => {
+		return await fetch(`${Client.apiUrl}/fs/sync/held/${id}`, { method: 'DELETE' });
+	};
+
+	static getTrash = async () => {
+		return await fetch(`${Client.apiUrl}/trash`).then((response) => response.json());
+	};
+
+	static restoreItem = async (itemId) => {
+		return await fetch(`${Client.apiUrl}/trash/items/${itemId}/restore`, { method: 'POST' });
+	};
+
+	static restoreTag = async (tagId) => {
+		return await fetch(`${Client.apiUrl}/trash/tags/${tagId}/restore`, { method: 'POST' });
+	};
+
+	static purgeItem = async (itemId) => {
+		return await fetch(`${Client.apiUrl}/trash/items/${itemId}`, { method: 'DELETE' });
+	};
+
+	static purgeTag = async (tagId) => {
+		return await fetch(`${Client.apiUrl}/trash/tags/${tagId}`, { method: 'DELETE' });
+	};
+
+	static purgeTrash = async () => {
+		return await fetch(`${Client.apiUrl}/trash/purge`, { method: 'POST' }).then((response) => response.json());
+	};
+
+	static collectStorageGarbage = async (dryRun) => {
+		return await fetch(`${Client.apiUrl}/storage/gc?dryRun=${dryRun}`, { method: 'POST' }).then((response) =>
+			response.json()
+		);
+	};
+
+	static exportItemNfo = async (itemId) => {
+		return await fetch(`${Client.apiUrl}/items/${itemId}/nfo`, { method: 'POST' });
+	};
+
+	static exportAllNfo = async () => {
+		return await fetch(`${Client.apiUrl}/nfo/export`, { method: 'POST' }).then((response) => response.json());
+	};
+
+	static getItemNfoUrl(itemId) {
+		return `${Client.apiUrl}/items/${itemId}/nfo`;
+	}
+
 	static getExportMetadataUrl() {
 		return `${Client.apiUrl}/export-metadata.json`;
 	}
@@ -5,6 +5,7 @@ export default class TagsUtil {
 	static dailyMixTag;
 	static highlightsTag;
 	static specTag;
+	static smartTag;
 	static categories = [];
 
 	static initCategories(categories) {
@@ -25,6 +26,9 @@ export default class TagsUtil {
 			} else if (specialTags[i].title === 'Spec') {
 				// spectagger.go
 				TagsUtil.specTag = specialTags[i];
+			} else if (specialTags[i].title === 'Smart') {
+				// smarttags.go
+				TagsUtil.smartTag = specialTags[i];
 			} else if (specialTags[i].title === 'Highlights') {
 				// highlights.go
 				TagsUtil.highlightsTag = specialTags[i];
@@ -36,6 +40,7 @@ export default class TagsUtil {
 			!TagsUtil.dailyMixTag ||
 			!TagsUtil.highlightsTag ||
 			!TagsUtil.specTag ||
+			!TagsUtil.smartTag ||
 			!TagsUtil.mixOnDemandTag
 		) {
 			console.log('Missing mandatory special tags');
@@ -62,12 +67,17 @@ export default class TagsUtil {
 		return tagId === TagsUtil.specTag.id;
 	}
 
+	static isSmartCategory(tagId) {
+		return tagId === TagsUtil.smartTag.id;
+	}
+
 	static isSpecialCategory(tagId) {
 		return (
 			TagsUtil.isDirectoriesCategory(tagId) ||
 			TagsUtil.isDailymixCategory(tagId) ||
 			TagsUtil.isMixOnDemandCategory(tagId) ||
 			TagsUtil.isSpecCategory(tagId) ||
+			TagsUtil.isSmartCategory(tagId) ||
 			TagsUtil.isHighlightsCategory(tagId)
 		);
 	}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/app"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/utils"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <archive.tar.gz>",
+	Short: "Write a backup archive of the database and storage, run it while the server is down",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBackup(cmd, args[0]); err != nil {
+			utils.LogError("Error in backup", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive.tar.gz>",
+	Short: "Restore a backup archive into the database and storage, run it while the server is down",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRestore(cmd, args[0]); err != nil {
+			utils.LogError("Error in restore", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// rootDirectory prefers the command flag, falling back to the config file and environment
+func rootDirectory(cmd *cobra.Command) string {
+	if rootDir, _ := cmd.Flags().GetString("root-directory"); rootDir != "" {
+		return rootDir
+	}
+
+	return viper.GetString("root-directory")
+}
+
+func runBackup(cmd *cobra.Command, archive string) error {
+	withoutStorage, _ := cmd.Flags().GetBool("without-storage")
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := app.Backup(context.Background(), rootDirectory(cmd), f, !withoutStorage); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func runRestore(cmd *cobra.Command, archive string) error {
+	conflict, _ := cmd.Flags().GetString("conflict")
+	policy := model.ConflictPolicy(conflict)
+	if policy != model.CONFLICT_SKIP && policy != model.CONFLICT_OVERWRITE {
+		return fmt.Errorf("unknown conflict policy %s, expected %s or %s", conflict, model.CONFLICT_SKIP, model.CONFLICT_OVERWRITE)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := app.Restore(context.Background(), rootDirectory(cmd), f, policy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Items %+v\nTags %+v\nDirectories %+v\nStorage files %d\n",
+		result.Items, result.Tags, result.Directories, result.StorageFiles)
+	return nil
+}
+
+func init() {
+	backupCmd.Flags().String("root-directory", "", "Server root directory")
+	backupCmd.Flags().Bool("without-storage", false, "Only archive the database, without the covers, previews and images")
+	restoreCmd.Flags().String("root-directory", "", "Server root directory")
+	restoreCmd.Flags().String("conflict", string(model.CONFLICT_SKIP), "What to do with entities that already exist, skip or overwrite")
+
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
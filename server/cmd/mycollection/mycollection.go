@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"my-collection/server/pkg/app"
+	"my-collection/server/pkg/bl/playback"
+	"my-collection/server/pkg/nfo"
+	"my-collection/server/pkg/scraper/tmdb"
 	"my-collection/server/pkg/utils"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/op/go-logging"
 	"github.com/spf13/cobra"
@@ -36,10 +40,26 @@ func run() error {
 		MixOnDemandItemsCount:       viper.GetInt("mix-on-demand-items-count"),
 		ItemsOptimizerMaxResolution: viper.GetInt("items-optimizer-max-resolution"),
 		ProcessorPaused:             viper.GetBool("processor-paused"),
+		ProcessorMetadataWorkers:    viper.GetInt("processor-metadata-workers"),
+		ProcessorFfmpegWorkers:      viper.GetInt("processor-ffmpeg-workers"),
 		CoversCount:                 viper.GetInt("covers-count"),
 		PreviewSceneCount:           viper.GetInt("preview-scene-count"),
 		PreviewSceneDuration:        viper.GetInt("preview-scene-duration"),
 		OpenSubtitleApiKeys:         viper.GetStringSlice("open-subtitle-api-keys"),
+		WatchedThresholdPercent:     viper.GetFloat64("watched-threshold-percent"),
+		FsWatch:                     viper.GetBool("fs-watch"),
+		FullSyncInterval:            viper.GetDuration("full-sync-interval"),
+		SyncHoldFraction:            viper.GetFloat64("sync-hold-fraction"),
+		TrashRetention:              viper.GetDuration("trash-retention"),
+		StorageGcInterval:           viper.GetDuration("storage-gc-interval"),
+		NfoImport:                   viper.GetBool("nfo-import"),
+		NfoTagParents:               viper.GetStringSlice("nfo-tag-parents"),
+		MetadataProvider:            viper.GetString("metadata-provider"),
+		MetadataFixturesDir:         viper.GetString("metadata-fixtures-dir"),
+		TmdbApiUrl:                  viper.GetString("tmdb-api-url"),
+		TmdbImageUrl:                viper.GetString("tmdb-image-url"),
+		TmdbApiKey:                  viper.GetString("tmdb-api-key"),
+		TmdbLanguage:                viper.GetString("tmdb-language"),
 	}
 
 	config.DebugPrint()
@@ -81,6 +101,24 @@ func init() {
 	rootCmd.Flags().Int("mix-on-demand-items-count", 30, "Number of items for mix on demand")
 	rootCmd.Flags().Int("items-optimizer-max-resolution", 1080, "Maximum resolution for items optimizer")
 	rootCmd.Flags().Bool("processor-paused", false, "Whether the processor is paused")
+	rootCmd.Flags().Int("processor-metadata-workers", 4, "Number of concurrent metadata tasks, such as reading file and video metadata")
+	rootCmd.Flags().Int("processor-ffmpeg-workers", 1, "Number of concurrent ffmpeg tasks, such as covers, previews and transcodes")
+	rootCmd.Flags().Bool("fs-watch", true, "Watch the root directory for changes and sync them as they happen")
+	rootCmd.Flags().Duration("full-sync-interval", 30*time.Minute, "Interval of the full root directory sync")
+	rootCmd.Flags().Duration("trash-retention", 30*24*time.Hour, "Deleted items and tags are purged from the trash after this duration, 0 keeps them")
+	rootCmd.Flags().Duration("storage-gc-interval", 24*time.Hour, "Interval of deleting storage files no longer referenced by any item or tag, 0 disables")
+	rootCmd.Flags().Float64("sync-hold-fraction", 0.1, "Hold syncs removing more than this fraction of the items until confirmed, 0 to disable")
+	rootCmd.Flags().Float64("watched-threshold-percent", playback.DefaultWatchedThresholdPercent, "Percentage of an item that must be played to flag it as watched")
+
+	// Metadata configuration flags
+	rootCmd.Flags().Bool("nfo-import", true, "Import the Kodi/Jellyfin nfo next to new files as tags")
+	rootCmd.Flags().StringSlice("nfo-tag-parents", nfo.DefaultTagParents, "The parent tag of each imported nfo field, as field=Title pairs (fields: "+strings.Join(nfo.Fields, ", ")+")")
+	rootCmd.Flags().String("metadata-provider", "", "Scrape movie metadata, posters and tags from a provider (tmdb, fixtures), empty disables")
+	rootCmd.Flags().String("metadata-fixtures-dir", "", "Directory of the json files served by the fixtures metadata provider")
+	rootCmd.Flags().String("tmdb-api-url", tmdb.DefaultApiUrl, "Base url of the TMDB compatible api")
+	rootCmd.Flags().String("tmdb-image-url", tmdb.DefaultImageUrl, "Base url of the TMDB compatible posters")
+	rootCmd.Flags().String("tmdb-api-key", "", "TMDB api key or read access token")
+	rootCmd.Flags().String("tmdb-language", "", "Language of the TMDB titles and descriptions, such as en-US")
 
 	// Media configuration flags
 	rootCmd.Flags().Int("covers-count", 0, "Number of covers to generate")
@@ -6,18 +6,27 @@ import (
 	"my-collection/server/pkg/automix"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/ratings"
 	"my-collection/server/pkg/db"
 	"my-collection/server/pkg/fssync"
 	"my-collection/server/pkg/itemsoptimizer"
+	"my-collection/server/pkg/mixer"
 	"my-collection/server/pkg/mixondemand"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/nfo"
 	"my-collection/server/pkg/opensubtitles"
 	"my-collection/server/pkg/processor"
 	"my-collection/server/pkg/relativasor"
+	"my-collection/server/pkg/scraper"
+	"my-collection/server/pkg/scraper/tmdb"
 	"my-collection/server/pkg/server"
 	"my-collection/server/pkg/server/push"
+	"my-collection/server/pkg/smarttags"
 	"my-collection/server/pkg/spectagger"
 	"my-collection/server/pkg/storage"
+	"my-collection/server/pkg/storagegc"
 	"my-collection/server/pkg/thumbnails"
+	"my-collection/server/pkg/trash"
 	"my-collection/server/pkg/utils"
 	"os"
 	"os/signal"
@@ -46,22 +55,25 @@ type MyCollection struct {
 	automix        *automix.Automix
 	mixondemand    *mixondemand.MixOnDemand
 	spectagger     *spectagger.Spectagger
+	smarttags      *smarttags.SmartTags
 	itemsoptimizer *itemsoptimizer.ItemsOptimizer
 	thumbnails     *thumbnails.Thumbnails
+	trash          *trash.Trash
+	storagegc      *storagegc.StorageGc
+	nfo            *nfo.NfoTagger
+	scraper        *scraper.Scraper
 	server         *server.Server
 	push           push.PushHandler
 	opensubtitles  *opensubtitles.OpenSubtitiles
+	config         MyCollectionConfig
 }
 
 func (mc *MyCollection) initialize(config MyCollectionConfig) error {
 	ctx := utils.ContextWithSubject(context.TODO(), "init")
+	mc.config = config
 
-	if err := relativasor.Init(config.RootDir); err != nil {
-		return err
-	}
-
-	dataDir := path.Join(relativasor.GetRootDirectory(), ".mycollection")
-	if err := os.MkdirAll(dataDir, 0750); err != nil {
+	dataDir, err := dataDirectory(config.RootDir)
+	if err != nil {
 		return err
 	}
 
@@ -77,7 +89,8 @@ func (mc *MyCollection) initialize(config MyCollectionConfig) error {
 		return err
 	}
 
-	mc.processor, err = processor.New(db, storage, config.ProcessorPaused, config.CoversCount, config.PreviewSceneCount, config.PreviewSceneDuration)
+	mc.processor, err = processor.New(db, storage, config.ProcessorPaused, config.CoversCount, config.PreviewSceneCount, config.PreviewSceneDuration,
+		config.ProcessorMetadataWorkers, config.ProcessorFfmpegWorkers)
 	if err != nil {
 		return err
 	}
@@ -88,17 +101,35 @@ func (mc *MyCollection) initialize(config MyCollectionConfig) error {
 	if err := directories.Init(ctx, db); err != nil {
 		return err
 	}
-	mc.fsManager, err = fssync.NewFsManager(ctx, db, config.FilesFilter, 60*time.Second)
+	nfoTagParents, err := nfo.ParseTagParents(config.NfoTagParents)
 	if err != nil {
 		return err
 	}
 
-	mc.automix, err = automix.New(ctx, db, config.AutoMixItemsCount)
+	mc.nfo, err = nfo.New(ctx, db, nfoTagParents)
 	if err != nil {
 		return err
 	}
 
-	mc.mixondemand, err = mixondemand.New(ctx, db, config.MixOnDemandItemsCount)
+	var metadataImporter model.ItemMetadataImporter
+	if config.NfoImport {
+		metadataImporter = mc.nfo
+	}
+
+	metadataProvider, err := newMetadataProvider(config)
+	if err != nil {
+		return err
+	}
+
+	if metadataProvider != nil {
+		mc.scraper, err = scraper.New(ctx, db, storage, metadataProvider, nfoTagParents)
+		if err != nil {
+			return err
+		}
+	}
+
+	mc.fsManager, err = fssync.NewFsManager(ctx, db, config.FilesFilter, config.FullSyncInterval, config.FsWatch,
+		config.SyncHoldFraction, metadataImporter)
 	if err != nil {
 		return err
 	}
@@ -108,10 +139,28 @@ func (mc *MyCollection) initialize(config MyCollectionConfig) error {
 		return err
 	}
 
+	mixStrategy := mixer.NewWeighted(db, mc.spectagger, &ratings.PreferencesWeigher{})
+	mc.automix, err = automix.New(ctx, db, mixStrategy, config.AutoMixItemsCount)
+	if err != nil {
+		return err
+	}
+
+	mc.mixondemand, err = mixondemand.New(ctx, db, mixStrategy, config.MixOnDemandItemsCount)
+	if err != nil {
+		return err
+	}
+
+	mc.smarttags, err = smarttags.New(ctx, db)
+	if err != nil {
+		return err
+	}
+
 	mc.opensubtitles = opensubtitles.NewOpenSubtitles(config.OpenSubtitleApiKeys)
 
 	mc.itemsoptimizer = itemsoptimizer.New(db, mc.processor, config.ItemsOptimizerMaxResolution)
 	mc.thumbnails = thumbnails.New(db, db, storage, 100, 100)
+	mc.trash = trash.New(db, storage, config.TrashRetention)
+	mc.storagegc = storagegc.New(db, storage, config.StorageGcInterval, storagegc.DefaultMinAge)
 	mc.server = server.New(config.ListenAddress)
 	mc.push = push.NewPush()
 
@@ -123,6 +172,35 @@ func (mc *MyCollection) initialize(config MyCollectionConfig) error {
 	return nil
 }
 
+func newMetadataProvider(config MyCollectionConfig) (scraper.MetadataProvider, error) {
+	switch config.MetadataProvider {
+	case "":
+		return nil, nil
+	case model.EXTERNAL_ID_TMDB:
+		if config.TmdbApiKey == "" {
+			return nil, fmt.Errorf("tmdb metadata provider requires an api key")
+		}
+		return tmdb.New(config.TmdbApiUrl, config.TmdbImageUrl, config.TmdbApiKey, config.TmdbLanguage), nil
+	case "fixtures":
+		return scraper.NewFixturesProvider(model.EXTERNAL_ID_TMDB, config.MetadataFixturesDir)
+	default:
+		return nil, fmt.Errorf("unknown metadata provider %s", config.MetadataProvider)
+	}
+}
+
+func dataDirectory(rootDir string) (string, error) {
+	if err := relativasor.Init(rootDir); err != nil {
+		return "", err
+	}
+
+	dataDir := path.Join(relativasor.GetRootDirectory(), ".mycollection")
+	if err := os.MkdirAll(dataDir, 0750); err != nil {
+		return "", err
+	}
+
+	return dataDir, nil
+}
+
 func (mc *MyCollection) Run(ctx context.Context) error {
 	eg, ctx := errgroup.WithContext(ctx)
 	if err := mc.runWithErrgroup(ctx, eg); err != nil {
@@ -152,6 +230,10 @@ func (mc *MyCollection) runWithErrgroup(ctx context.Context, eg *errgroup.Group)
 		return mc.spectagger.Run(ctx)
 	})
 
+	eg.Go(func() error {
+		return mc.smarttags.Run(ctx)
+	})
+
 	eg.Go(func() error {
 		return mc.itemsoptimizer.Run(ctx)
 	})
@@ -160,6 +242,20 @@ func (mc *MyCollection) runWithErrgroup(ctx context.Context, eg *errgroup.Group)
 		return mc.thumbnails.Run(ctx)
 	})
 
+	eg.Go(func() error {
+		return mc.trash.Run(ctx)
+	})
+
+	eg.Go(func() error {
+		return mc.storagegc.Run(ctx)
+	})
+
+	if mc.scraper != nil {
+		eg.Go(func() error {
+			return mc.scraper.Run(ctx)
+		})
+	}
+
 	eg.Go(func() error {
 		return mc.push.Run(ctx)
 	})
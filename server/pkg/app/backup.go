@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"io"
+	"my-collection/server/pkg/backup"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	"path/filepath"
+)
+
+// Backup writes an archive of the collection in rootDir, it should run while the server is down
+func Backup(ctx context.Context, rootDir string, w io.Writer, includeStorage bool) error {
+	dataDir, err := dataDirectory(rootDir)
+	if err != nil {
+		return err
+	}
+
+	db, err := db.New(filepath.Join(dataDir, "db.sqlite"), false)
+	if err != nil {
+		return err
+	}
+
+	storageDir := ""
+	if includeStorage {
+		storage, err := storage.New(filepath.Join(dataDir, "storage"))
+		if err != nil {
+			return err
+		}
+
+		storageDir = storage.GetStorageDirectory("")
+	}
+
+	return backup.ExportArchive(ctx, db, storageDir, w)
+}
+
+// Restore imports an archive into the collection in rootDir, it should run while the server is down
+func Restore(ctx context.Context, rootDir string, r io.Reader, policy model.ConflictPolicy) (*model.RestoreResult, error) {
+	dataDir, err := dataDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := db.New(filepath.Join(dataDir, "db.sqlite"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := storage.New(filepath.Join(dataDir, "storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.ImportArchive(ctx, db, storage.GetStorageDirectory(""), r, policy)
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"my-collection/server/pkg/directorytree"
 	"strings"
+	"time"
 )
 
 type MyCollectionConfig struct {
@@ -13,10 +14,26 @@ type MyCollectionConfig struct {
 	MixOnDemandItemsCount       int
 	ItemsOptimizerMaxResolution int
 	ProcessorPaused             bool
+	ProcessorMetadataWorkers    int
+	ProcessorFfmpegWorkers      int
 	CoversCount                 int
 	PreviewSceneCount           int
 	PreviewSceneDuration        int
 	OpenSubtitleApiKeys         []string
+	WatchedThresholdPercent     float64
+	FsWatch                     bool
+	FullSyncInterval            time.Duration
+	SyncHoldFraction            float64
+	TrashRetention              time.Duration
+	StorageGcInterval           time.Duration
+	NfoImport                   bool
+	NfoTagParents               []string
+	MetadataProvider            string
+	MetadataFixturesDir         string
+	TmdbApiUrl                  string
+	TmdbImageUrl                string
+	TmdbApiKey                  string
+	TmdbLanguage                string
 }
 
 func (c *MyCollectionConfig) DebugPrint() {
@@ -28,9 +45,25 @@ func (c *MyCollectionConfig) DebugPrint() {
 	logger.Debugf("  %-30s %d", "MixOnDemandItemsCount:", c.MixOnDemandItemsCount)
 	logger.Debugf("  %-30s %d", "ItemsOptimizerMaxResolution:", c.ItemsOptimizerMaxResolution)
 	logger.Debugf("  %-30s %t", "ProcessorPaused:", c.ProcessorPaused)
+	logger.Debugf("  %-30s %d", "ProcessorMetadataWorkers:", c.ProcessorMetadataWorkers)
+	logger.Debugf("  %-30s %d", "ProcessorFfmpegWorkers:", c.ProcessorFfmpegWorkers)
 	logger.Debugf("  %-30s %d", "CoversCount:", c.CoversCount)
 	logger.Debugf("  %-30s %d", "PreviewSceneCount:", c.PreviewSceneCount)
 	logger.Debugf("  %-30s %d", "PreviewSceneDuration:", c.PreviewSceneDuration)
 	logger.Debugf("  %-30s %v", "OpenSubtitleApiKeys:", c.OpenSubtitleApiKeys)
+	logger.Debugf("  %-30s %.1f", "WatchedThresholdPercent:", c.WatchedThresholdPercent)
+	logger.Debugf("  %-30s %t", "FsWatch:", c.FsWatch)
+	logger.Debugf("  %-30s %s", "FullSyncInterval:", c.FullSyncInterval)
+	logger.Debugf("  %-30s %.2f", "SyncHoldFraction:", c.SyncHoldFraction)
+	logger.Debugf("  %-30s %s", "TrashRetention:", c.TrashRetention)
+	logger.Debugf("  %-30s %s", "StorageGcInterval:", c.StorageGcInterval)
+	logger.Debugf("  %-30s %t", "NfoImport:", c.NfoImport)
+	logger.Debugf("  %-30s %v", "NfoTagParents:", c.NfoTagParents)
+	logger.Debugf("  %-30s %s", "MetadataProvider:", c.MetadataProvider)
+	logger.Debugf("  %-30s %s", "MetadataFixturesDir:", c.MetadataFixturesDir)
+	logger.Debugf("  %-30s %s", "TmdbApiUrl:", c.TmdbApiUrl)
+	logger.Debugf("  %-30s %s", "TmdbImageUrl:", c.TmdbImageUrl)
+	logger.Debugf("  %-30s %t", "TmdbApiKey set:", c.TmdbApiKey != "")
+	logger.Debugf("  %-30s %s", "TmdbLanguage:", c.TmdbLanguage)
 	logger.Debugf(strings.Repeat("-", 50))
 }
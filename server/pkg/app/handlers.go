@@ -8,15 +8,25 @@ import (
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/opensubtitles"
 	"my-collection/server/pkg/processor"
+	"my-collection/server/pkg/server/duplicates"
 	"my-collection/server/pkg/server/fs"
 	"my-collection/server/pkg/server/items"
 	"my-collection/server/pkg/server/management"
+	metadataHandler "my-collection/server/pkg/server/metadata"
+	nfoHandler "my-collection/server/pkg/server/nfo"
+	"my-collection/server/pkg/server/playback"
+	"my-collection/server/pkg/server/ratings"
+	scraperHandler "my-collection/server/pkg/server/scraper"
+	"my-collection/server/pkg/server/search"
+	smartTagsHandler "my-collection/server/pkg/server/smarttags"
 	storageHandler "my-collection/server/pkg/server/storage"
 	"my-collection/server/pkg/server/subtitles"
 	"my-collection/server/pkg/server/tags"
 	"my-collection/server/pkg/server/tasks"
+	trashHandler "my-collection/server/pkg/server/trash"
 	"my-collection/server/pkg/spectagger"
 	"my-collection/server/pkg/storage"
+	"my-collection/server/pkg/storagegc"
 )
 
 func (mc *MyCollection) registerHandlers(db db.Database, storage *storage.Storage, fsm *fssync.FsManager) {
@@ -25,15 +35,27 @@ func (mc *MyCollection) registerHandlers(db db.Database, storage *storage.Storag
 	mc.server.RegisterHandler(storageHandler.NewHandler(storage))
 	mc.server.RegisterHandler(fs.NewHandler(db, fsm))
 	mc.server.RegisterHandler(tasks.NewHandler(db, mc.processor))
+	mc.server.RegisterHandler(search.NewHandler(db))
+	mc.server.RegisterHandler(smartTagsHandler.NewHandler(mc.smarttags))
+	mc.server.RegisterHandler(playback.NewHandler(db, mc.config.WatchedThresholdPercent))
+	mc.server.RegisterHandler(ratings.NewHandler(db))
+	mc.server.RegisterHandler(duplicates.NewHandler(db, mc.processor))
+	mc.server.RegisterHandler(trashHandler.NewHandler(mc.trash))
+	mc.server.RegisterHandler(metadataHandler.NewHandler(db))
+	mc.server.RegisterHandler(nfoHandler.NewHandler(mc.nfo))
+	if mc.scraper != nil {
+		mc.server.RegisterHandler(scraperHandler.NewHandler(mc.scraper))
+	}
 	mc.server.RegisterHandler(subtitles.NewHandler(db, &struct {
 		opensubtitles.OpenSubtitiles
 		model.TempFileProvider
 	}{*mc.opensubtitles, storage}))
 	mc.server.RegisterHandler(mc.push)
 	mc.server.RegisterHandler(management.NewHandler(db, &struct {
-		processor.Processor
+		*processor.Processor
 		itemsoptimizer.ItemsOptimizer
 		spectagger.Spectagger
 		mixondemand.MixOnDemand
-	}{*mc.processor, *mc.itemsoptimizer, *mc.spectagger, *mc.mixondemand}))
+		*storagegc.StorageGc
+	}{mc.processor, *mc.itemsoptimizer, *mc.spectagger, *mc.mixondemand, mc.storagegc}))
 }
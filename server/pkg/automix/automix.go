@@ -7,6 +7,7 @@ import (
 	"my-collection/server/pkg/bl/special_tags"
 	"my-collection/server/pkg/bl/tag_annotations"
 	"my-collection/server/pkg/bl/tags"
+	"my-collection/server/pkg/mixer"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/utils"
 	"time"
@@ -18,7 +19,7 @@ type autoMixDb interface {
 	model.TagAnnotationReaderWriter
 }
 
-func New(ctx context.Context, db autoMixDb, dailyMixItemsCount int) (*Automix, error) {
+func New(ctx context.Context, db autoMixDb, strategy mixer.Strategy, dailyMixItemsCount int) (*Automix, error) {
 	d, err := db.GetTag(ctx, special_tags.DailymixTag)
 	if err != nil {
 		if err := db.CreateOrUpdateTag(ctx, special_tags.DailymixTag); err != nil {
@@ -30,12 +31,14 @@ func New(ctx context.Context, db autoMixDb, dailyMixItemsCount int) (*Automix, e
 
 	return &Automix{
 		db:                 db,
+		strategy:           strategy,
 		dailyMixItemsCount: dailyMixItemsCount,
 	}, nil
 }
 
 type Automix struct {
 	db                 autoMixDb
+	strategy           mixer.Strategy
 	dailyMixItemsCount int
 	ctx                context.Context
 }
@@ -71,7 +74,7 @@ func (d *Automix) generateDailymix(ctg model.CurrentTimeGetter) error {
 		return err
 	}
 
-	randomItems, err := items.GetRandomItems(d.ctx, d.db, d.dailyMixItemsCount, func(item *model.Item) bool {
+	randomItems, err := mixer.GetItems(d.ctx, d.db, d.strategy, d.dailyMixItemsCount, func(item *model.Item) bool {
 		isShortSubitem := items.IsSubItem(item) && item.DurationSeconds < 60*5
 		return !items.IsHighlight(item) && !items.IsSplittedItem(item) && !isShortSubitem
 	})
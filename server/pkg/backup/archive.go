@@ -0,0 +1,346 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// An archive is a tar.gz holding manifest.json first, then snapshot.json with the database model
+// and optionally the storage files under storage/
+
+const (
+	ArchiveFormat  = "my-collection-backup"
+	ArchiveVersion = 1
+
+	manifestEntry = "manifest.json"
+	snapshotEntry = "snapshot.json"
+	storageEntry  = "storage/"
+
+	// the processor queue files aren't archived, the unfinished tasks are part of the snapshot and
+	// the queues are rebuilt from them
+	tasksDirectory = "tasks"
+)
+
+type Manifest struct {
+	Format    string `json:"format"`
+	Version   int    `json:"version"`
+	CreatedAt int64  `json:"createdAt"`
+	Items     int    `json:"items"`
+	Tags      int    `json:"tags"`
+	Storage   bool   `json:"storage"`
+}
+
+// ExportArchive writes the whole database model, and the storage files when storageDir isn't empty
+func ExportArchive(ctx context.Context, sr model.SnapshotReader, storageDir string, w io.Writer) error {
+	snapshot, err := sr.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Exporting archive of %d items and %d tags", len(snapshot.Items), len(snapshot.Tags))
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest := Manifest{
+		Format:    ArchiveFormat,
+		Version:   ArchiveVersion,
+		CreatedAt: time.Now().UnixMilli(),
+		Items:     len(snapshot.Items),
+		Tags:      len(snapshot.Tags),
+		Storage:   storageDir != "",
+	}
+
+	if err := writeJsonEntry(tw, manifestEntry, manifest); err != nil {
+		return err
+	}
+
+	if err := writeJsonEntry(tw, snapshotEntry, snapshot); err != nil {
+		return err
+	}
+
+	if storageDir != "" {
+		if err := writeStorage(tw, storageDir); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if err := gz.Close(); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+// ImportArchive restores an archive written by ExportArchive, the storage files are extracted into
+// storageDir unless it's empty, existing files are only replaced with the overwrite policy
+func ImportArchive(ctx context.Context, sr model.SnapshotRestorer, storageDir string, r io.Reader,
+	policy model.ConflictPolicy) (*model.RestoreResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	manifest := Manifest{}
+	if err := readJsonEntry(tr, manifestEntry, &manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Format != ArchiveFormat {
+		return nil, errors.Errorf("not a backup archive, format is %q", manifest.Format)
+	}
+
+	if manifest.Version > ArchiveVersion {
+		return nil, errors.Errorf("unsupported archive version %d, latest supported is %d", manifest.Version, ArchiveVersion)
+	}
+
+	snapshot := model.Snapshot{}
+	if err := readJsonEntry(tr, snapshotEntry, &snapshot); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Importing archive of %d items and %d tags created at %s", manifest.Items, manifest.Tags,
+		time.UnixMilli(manifest.CreatedAt))
+	relocator := newRelocator()
+	result, err := sr.RestoreSnapshot(ctx, &snapshot, policy, relocator)
+	if err != nil {
+		return nil, err
+	}
+
+	if storageDir != "" {
+		if err := readStorage(tr, storageDir, relocator, policy, result); err != nil {
+			return result, err
+		}
+	}
+
+	logger.Infof("Imported archive, items %+v, tags %+v, directories %+v, %d storage files, %d tasks",
+		result.Items, result.Tags, result.Directories, result.StorageFiles, result.Tasks)
+	return result, nil
+}
+
+func writeJsonEntry(tw *tar.Writer, name string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0640,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+func readJsonEntry(tr *tar.Reader, name string, value any) error {
+	header, err := tr.Next()
+	if err != nil {
+		return errors.Wrap(fmt.Errorf("reading %s: %w", name, err), 0)
+	}
+
+	if header.Name != name {
+		return errors.Errorf("expected %s in archive, found %s", name, header.Name)
+	}
+
+	if err := json.NewDecoder(tr).Decode(value); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+func writeStorage(tw *tar.Writer, storageDir string) error {
+	return filepath.WalkDir(storageDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		name, err := filepath.Rel(storageDir, file)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		if d.IsDir() {
+			if name == storage.TEMP_DIRECTORY || name == tasksDirectory {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		header.Name = storageEntry + filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(tw, f); err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		return nil
+	})
+}
+
+func readStorage(tr *tar.Reader, storageDir string, relocator *relocator, policy model.ConflictPolicy,
+	result *model.RestoreResult) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasPrefix(header.Name, storageEntry) {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, storageEntry))
+		if name == "." || name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			logger.Warningf("Skipping storage entry outside of the storage %s", header.Name)
+			continue
+		}
+
+		// the queue files of older archives are skipped, their tasks have no rows to be resumed from
+		if strings.HasPrefix(name, tasksDirectory+"/") {
+			continue
+		}
+
+		if renamed, ok := relocator.renames[name]; ok {
+			name = renamed
+		}
+
+		file := filepath.Join(storageDir, filepath.FromSlash(name))
+		if _, err := os.Stat(file); err == nil && policy != model.CONFLICT_OVERWRITE {
+			continue
+		}
+
+		if err := extractFile(tr, file, header); err != nil {
+			return err
+		}
+
+		result.StorageFiles++
+	}
+}
+
+func extractFile(tr *tar.Reader, file string, header *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if _, err := io.Copy(f, tr); err != nil {
+		f.Close()
+		return errors.Wrap(err, 0)
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if err := os.Chtimes(file, header.ModTime, header.ModTime); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+// relocator moves the storage files of entities restored with a new id into the directories of
+// their new id, recording the renames for the storage extraction
+type relocator struct {
+	renames map[string]string
+}
+
+func newRelocator() *relocator {
+	return &relocator{renames: make(map[string]string)}
+}
+
+func (r *relocator) RelocateItemUrl(url string, oldId uint64, newId uint64) string {
+	return r.relocate(url, storage.ItemDirectories, oldId, newId)
+}
+
+func (r *relocator) RelocateTagUrl(url string, oldId uint64, newId uint64) string {
+	return r.relocate(url, storage.TagDirectories, oldId, newId)
+}
+
+func (r *relocator) RelocateTagImageTypeUrl(url string, oldId uint64, newId uint64) string {
+	return r.relocate(url, storage.TagImageTypeDirectories, oldId, newId)
+}
+
+func (r *relocator) relocate(url string, directories []string, oldId uint64, newId uint64) string {
+	if oldId == newId || !strings.HasPrefix(url, storage.STORAGE_URL_PREFIX) {
+		return url
+	}
+
+	name := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(url, storage.STORAGE_URL_PREFIX)), "/")
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) != 3 || parts[1] != fmt.Sprint(oldId) || !contains(directories, parts[0]) {
+		return url
+	}
+
+	renamed := path.Join(parts[0], fmt.Sprint(newId), parts[2])
+	r.renames[name] = renamed
+	return filepath.Join(storage.STORAGE_URL_PREFIX, filepath.FromSlash(renamed))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func putStorageFile(t *testing.T, storageDir string, name string, content string) {
+	file := filepath.Join(storageDir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0750))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0640))
+}
+
+func readStorageFile(t *testing.T, storageDir string, name string) string {
+	data, err := os.ReadFile(filepath.Join(storageDir, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func setupArchiveSource(t *testing.T, ctx context.Context) (*bytes.Buffer, string) {
+	db := setupNewDb(t, "archive-source.sqlite")
+	storageDir := t.TempDir()
+
+	tit := &model.TagImageType{Nickname: "icon", IconUrl: ".internal-storage/tit-icon/1/icon.png"}
+	require.NoError(t, db.CreateOrUpdateTagImageType(ctx, tit))
+	category := &model.Tag{Title: "category"}
+	require.NoError(t, db.CreateOrUpdateTag(ctx, category))
+	tag := &model.Tag{Title: "tag", ParentID: &category.Id, DisplayStyle: "chip",
+		Images: []*model.TagImage{{Url: ".internal-storage/thumbnails/2/1.png", ImageTypeId: tit.Id}}}
+	require.NoError(t, db.CreateOrUpdateTag(ctx, tag))
+	require.NoError(t, db.CreateTagAnnotation(ctx, &model.TagAnnotation{Title: "annotation", Tags: []*model.Tag{tag}}))
+	require.NoError(t, db.CreateOrUpdateTagCustomCommand(ctx, &model.TagCustomCommand{Title: "open", TagId: tag.Id}))
+
+	item := &model.Item{Title: "item", Origin: "origin", Rating: 4, Tags: []*model.Tag{tag},
+		MainCoverUrl: ptr.To(".internal-storage/main-covers/1/main.png"),
+		Covers:       []model.Cover{{Url: ".internal-storage/covers/1/1.png"}}}
+	require.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	highlight := &model.Item{Title: "highlight", Origin: "origin", HighlightParentItemId: &item.Id}
+	require.NoError(t, db.CreateOrUpdateItem(ctx, highlight))
+	require.NoError(t, db.CreateOrUpdateDirectory(ctx, &model.Directory{Path: "movies", Tags: []*model.Tag{tag}}))
+	require.NoError(t, db.SaveWatchProgress(ctx, &model.WatchProgress{ItemId: item.Id, PositionSeconds: 30}))
+	require.NoError(t, db.CreateTask(ctx, &model.Task{Id: "pending", TaskType: model.REFRESH_PREVIEW_TASK,
+		Params: fmt.Sprintf(`{"id":%d,"count":3,"duration":2}`, item.Id), EnequeueTime: ptr.To(int64(1)),
+		ProcessingStart: ptr.To(int64(2))}))
+	require.NoError(t, db.CreateTask(ctx, &model.Task{Id: "done", TaskType: model.REFRESH_PREVIEW_TASK,
+		Params: fmt.Sprintf(`{"id":%d,"count":3,"duration":2}`, item.Id), ProcessingEnd: ptr.To(int64(3))}))
+
+	putStorageFile(t, storageDir, "tit-icon/1/icon.png", "icon")
+	putStorageFile(t, storageDir, "thumbnails/2/1.png", "thumbnail")
+	putStorageFile(t, storageDir, "main-covers/1/main.png", "main")
+	putStorageFile(t, storageDir, "covers/1/1.png", "cover")
+	putStorageFile(t, storageDir, "tasks/queue", "queue")
+	putStorageFile(t, storageDir, "temp/partial", "temp")
+
+	archive := &bytes.Buffer{}
+	require.NoError(t, ExportArchive(ctx, db, storageDir, archive))
+	return archive, storageDir
+}
+
+func TestArchiveIntoEmptyDb(t *testing.T) {
+	ctx := context.Background()
+	archive, _ := setupArchiveSource(t, ctx)
+	db := setupNewDb(t, "archive-empty.sqlite")
+	storageDir := t.TempDir()
+
+	result, err := ImportArchive(ctx, db, storageDir, archive, model.CONFLICT_SKIP)
+	require.NoError(t, err)
+	assert.True(t, result.EmptyTarget)
+	assert.Equal(t, model.RestoreCounts{Created: 2}, result.Items)
+	assert.Equal(t, model.RestoreCounts{Created: 2}, result.Tags)
+	assert.Equal(t, model.RestoreCounts{Created: 1}, result.Directories)
+	assert.Equal(t, 4, result.StorageFiles)
+	assert.Equal(t, 1, result.Tasks)
+
+	item, err := db.GetItem(ctx, uint64(1))
+	require.NoError(t, err)
+	assert.Equal(t, "item", item.Title)
+	assert.Equal(t, 4, item.Rating)
+	assert.Equal(t, ".internal-storage/main-covers/1/main.png", *item.MainCoverUrl)
+	assert.Len(t, item.Covers, 1)
+	assert.Len(t, item.Tags, 1)
+	assert.Len(t, item.Highlights, 1)
+
+	tag, err := db.GetTag(ctx, item.Tags[0].Id)
+	require.NoError(t, err)
+	assert.Equal(t, "chip", tag.DisplayStyle)
+	assert.Len(t, tag.Images, 1)
+	annotations, err := db.GetTagAnnotations(ctx, tag.Id)
+	require.NoError(t, err)
+	assert.Len(t, annotations, 1)
+
+	dir, err := db.GetDirectory(ctx, "path = ?", "movies")
+	require.NoError(t, err)
+	assert.Len(t, dir.Tags, 1)
+	progress, err := db.GetWatchProgress(ctx, item.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, progress.PositionSeconds)
+
+	// the queue is rebuilt from the unfinished tasks, which start over
+	tasks, err := db.FindTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, *tasks, 1)
+	assert.Equal(t, "pending", (*tasks)[0].Id)
+	assert.Equal(t, fmt.Sprintf(`{"id":%d,"count":3,"duration":2}`, item.Id), (*tasks)[0].Params)
+	assert.Nil(t, (*tasks)[0].ProcessingStart)
+	assert.NoFileExists(t, filepath.Join(storageDir, "tasks/queue"))
+	assert.NoFileExists(t, filepath.Join(storageDir, "temp/partial"))
+}
+
+func TestArchiveIntoExistingDb(t *testing.T) {
+	ctx := context.Background()
+	archive, _ := setupArchiveSource(t, ctx)
+	db := setupNewDb(t, "archive-existing.sqlite")
+	storageDir := t.TempDir()
+
+	// takes the archived id of the item, which is restored with a new one
+	other := &model.Item{Title: "other", Origin: "origin", Covers: []model.Cover{{Url: ".internal-storage/covers/1/1.png"}}}
+	require.NoError(t, db.CreateOrUpdateItem(ctx, other))
+	putStorageFile(t, storageDir, "covers/1/1.png", "other cover")
+	existing := &model.Item{Title: "highlight", Origin: "origin"}
+	require.NoError(t, db.CreateOrUpdateItem(ctx, existing))
+
+	result, err := ImportArchive(ctx, db, storageDir, archive, model.CONFLICT_SKIP)
+	require.NoError(t, err)
+	assert.False(t, result.EmptyTarget)
+	assert.Equal(t, model.RestoreCounts{Created: 1, Skipped: 1}, result.Items)
+
+	items, err := db.GetItems(ctx, "title = ?", "item")
+	require.NoError(t, err)
+	require.Len(t, *items, 1)
+	item := (*items)[0]
+	assert.NotEqual(t, uint64(1), item.Id)
+	assert.Equal(t, filepath.Join(".internal-storage/covers", fmt.Sprint(item.Id), "1.png"), item.Covers[0].Url)
+	assert.Equal(t, "cover", readStorageFile(t, storageDir, filepath.Join("covers", fmt.Sprint(item.Id), "1.png")))
+	assert.Equal(t, "main", readStorageFile(t, storageDir, filepath.Join("main-covers", fmt.Sprint(item.Id), "main.png")))
+	assert.Equal(t, "other cover", readStorageFile(t, storageDir, "covers/1/1.png"))
+	assert.Equal(t, 0, result.Tasks)
+	tasks, err := db.FindTasks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, *tasks)
+
+	archive, _ = setupArchiveSource(t, ctx)
+	result, err = ImportArchive(ctx, db, storageDir, archive, model.CONFLICT_OVERWRITE)
+	require.NoError(t, err)
+	assert.Equal(t, model.RestoreCounts{Updated: 2}, result.Items)
+	assert.Equal(t, model.RestoreCounts{Updated: 2}, result.Tags)
+	highlight, err := db.GetItem(ctx, existing.Id)
+	require.NoError(t, err)
+	assert.Equal(t, item.Id, *highlight.HighlightParentItemId)
+	item2, err := db.GetItem(ctx, item.Id)
+	require.NoError(t, err)
+	assert.Len(t, item2.Covers, 1)
+}
+
+func TestImportArchiveRejectsUnknownVersion(t *testing.T) {
+	archive := &bytes.Buffer{}
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, writeJsonEntry(tw, manifestEntry, Manifest{Format: ArchiveFormat, Version: ArchiveVersion + 1}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	db := setupNewDb(t, "archive-version.sqlite")
+	_, err := ImportArchive(context.Background(), db, "", archive, model.CONFLICT_SKIP)
+	assert.ErrorContains(t, err, "unsupported archive version")
+}
@@ -0,0 +1,276 @@
+package duplicates
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/directories"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/special_tags"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/phash"
+	"sort"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("duplicates")
+
+const (
+	DefaultMaxDistance       = 6.0
+	DefaultDurationTolerance = 2.0
+)
+
+type MergeDb interface {
+	model.ItemReaderWriter
+	model.TagReader
+	model.ItemsTagsUpdater
+	model.ItemPreferencesWriter
+}
+
+type hashedItem struct {
+	item   *model.Item
+	hashes []uint64
+}
+
+// FindDuplicates groups hashed items whose frames are at most maxDistance bits apart on
+// average. Only items with a duration within durationTolerance seconds are compared, and
+// an item similar to any member of a group joins the whole group.
+func FindDuplicates(ctx context.Context, ir model.ItemReader, hr model.VideoHashReader,
+	maxDistance float64, durationTolerance float64) ([]model.DuplicateGroup, error) {
+	candidates, err := getHashedItems(ctx, ir, hr)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].item.DurationSeconds < candidates[j].item.DurationSeconds
+	})
+
+	parents := make([]int, len(candidates))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if candidates[j].item.DurationSeconds-candidates[i].item.DurationSeconds > durationTolerance {
+				break
+			}
+
+			if phash.FramesDistance(candidates[i].hashes, candidates[j].hashes) <= maxDistance {
+				parents[find(parents, j)] = find(parents, i)
+			}
+		}
+	}
+
+	members := make(map[int][]hashedItem)
+	for i := range candidates {
+		root := find(parents, i)
+		members[root] = append(members[root], candidates[i])
+	}
+
+	groups := make([]model.DuplicateGroup, 0)
+	for _, group := range members {
+		if len(group) > 1 {
+			groups = append(groups, buildGroup(group))
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Distance != groups[j].Distance {
+			return groups[i].Distance < groups[j].Distance
+		}
+		return groups[i].KeeperId < groups[j].KeeperId
+	})
+
+	return groups, nil
+}
+
+func getHashedItems(ctx context.Context, ir model.ItemReader, hr model.VideoHashReader) ([]hashedItem, error) {
+	hashes, err := hr.GetVideoHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hashesByItem := make(map[uint64][]uint64)
+	for _, hash := range *hashes {
+		parsed, err := phash.ParseHashes(hash.Hashes)
+		if err != nil {
+			logger.Warningf("Ignoring invalid hashes of item %d - %s", hash.ItemId, err)
+			continue
+		}
+
+		hashesByItem[hash.ItemId] = parsed
+	}
+
+	allItems, err := ir.GetAllItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]hashedItem, 0, len(hashesByItem))
+	for i := range *allItems {
+		item := &(*allItems)[i]
+		if items.IsHighlight(item) || items.IsSplittedItem(item) {
+			continue
+		}
+
+		if itemHashes, ok := hashesByItem[item.Id]; ok {
+			result = append(result, hashedItem{item: item, hashes: itemHashes})
+		}
+	}
+
+	return result, nil
+}
+
+func find(parents []int, i int) int {
+	for parents[i] != i {
+		parents[i] = parents[parents[i]]
+		i = parents[i]
+	}
+
+	return i
+}
+
+func buildGroup(members []hashedItem) model.DuplicateGroup {
+	keeper := members[0]
+	for _, member := range members[1:] {
+		if isBetterCopy(member.item, keeper.item) {
+			keeper = member
+		}
+	}
+
+	group := model.DuplicateGroup{Items: make([]*model.Item, 0, len(members)), KeeperId: keeper.item.Id}
+	for _, member := range members {
+		group.Items = append(group.Items, member.item)
+		if member.item.Id != keeper.item.Id {
+			if distance := phash.FramesDistance(keeper.hashes, member.hashes); distance > group.Distance {
+				group.Distance = distance
+			}
+		}
+	}
+
+	return group
+}
+
+func isBetterCopy(item *model.Item, other *model.Item) bool {
+	pixels := item.Width * item.Height
+	otherPixels := other.Width * other.Height
+	if pixels != otherPixels {
+		return pixels > otherPixels
+	}
+
+	return item.FileSize > other.FileSize
+}
+
+// MergeDuplicates moves the user tags, rating and favorite flag of the duplicates to the
+// keeper, and removes the duplicates, optionally deleting their files.
+func MergeDuplicates(ctx context.Context, db MergeDb, keeperId uint64, duplicateIds []uint64, deleteRealFiles bool) error {
+	keeper, err := db.GetItem(ctx, keeperId)
+	if err != nil {
+		return err
+	}
+
+	duplicates, err := db.GetItems(ctx, duplicateIds)
+	if err != nil {
+		return err
+	}
+
+	if len(*duplicates) != len(duplicateIds) {
+		return errors.Errorf("some of the duplicates %v not found", duplicateIds)
+	}
+
+	for _, duplicate := range *duplicates {
+		if duplicate.Id == keeperId {
+			return errors.Errorf("item %d is both the keeper and a duplicate", keeperId)
+		}
+	}
+
+	if err := mergeTags(ctx, db, keeper, *duplicates); err != nil {
+		return err
+	}
+
+	if err := mergePreferences(ctx, db, keeper, *duplicates); err != nil {
+		return err
+	}
+
+	for _, duplicate := range *duplicates {
+		if deleteRealFiles {
+			if err := items.DeleteRealFile(ctx, db, duplicate.Id); err != nil {
+				return err
+			}
+		}
+
+		if errs := items.RemoveItemAndItsAssociations(ctx, db, duplicate.Id); len(errs) > 0 {
+			return errs[0]
+		}
+
+		logger.Infof("Duplicate %d merged into %d", duplicate.Id, keeperId)
+	}
+
+	return nil
+}
+
+func mergeTags(ctx context.Context, db MergeDb, keeper *model.Item, duplicates []model.Item) error {
+	tagIds := make([]uint64, 0)
+	for _, duplicate := range duplicates {
+		for _, tag := range duplicate.Tags {
+			tagIds = append(tagIds, tag.Id)
+		}
+	}
+
+	if len(tagIds) == 0 {
+		return nil
+	}
+
+	tags, err := db.GetTagsWithoutChildren(ctx, tagIds)
+	if err != nil {
+		return err
+	}
+
+	merged := make([]uint64, 0)
+	for _, tag := range *tags {
+		if isUserTag(&tag) {
+			merged = append(merged, tag.Id)
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	return db.UpdateItemsTags(ctx, []uint64{keeper.Id}, merged, []uint64{})
+}
+
+// isUserTag tells whether the tag was given by the user, and not generated from the
+// directory of the file or by one of the special taggers
+func isUserTag(tag *model.Tag) bool {
+	if tag.ParentID == nil {
+		return false
+	}
+
+	return !special_tags.IsSpecial(*tag.ParentID) && *tag.ParentID != directories.GetDirectoriesTagId()
+}
+
+func mergePreferences(ctx context.Context, db MergeDb, keeper *model.Item, duplicates []model.Item) error {
+	rating := keeper.Rating
+	favorite := keeper.Favorite
+	for _, duplicate := range duplicates {
+		if duplicate.Rating > rating {
+			rating = duplicate.Rating
+		}
+		favorite = favorite || duplicate.Favorite
+	}
+
+	if rating != keeper.Rating {
+		if err := db.SetItemRating(ctx, keeper.Id, rating); err != nil {
+			return err
+		}
+	}
+
+	if favorite != keeper.Favorite {
+		return db.SetItemFavorite(ctx, keeper.Id, favorite)
+	}
+
+	return nil
+}
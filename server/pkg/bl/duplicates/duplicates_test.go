@@ -0,0 +1,90 @@
+package duplicates
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/phash"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func createHashedItem(t *testing.T, db db.Database, item *model.Item, hashes []uint64) {
+	ctx := context.Background()
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	assert.NoError(t, db.SaveVideoHash(ctx, &model.VideoHash{ItemId: item.Id, Hashes: phash.FormatHashes(hashes)}))
+}
+
+func TestFindDuplicates(t *testing.T) {
+	db := setupNewDb(t, "find-duplicates.sqlite")
+	ctx := context.Background()
+
+	low := &model.Item{Title: "low", Origin: "movies", DurationSeconds: 100, Width: 640, Height: 480}
+	high := &model.Item{Title: "high", Origin: "movies", DurationSeconds: 101, Width: 1920, Height: 1080}
+	other := &model.Item{Title: "other", Origin: "movies", DurationSeconds: 100, Width: 1920, Height: 1080}
+	longer := &model.Item{Title: "longer", Origin: "movies", DurationSeconds: 200, Width: 1920, Height: 1080}
+	unhashed := &model.Item{Title: "unhashed", Origin: "movies", DurationSeconds: 100}
+	createHashedItem(t, db, low, []uint64{0x0f, 0xf0})
+	createHashedItem(t, db, high, []uint64{0x0f, 0xf1})
+	createHashedItem(t, db, other, []uint64{0xffffffff00000000, 0x00000000ffffffff})
+	createHashedItem(t, db, longer, []uint64{0x0f, 0xf0})
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, unhashed))
+
+	groups, err := FindDuplicates(ctx, db, db, DefaultMaxDistance, DefaultDurationTolerance)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, high.Id, groups[0].KeeperId)
+	assert.Equal(t, 0.5, groups[0].Distance)
+	assert.Len(t, groups[0].Items, 2)
+
+	groups, err = FindDuplicates(ctx, db, db, DefaultMaxDistance, 200)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Len(t, groups[0].Items, 3)
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	db := setupNewDb(t, "merge-duplicates.sqlite")
+	ctx := context.Background()
+
+	category := &model.Tag{Title: "category"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, category))
+	userTag := &model.Tag{Title: "user-tag", ParentID: pointer.Uint64(category.Id)}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, userTag))
+
+	keeper := &model.Item{Title: "keeper", Origin: "movies", Rating: 2}
+	duplicate := &model.Item{Title: "duplicate", Origin: "movies", Rating: 4, Favorite: true,
+		Tags: []*model.Tag{userTag, category}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, keeper))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, duplicate))
+
+	assert.Error(t, MergeDuplicates(ctx, db, keeper.Id, []uint64{keeper.Id}, false))
+	assert.Error(t, MergeDuplicates(ctx, db, keeper.Id, []uint64{12345}, false))
+	assert.NoError(t, MergeDuplicates(ctx, db, keeper.Id, []uint64{duplicate.Id}, false))
+
+	keeper, err := db.GetItem(ctx, keeper.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, keeper.Rating)
+	assert.True(t, keeper.Favorite)
+	assert.Len(t, keeper.Tags, 1)
+	assert.Equal(t, userTag.Id, keeper.Tags[0].Id)
+
+	_, err = db.GetItem(ctx, duplicate.Id)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
@@ -192,3 +193,26 @@ func IncludeDir(ctx context.Context, drw model.DirectoryReaderWriter, path strin
 
 	return nil
 }
+
+// ScanScope validates a directory to scan and returns it relative to the root directory,
+// the root itself is an empty scope
+func ScanScope(path string) (string, error) {
+	if path == model.ROOT_DIRECTORY_PATH {
+		return "", nil
+	}
+
+	relativePath := relativasor.GetRelativePath(filepath.Clean(path))
+	if filepath.IsAbs(relativePath) {
+		return "", fmt.Errorf("directory %s is outside of the root directory", path)
+	}
+
+	if relativePath == "." {
+		return "", nil
+	}
+
+	if relativePath == ".." || strings.HasPrefix(relativePath, "../") {
+		return "", fmt.Errorf("directory %s is outside of the root directory", path)
+	}
+
+	return relativePath, nil
+}
@@ -0,0 +1,233 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+	"my-collection/server/pkg/utils"
+)
+
+type BulkDb interface {
+	model.ItemReaderWriter
+	model.ItemsQuerier
+	model.TagReader
+	model.ItemsTagsUpdater
+}
+
+type BulkProcessor interface {
+	EnqueueItemVideoMetadata(ctx context.Context, id uint64, title string) error
+	EnqueueItemCovers(ctx context.Context, id uint64, title string) error
+	EnqueueItemPreview(ctx context.Context, id uint64, title string) error
+	EnqueueItemFileMetadata(ctx context.Context, id uint64, title string) error
+}
+
+type BulkOptimizer interface {
+	HandleItem(ctx context.Context, item *model.Item)
+}
+
+// InvalidBulkRequestError is returned before any item was touched
+type InvalidBulkRequestError struct {
+	Reason string
+}
+
+func (e *InvalidBulkRequestError) Error() string {
+	return fmt.Sprintf("invalid bulk request, %s", e.Reason)
+}
+
+// ExecuteBulk runs the operations of the request on every selected item. Tags are added
+// and removed for all of the items in a single transaction, the other operations run item
+// by item, and deletion comes last. The result reports the outcome of every item.
+func ExecuteBulk(ctx context.Context, db BulkDb, processor BulkProcessor, optimizer BulkOptimizer,
+	request *model.BulkRequest) (*model.BulkResult, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	addedTags, removedTags, deletion, err := validateBulkOperations(ctx, db, request.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, missingIds, err := selectBulkItems(ctx, db, request)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.BulkItemResult, 0, len(selected)+len(missingIds))
+	for _, itemId := range missingIds {
+		results = append(results, model.BulkItemResult{ItemId: itemId, Errors: []string{"item not found"}})
+	}
+
+	itemIds := make([]uint64, len(selected))
+	for i, item := range selected {
+		itemIds[i] = item.Id
+	}
+
+	tagsErr := db.UpdateItemsTags(ctx, itemIds, addedTags, removedTags)
+	for _, item := range selected {
+		result := model.BulkItemResult{ItemId: item.Id, Title: item.Title, Errors: make([]string, 0)}
+		if tagsErr != nil {
+			result.Errors = append(result.Errors, tagsErr.Error())
+		}
+
+		for _, operation := range request.Operations {
+			if err := executeItemOperation(ctx, processor, optimizer, &item, operation); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+
+		if deletion != nil && tagsErr == nil {
+			for _, err := range deleteBulkItem(ctx, db, item.Id, deletion.DeleteRealFile) {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return summarizeBulkResults(results), nil
+}
+
+func validateBulkOperations(ctx context.Context, tr model.TagReader,
+	operations []model.BulkOperation) ([]uint64, []uint64, *model.BulkOperation, error) {
+	if len(operations) == 0 {
+		return nil, nil, nil, &InvalidBulkRequestError{Reason: "no operations"}
+	}
+
+	addedTags := make([]uint64, 0)
+	removedTags := make([]uint64, 0)
+	var deletion *model.BulkOperation
+	for i, operation := range operations {
+		switch operation.Type {
+		case model.BULK_ADD_TAGS, model.BULK_REMOVE_TAGS:
+			if len(operation.TagIds) == 0 {
+				return nil, nil, nil, &InvalidBulkRequestError{Reason: fmt.Sprintf("%s without tags", operation.Type)}
+			}
+
+			if operation.Type == model.BULK_ADD_TAGS {
+				addedTags = append(addedTags, operation.TagIds...)
+			} else {
+				removedTags = append(removedTags, operation.TagIds...)
+			}
+		case model.BULK_DELETE:
+			deletion = &operations[i]
+		case model.BULK_COVERS, model.BULK_PREVIEW, model.BULK_METADATA, model.BULK_OPTIMIZE:
+		default:
+			return nil, nil, nil, &InvalidBulkRequestError{Reason: fmt.Sprintf("unknown operation %s", operation.Type)}
+		}
+	}
+
+	if err := validateTagsExist(ctx, tr, append(addedTags, removedTags...)); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return addedTags, removedTags, deletion, nil
+}
+
+func validateTagsExist(ctx context.Context, tr model.TagReader, tagIds []uint64) error {
+	if len(tagIds) == 0 {
+		return nil
+	}
+
+	tags, err := tr.GetTagsWithoutChildren(ctx, tagIds)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[uint64]bool)
+	for _, tag := range *tags {
+		found[tag.Id] = true
+	}
+
+	for _, tagId := range tagIds {
+		if !found[tagId] {
+			return &InvalidBulkRequestError{Reason: fmt.Sprintf("tag %d not found", tagId)}
+		}
+	}
+
+	return nil
+}
+
+// selectBulkItems returns the items selected by the request, and the requested ids
+// that don't exist
+func selectBulkItems(ctx context.Context, db BulkDb, request *model.BulkRequest) ([]model.Item, []uint64, error) {
+	if (len(request.ItemIds) == 0) == (request.Query == "") {
+		return nil, nil, &InvalidBulkRequestError{Reason: "expected either items or a query"}
+	}
+
+	if request.Query != "" {
+		expression, err := querylang.Parse(request.Query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		page, err := db.QueryItems(ctx, &model.ItemsQuery{Expression: expression})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return page.Items, nil, nil
+	}
+
+	found, err := db.GetItems(ctx, request.ItemIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := make(map[uint64]bool)
+	for _, item := range *found {
+		existing[item.Id] = true
+	}
+
+	missing := make([]uint64, 0)
+	for _, itemId := range request.ItemIds {
+		if !existing[itemId] {
+			missing = append(missing, itemId)
+		}
+	}
+
+	return *found, missing, nil
+}
+
+func executeItemOperation(ctx context.Context, processor BulkProcessor, optimizer BulkOptimizer,
+	item *model.Item, operation model.BulkOperation) error {
+	switch operation.Type {
+	case model.BULK_COVERS:
+		return processor.EnqueueItemCovers(ctx, item.Id, item.Title)
+	case model.BULK_PREVIEW:
+		return processor.EnqueueItemPreview(ctx, item.Id, item.Title)
+	case model.BULK_METADATA:
+		if err := processor.EnqueueItemVideoMetadata(ctx, item.Id, item.Title); err != nil {
+			return err
+		}
+		return processor.EnqueueItemFileMetadata(ctx, item.Id, item.Title)
+	case model.BULK_OPTIMIZE:
+		optimizer.HandleItem(ctx, item)
+	}
+
+	return nil
+}
+
+func deleteBulkItem(ctx context.Context, db model.ItemReaderWriter, itemId uint64, deleteRealFile bool) []error {
+	if deleteRealFile {
+		if err := DeleteRealFile(ctx, db, itemId); err != nil {
+			return []error{err}
+		}
+	}
+
+	return RemoveItemAndItsAssociations(ctx, db, itemId)
+}
+
+func summarizeBulkResults(results []model.BulkItemResult) *model.BulkResult {
+	summary := &model.BulkResult{Items: results}
+	for i := range summary.Items {
+		summary.Items[i].Success = len(summary.Items[i].Errors) == 0
+		if summary.Items[i].Success {
+			summary.Items[i].Errors = nil
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	logger.Infof("Bulk operation finished, %d succeeded, %d failed", summary.Succeeded, summary.Failed)
+	return summary
+}
@@ -2,8 +2,6 @@ package items
 
 import (
 	"context"
-	"fmt"
-	"math/rand"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
 	"os"
@@ -128,6 +126,7 @@ func HasSingleTag(item *model.Item, tag *model.Tag) bool {
 	return len(item.Tags) == 1 && item.Tags[0].Id == tag.Id
 }
 
+// RemoveItemAndItsAssociations moves the item to the trash, it can be restored until purged
 func RemoveItemAndItsAssociations(ctx context.Context, iw model.ItemWriter, itemId uint64) []error {
 	errors := make([]error, 0)
 	if err := iw.RemoveItem(ctx, itemId); err != nil {
@@ -160,7 +159,7 @@ func DeleteRealFile(ctx context.Context, ir model.ItemReader, itemId uint64) err
 	return nil
 }
 
-func noRandom(item *model.Item) bool {
+func IsNoRandom(item *model.Item) bool {
 	for _, tag := range item.Tags {
 		if tag.NoRandom != nil && *tag.NoRandom {
 			return true
@@ -170,50 +169,6 @@ func noRandom(item *model.Item) bool {
 	return false
 }
 
-func GetRandomItems(ctx context.Context, ir model.ItemReader, count int, filter ItemsFilter) ([]*model.Item, error) {
-	allItems, err := ir.GetAllItems(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if len(*allItems) == 0 {
-		return nil, fmt.Errorf("no items")
-	}
-	if count >= len(*allItems) {
-		count = len(*allItems) - 1
-	}
-
-	randomItems := make([]*model.Item, 0)
-	maxAttempts := len(*allItems) * 10 // Safety limit to prevent infinite loops
-
-	for i := 0; i < count; i++ {
-		attempts := 0
-		var chosenItem *model.Item
-
-		for {
-			if attempts >= maxAttempts {
-				// If we can't find enough valid items, return what we have
-				break
-			}
-
-			chosenItem = &((*allItems)[rand.Intn(len(*allItems))])
-
-			// Check if this item is suitable
-			if !ItemExists(randomItems, chosenItem) && filter(chosenItem) && !noRandom(chosenItem) {
-				break
-			}
-
-			attempts++
-		}
-
-		// Only add the item if we found a valid one
-		if attempts < maxAttempts {
-			randomItems = append(randomItems, chosenItem)
-		}
-	}
-
-	return randomItems, nil
-}
-
 func IsModified(item *model.Item, fmg model.FileMetadataGetter) (bool, error) {
 	path := relativasor.GetAbsoluteFile(filepath.Join(item.Origin, item.Title))
 	lastModified, _, err := fmg.GetFileMetadata(path)
@@ -3,13 +3,11 @@ package items
 import (
 	"context"
 	"errors"
-	"math/rand"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -683,7 +681,7 @@ func TestNoRandom(t *testing.T) {
 			},
 		}
 
-		result := noRandom(item)
+		result := IsNoRandom(item)
 		assert.True(t, result)
 	})
 
@@ -695,7 +693,7 @@ func TestNoRandom(t *testing.T) {
 			},
 		}
 
-		result := noRandom(item)
+		result := IsNoRandom(item)
 		assert.False(t, result)
 	})
 
@@ -704,7 +702,7 @@ func TestNoRandom(t *testing.T) {
 			Tags: []*model.Tag{},
 		}
 
-		result := noRandom(item)
+		result := IsNoRandom(item)
 		assert.False(t, result)
 	})
 
@@ -716,145 +714,11 @@ func TestNoRandom(t *testing.T) {
 			},
 		}
 
-		result := noRandom(item)
+		result := IsNoRandom(item)
 		assert.False(t, result)
 	})
 }
 
-func TestGetRandomItems(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockItemReader := model.NewMockItemReader(ctrl)
-
-	// Create test filter that accepts all items
-	acceptAllFilter := func(item *model.Item) bool {
-		return true
-	}
-
-	// Create test filter that rejects items with ID 2
-	rejectId2Filter := func(item *model.Item) bool {
-		return item.Id != 2
-	}
-
-	t.Run("get random items successfully", func(t *testing.T) {
-		items := []model.Item{
-			{Id: 1, Title: "item1"},
-			{Id: 2, Title: "item2"},
-			{Id: 3, Title: "item3"},
-			{Id: 4, Title: "item4"},
-			{Id: 5, Title: "item5"},
-		}
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(&items, nil)
-
-		// Seed random for deterministic testing
-		rand.Seed(time.Now().UnixNano())
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 3, acceptAllFilter)
-
-		assert.NoError(t, err)
-		assert.Len(t, result, 3)
-
-		// Check that all returned items are unique
-		ids := make(map[uint64]bool)
-		for _, item := range result {
-			assert.False(t, ids[item.Id], "Duplicate item found")
-			ids[item.Id] = true
-		}
-	})
-
-	t.Run("get random items with filter", func(t *testing.T) {
-		items := []model.Item{
-			{Id: 1, Title: "item1"},
-			{Id: 2, Title: "item2"}, // This will be filtered out
-			{Id: 3, Title: "item3"},
-			{Id: 4, Title: "item4"},
-		}
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(&items, nil)
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 2, rejectId2Filter)
-
-		assert.NoError(t, err)
-		assert.LessOrEqual(t, len(result), 2) // Should be at most 2 items
-
-		// Verify that item with ID 2 is not in the result
-		for _, item := range result {
-			assert.NotEqual(t, uint64(2), item.Id)
-		}
-	})
-
-	t.Run("get random items with noRandom tag", func(t *testing.T) {
-		items := []model.Item{
-			{Id: 1, Title: "item1"},
-			{Id: 2, Title: "item2", Tags: []*model.Tag{{Id: 1, NoRandom: pointer.Bool(true)}}},
-			{Id: 3, Title: "item3"},
-		}
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(&items, nil)
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 2, acceptAllFilter)
-
-		assert.NoError(t, err)
-
-		// Verify that item with noRandom tag is not in the result
-		for _, item := range result {
-			assert.NotEqual(t, uint64(2), item.Id)
-		}
-	})
-
-	t.Run("request more items than available", func(t *testing.T) {
-		items := []model.Item{
-			{Id: 1, Title: "item1"},
-			{Id: 2, Title: "item2"},
-		}
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(&items, nil)
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 5, acceptAllFilter)
-
-		assert.NoError(t, err)
-		assert.LessOrEqual(t, len(result), len(items)-1) // Should be at most len(items)-1
-	})
-
-	t.Run("no items available", func(t *testing.T) {
-		items := []model.Item{}
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(&items, nil)
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 3, acceptAllFilter)
-
-		assert.Error(t, err)
-		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "no items")
-	})
-
-	t.Run("get all items error", func(t *testing.T) {
-		expectedError := errors.New("get items error")
-
-		mockItemReader.EXPECT().
-			GetAllItems(gomock.Any()).
-			Return(nil, expectedError)
-
-		result, err := GetRandomItems(context.Background(), mockItemReader, 3, acceptAllFilter)
-
-		assert.Error(t, err)
-		assert.Nil(t, result)
-		assert.Equal(t, expectedError, err)
-	})
-}
-
 func TestIsModified(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
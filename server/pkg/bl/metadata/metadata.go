@@ -0,0 +1,143 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	minReleaseYear = 1870
+	// announced titles may already have a planned release year
+	maxYearsAhead = 10
+)
+
+var (
+	imdbIdPattern   = regexp.MustCompile(`^tt\d+$`)
+	languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+	providerPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+)
+
+// ValidationError describes why metadata can't be saved
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(format string, args ...any) *ValidationError {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Normalize trims the fields, lower cases the language and providers and drops empty external ids
+func Normalize(metadata *model.ItemMetadata) {
+	metadata.OriginalTitle = strings.TrimSpace(metadata.OriginalTitle)
+	metadata.Description = strings.TrimSpace(metadata.Description)
+	metadata.Language = strings.ToLower(strings.TrimSpace(metadata.Language))
+
+	if len(metadata.ExternalIds) == 0 {
+		metadata.ExternalIds = nil
+		return
+	}
+
+	externalIds := make(model.ExternalIds, len(metadata.ExternalIds))
+	for provider, id := range metadata.ExternalIds {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		id = strings.TrimSpace(id)
+		if provider != "" && id != "" {
+			externalIds[provider] = id
+		}
+	}
+
+	metadata.ExternalIds = externalIds
+	if len(externalIds) == 0 {
+		metadata.ExternalIds = nil
+	}
+}
+
+func Validate(metadata *model.ItemMetadata) error {
+	maxYear := time.Now().Year() + maxYearsAhead
+	if metadata.ReleaseYear != 0 && (metadata.ReleaseYear < minReleaseYear || metadata.ReleaseYear > maxYear) {
+		return newValidationError("invalid release year %d, expected 0 (unknown) or %d to %d",
+			metadata.ReleaseYear, minReleaseYear, maxYear)
+	}
+
+	if metadata.Language != "" && !languagePattern.MatchString(metadata.Language) {
+		return newValidationError("invalid language %s, expected an ISO 639 code such as en", metadata.Language)
+	}
+
+	for provider, id := range metadata.ExternalIds {
+		if err := ValidateExternalId(provider, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ValidateExternalId(provider string, id string) error {
+	if !providerPattern.MatchString(provider) {
+		return newValidationError("invalid external id provider %q", provider)
+	}
+
+	if provider == model.EXTERNAL_ID_IMDB && !imdbIdPattern.MatchString(id) {
+		return newValidationError("invalid imdb id %s, expected tt followed by digits", id)
+	}
+
+	return nil
+}
+
+func SetMetadata(ctx context.Context, mw model.ItemMetadataWriter, itemId uint64, metadata *model.ItemMetadata) error {
+	Normalize(metadata)
+	if err := Validate(metadata); err != nil {
+		return err
+	}
+
+	return mw.SetItemMetadata(ctx, itemId, metadata)
+}
+
+// Merge fills the empty fields of the item metadata from an imported one, what the user
+// already set is never replaced
+func Merge(metadata *model.ItemMetadata, imported *model.ItemMetadata) {
+	if metadata.ReleaseYear == 0 {
+		metadata.ReleaseYear = imported.ReleaseYear
+	}
+	if metadata.OriginalTitle == "" {
+		metadata.OriginalTitle = imported.OriginalTitle
+	}
+	if metadata.Description == "" {
+		metadata.Description = imported.Description
+	}
+	if metadata.Language == "" {
+		metadata.Language = imported.Language
+	}
+
+	for provider, id := range imported.ExternalIds {
+		if _, ok := metadata.ExternalIds[provider]; ok {
+			continue
+		}
+
+		if metadata.ExternalIds == nil {
+			metadata.ExternalIds = make(model.ExternalIds)
+		}
+		metadata.ExternalIds[provider] = id
+	}
+}
+
+func ImdbId(item *model.Item) string {
+	return item.ExternalIds[model.EXTERNAL_ID_IMDB]
+}
+
+// Decade returns the first year of the decade the item was released in, 0 if the year is unknown
+func Decade(item *model.Item) int {
+	if item.ReleaseYear == 0 {
+		return 0
+	}
+
+	return item.ReleaseYear / 10 * 10
+}
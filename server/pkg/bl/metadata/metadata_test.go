@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeAndValidate(t *testing.T) {
+	metadata := &model.ItemMetadata{
+		ReleaseYear:   1999,
+		OriginalTitle: "  Le Film ",
+		Language:      " EN",
+		ExternalIds:   model.ExternalIds{" IMDB ": "tt0133093 ", "tmdb": " "},
+	}
+
+	Normalize(metadata)
+	assert.Equal(t, "Le Film", metadata.OriginalTitle)
+	assert.Equal(t, "en", metadata.Language)
+	assert.Equal(t, model.ExternalIds{"imdb": "tt0133093"}, metadata.ExternalIds)
+	assert.NoError(t, Validate(metadata))
+
+	assert.Error(t, Validate(&model.ItemMetadata{ReleaseYear: 1500}))
+	assert.Error(t, Validate(&model.ItemMetadata{Language: "english"}))
+	assert.Error(t, Validate(&model.ItemMetadata{ExternalIds: model.ExternalIds{"imdb": "133093"}}))
+	assert.Error(t, Validate(&model.ItemMetadata{ExternalIds: model.ExternalIds{"the db": "1"}}))
+	assert.NoError(t, Validate(&model.ItemMetadata{}))
+}
+
+func TestSetMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+	mw := model.NewMockItemMetadataWriter(ctrl)
+
+	mw.EXPECT().SetItemMetadata(ctx, uint64(1), &model.ItemMetadata{ReleaseYear: 2001, Language: "fr"}).Return(nil)
+	assert.NoError(t, SetMetadata(ctx, mw, 1, &model.ItemMetadata{ReleaseYear: 2001, Language: "FR"}))
+	assert.Error(t, SetMetadata(ctx, mw, 1, &model.ItemMetadata{ReleaseYear: 20001}))
+}
+
+func TestMerge(t *testing.T) {
+	metadata := &model.ItemMetadata{Description: "mine", ExternalIds: model.ExternalIds{"imdb": "tt1"}}
+	Merge(metadata, &model.ItemMetadata{ReleaseYear: 1999, Description: "imported",
+		ExternalIds: model.ExternalIds{"imdb": "tt2", "tmdb": "603"}})
+
+	assert.Equal(t, 1999, metadata.ReleaseYear)
+	assert.Equal(t, "mine", metadata.Description)
+	assert.Equal(t, model.ExternalIds{"imdb": "tt1", "tmdb": "603"}, metadata.ExternalIds)
+}
+
+func TestDecade(t *testing.T) {
+	assert.Equal(t, 0, Decade(&model.Item{}))
+	assert.Equal(t, 1990, Decade(&model.Item{ItemMetadata: model.ItemMetadata{ReleaseYear: 1999}}))
+	assert.Equal(t, 2000, Decade(&model.Item{ItemMetadata: model.ItemMetadata{ReleaseYear: 2000}}))
+}
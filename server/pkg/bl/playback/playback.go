@@ -0,0 +1,123 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+const DefaultWatchedThresholdPercent = 90
+
+// ReportProgress saves the player position of an item, positions are in the file
+// time of the item (like StartPosition), so the same position is rolled up into the
+// main item of highlights and sub-items. An item is flagged as completed once more
+// than watchedThresholdPercent of it was played, and stays so until reset.
+func ReportProgress(ctx context.Context, ir model.ItemReader, pw model.WatchProgressReaderWriter,
+	ctg model.CurrentTimeGetter, itemId uint64, position float64, completed bool, watchedThresholdPercent float64) error {
+	item, err := ir.GetItem(ctx, itemId)
+	if err != nil {
+		return err
+	}
+
+	timestamp := ctg.GetCurrentTime().UnixMilli()
+	for {
+		if err := saveProgress(ctx, pw, item, position, completed, watchedThresholdPercent, timestamp); err != nil {
+			return err
+		}
+
+		parentId := getParentItemId(item)
+		if parentId == nil {
+			return nil
+		}
+
+		item, err = ir.GetItem(ctx, *parentId)
+		if err != nil {
+			return err
+		}
+
+		completed = false
+	}
+}
+
+func saveProgress(ctx context.Context, pw model.WatchProgressReaderWriter, item *model.Item,
+	position float64, completed bool, watchedThresholdPercent float64, timestamp int64) error {
+	existing, err := pw.GetWatchProgress(ctx, item.Id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	alreadyCompleted := err == nil && existing.Completed
+	return pw.SaveWatchProgress(ctx, &model.WatchProgress{
+		ItemId:          item.Id,
+		PositionSeconds: position,
+		Timestamp:       timestamp,
+		Completed:       alreadyCompleted || completed || isWatched(item, position, watchedThresholdPercent),
+	})
+}
+
+func getParentItemId(item *model.Item) *uint64 {
+	if items.IsSubItem(item) {
+		return item.MainItemId
+	}
+
+	if items.IsHighlight(item) {
+		return item.HighlightParentItemId
+	}
+
+	return nil
+}
+
+func isWatched(item *model.Item, position float64, watchedThresholdPercent float64) bool {
+	if item.DurationSeconds <= 0 {
+		return false
+	}
+
+	return (position-item.StartPosition)*100/item.DurationSeconds >= watchedThresholdPercent
+}
+
+// GetResumePosition returns where the player should start the item, watched items
+// and positions outside of the item start over.
+func GetResumePosition(ctx context.Context, ir model.ItemReader, pr model.WatchProgressReader, itemId uint64) (*model.WatchProgress, error) {
+	item, err := ir.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := pr.GetWatchProgress(ctx, itemId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.WatchProgress{ItemId: itemId, PositionSeconds: item.StartPosition}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	end := item.StartPosition + item.DurationSeconds
+	if progress.Completed || progress.PositionSeconds < item.StartPosition ||
+		(item.DurationSeconds > 0 && progress.PositionSeconds >= end) {
+		progress.PositionSeconds = item.StartPosition
+	}
+
+	return progress, nil
+}
+
+func GetContinueWatching(ctx context.Context, ir model.ItemReader, pr model.WatchProgressReader, limit int) ([]model.ContinueWatching, error) {
+	history, err := pr.GetWatchHistory(ctx, true, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.ContinueWatching, 0, len(*history))
+	for _, progress := range *history {
+		item, err := ir.GetItem(ctx, progress.ItemId)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, model.ContinueWatching{Item: item, Progress: progress})
+	}
+
+	return result, nil
+}
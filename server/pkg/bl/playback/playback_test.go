@@ -0,0 +1,117 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedTime struct {
+	now time.Time
+}
+
+func (f *fixedTime) GetCurrentTime() time.Time {
+	return f.now
+}
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestReportProgressRollsUpToMainItem(t *testing.T) {
+	db := setupNewDb(t, "rollup.sqlite")
+	ctx := context.Background()
+	clock := &fixedTime{now: time.UnixMilli(1000)}
+
+	main := &model.Item{Title: "main", Origin: "movies", DurationSeconds: 1000}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, main))
+	highlight := &model.Item{Title: "main", Origin: "movies-highlight", StartPosition: 100, EndPosition: 200,
+		DurationSeconds: 100, HighlightParentItemId: &main.Id}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, highlight))
+
+	assert.NoError(t, ReportProgress(ctx, db, db, clock, highlight.Id, 195, false, 90))
+
+	highlightProgress, err := db.GetWatchProgress(ctx, highlight.Id)
+	assert.NoError(t, err)
+	assert.True(t, highlightProgress.Completed)
+	assert.Equal(t, 195.0, highlightProgress.PositionSeconds)
+
+	mainProgress, err := db.GetWatchProgress(ctx, main.Id)
+	assert.NoError(t, err)
+	assert.False(t, mainProgress.Completed)
+	assert.Equal(t, 195.0, mainProgress.PositionSeconds)
+	assert.Equal(t, int64(1000), mainProgress.Timestamp)
+
+	resume, err := GetResumePosition(ctx, db, db, main.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 195.0, resume.PositionSeconds)
+
+	resume, err = GetResumePosition(ctx, db, db, highlight.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, resume.PositionSeconds)
+
+	continueWatching, err := GetContinueWatching(ctx, db, db, 10)
+	assert.NoError(t, err)
+	assert.Len(t, continueWatching, 1)
+	assert.Equal(t, main.Id, continueWatching[0].Item.Id)
+
+	clock.now = time.UnixMilli(2000)
+	assert.NoError(t, ReportProgress(ctx, db, db, clock, main.Id, 950, false, 90))
+	mainProgress, err = db.GetWatchProgress(ctx, main.Id)
+	assert.NoError(t, err)
+	assert.True(t, mainProgress.Completed)
+
+	continueWatching, err = GetContinueWatching(ctx, db, db, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, continueWatching)
+
+	history, err := db.GetWatchHistory(ctx, false, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, *history, 1)
+}
+
+func TestReportProgressWatchedFlag(t *testing.T) {
+	db := setupNewDb(t, "watched.sqlite")
+	ctx := context.Background()
+	clock := &fixedTime{now: time.UnixMilli(1000)}
+
+	item := &model.Item{Title: "item", Origin: "movies", DurationSeconds: 100}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+
+	assert.NoError(t, ReportProgress(ctx, db, db, clock, item.Id, 40, false, 50))
+	progress, err := db.GetWatchProgress(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.False(t, progress.Completed)
+
+	assert.NoError(t, ReportProgress(ctx, db, db, clock, item.Id, 60, false, 50))
+	progress, err = db.GetWatchProgress(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.True(t, progress.Completed)
+
+	assert.NoError(t, ReportProgress(ctx, db, db, clock, item.Id, 10, false, 50))
+	progress, err = db.GetWatchProgress(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.True(t, progress.Completed)
+
+	resume, err := GetResumePosition(ctx, db, db, item.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, resume.PositionSeconds)
+
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	_, err = db.GetWatchProgress(ctx, item.Id)
+	assert.Error(t, err)
+}
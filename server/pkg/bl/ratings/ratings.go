@@ -0,0 +1,109 @@
+package ratings
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("ratings")
+
+const (
+	MaxRating = 5
+
+	favoriteFactor = 2.0
+)
+
+func ValidateRating(rating int) error {
+	if rating < 0 || rating > MaxRating {
+		return errors.Errorf("invalid rating %d, expected 0 (unrated) to %d", rating, MaxRating)
+	}
+
+	return nil
+}
+
+func SetRating(ctx context.Context, pw model.ItemPreferencesWriter, itemId uint64, rating int) error {
+	if err := ValidateRating(rating); err != nil {
+		return err
+	}
+
+	return pw.SetItemRating(ctx, itemId, rating)
+}
+
+// ImportRatings sets the rating and favorite flag of many items at once, items are found
+// by id or by title and origin. Entries that fail are reported and don't stop the import.
+func ImportRatings(ctx context.Context, ir model.ItemReader, pw model.ItemPreferencesWriter,
+	entries []model.RatingImport) *model.RatingImportResult {
+	result := &model.RatingImportResult{Failed: make([]string, 0)}
+	for _, entry := range entries {
+		if err := importRating(ctx, ir, pw, entry); err != nil {
+			logger.Warningf("Unable to import rating of %s - %s", describeEntry(entry), err)
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %s", describeEntry(entry), err))
+			continue
+		}
+
+		result.Imported++
+	}
+
+	logger.Infof("Imported %d ratings, %d failed", result.Imported, len(result.Failed))
+	return result
+}
+
+func importRating(ctx context.Context, ir model.ItemReader, pw model.ItemPreferencesWriter, entry model.RatingImport) error {
+	if err := ValidateRating(entry.Rating); err != nil {
+		return err
+	}
+
+	itemId := entry.ItemId
+	if itemId == 0 {
+		if entry.Title == "" || entry.Origin == "" {
+			return errors.Errorf("missing ('id') or ('title' and 'origin')")
+		}
+
+		item, err := ir.GetItem(ctx, "title = ? and origin = ?", entry.Title, entry.Origin)
+		if err != nil {
+			return err
+		}
+
+		itemId = item.Id
+	}
+
+	if err := pw.SetItemRating(ctx, itemId, entry.Rating); err != nil {
+		return err
+	}
+
+	if entry.Favorite != nil {
+		return pw.SetItemFavorite(ctx, itemId, *entry.Favorite)
+	}
+
+	return nil
+}
+
+func describeEntry(entry model.RatingImport) string {
+	if entry.ItemId != 0 {
+		return fmt.Sprintf("item %d", entry.ItemId)
+	}
+
+	return fmt.Sprintf("%s/%s", entry.Origin, entry.Title)
+}
+
+// PreferencesWeigher makes favorite and highly rated items more likely to be mixed,
+// and poorly rated ones less likely. Unrated items keep their weight.
+type PreferencesWeigher struct{}
+
+func (p *PreferencesWeigher) Weight(item *model.Item) float64 {
+	weight := 1.0
+	if item.Favorite {
+		weight *= favoriteFactor
+	}
+
+	if item.Rating > 0 {
+		// 0.6 for 1 star up to 1.4 for 5 stars
+		weight *= 0.4 + float64(item.Rating)/MaxRating
+	}
+
+	return weight
+}
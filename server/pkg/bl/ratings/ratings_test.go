@@ -0,0 +1,82 @@
+package ratings
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestSetRating(t *testing.T) {
+	db := setupNewDb(t, "set-rating.sqlite")
+	ctx := context.Background()
+
+	item := &model.Item{Title: "item", Origin: "movies"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+
+	assert.NoError(t, SetRating(ctx, db, item.Id, 4))
+	assert.Error(t, SetRating(ctx, db, item.Id, 6))
+	assert.Error(t, SetRating(ctx, db, item.Id, -1))
+
+	item, err := db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, item.Rating)
+}
+
+func TestImportRatings(t *testing.T) {
+	db := setupNewDb(t, "import-ratings.sqlite")
+	ctx := context.Background()
+
+	first := &model.Item{Title: "first", Origin: "movies"}
+	second := &model.Item{Title: "second", Origin: "movies"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, first))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, second))
+
+	result := ImportRatings(ctx, db, db, []model.RatingImport{
+		{ItemId: first.Id, Rating: 5, Favorite: pointer.Bool(true)},
+		{Title: "second", Origin: "movies", Rating: 2},
+		{Title: "missing", Origin: "movies", Rating: 2},
+		{ItemId: first.Id, Rating: 11},
+		{Rating: 3},
+	})
+
+	assert.Equal(t, 2, result.Imported)
+	assert.Len(t, result.Failed, 3)
+
+	first, err := db.GetItem(ctx, first.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, first.Rating)
+	assert.True(t, first.Favorite)
+
+	second, err = db.GetItem(ctx, second.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, second.Rating)
+	assert.False(t, second.Favorite)
+}
+
+func TestPreferencesWeigher(t *testing.T) {
+	weigher := &PreferencesWeigher{}
+
+	assert.Equal(t, 1.0, weigher.Weight(&model.Item{}))
+	assert.Equal(t, 2.0, weigher.Weight(&model.Item{Favorite: true}))
+	assert.Less(t, weigher.Weight(&model.Item{Rating: 1}), 1.0)
+	assert.Greater(t, weigher.Weight(&model.Item{Rating: 5}), 1.0)
+	assert.Greater(t, weigher.Weight(&model.Item{Rating: 5, Favorite: true}), weigher.Weight(&model.Item{Favorite: true}))
+}
@@ -3,6 +3,7 @@ package subtitles
 import (
 	"context"
 	"fmt"
+	"my-collection/server/pkg/bl/metadata"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
 	"my-collection/server/pkg/srt"
@@ -64,6 +65,16 @@ func GetAvailableNames(ctx context.Context, ir model.ItemReader, itemId uint64)
 	return lookForAvailableSubtitles(videoDir)
 }
 
+// getIMDbID prefers the id stored on the item, falling back to the [imdbid-tt...] naming
+// convention of Jellyfin and Radarr
+func getIMDbID(item *model.Item) string {
+	if imdbId := metadata.ImdbId(item); imdbId != "" {
+		return imdbId
+	}
+
+	return extractIMDbID(item.Url)
+}
+
 func extractIMDbID(path string) string {
 	re := regexp.MustCompile(`\[imdbid-(tt\d+)\]`)
 	matches := re.FindStringSubmatch(path)
@@ -81,7 +92,7 @@ func GetOnlineNames(ctx context.Context, ir model.ItemReader, l SubtitlesLister,
 		return nil, err
 	}
 
-	imdbId := extractIMDbID(item.Url)
+	imdbId := getIMDbID(item)
 
 	subtitles, err := l.List(imdbId, lang, aiTranslated)
 	if err != nil {
@@ -137,3 +148,52 @@ func Delete(ctx context.Context, url string) error {
 	subtitleFile := relativasor.GetAbsoluteFile(url)
 	return os.Remove(subtitleFile)
 }
+
+func IndexSubtitle(ctx context.Context, si model.SubtitlesIndexer, itemId uint64, url string) error {
+	subtitle, err := GetSubtitle(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	return si.IndexSubtitle(ctx, itemId, url, subtitle)
+}
+
+func IndexAvailableSubtitles(ctx context.Context, ir model.ItemReader, si model.SubtitlesIndexer, itemId uint64) error {
+	item, err := ir.GetItem(ctx, itemId)
+	if err != nil {
+		return err
+	}
+
+	return indexItemSubtitles(ctx, si, item)
+}
+
+func IndexAllAvailableSubtitles(ctx context.Context, ir model.ItemReader, si model.SubtitlesIndexer) error {
+	items, err := ir.GetAllItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range *items {
+		if err := indexItemSubtitles(ctx, si, &item); err != nil {
+			logger.Warningf("Unable to index subtitles of item %d - %s", item.Id, err)
+		}
+	}
+
+	return nil
+}
+
+func indexItemSubtitles(ctx context.Context, si model.SubtitlesIndexer, item *model.Item) error {
+	videoDir := filepath.Dir(relativasor.GetAbsoluteFile(item.Url))
+	available, err := lookForAvailableSubtitles(videoDir)
+	if err != nil {
+		return err
+	}
+
+	for _, subtitle := range available {
+		if err := IndexSubtitle(ctx, si, item.Id, subtitle.Url); err != nil {
+			logger.Warningf("Unable to index subtitle %s of item %d - %s", subtitle.Url, item.Id, err)
+		}
+	}
+
+	return nil
+}
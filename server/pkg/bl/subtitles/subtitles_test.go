@@ -1,6 +1,7 @@
 package subtitles
 
 import (
+	"my-collection/server/pkg/model"
 	"os"
 	"path/filepath"
 	"testing"
@@ -213,3 +214,11 @@ func TestLookForAvailableSubtitles(t *testing.T) {
 		}
 	})
 }
+
+func TestGetIMDbID(t *testing.T) {
+	url := "movies/Inside Llewyn Davis (2013) [imdbid-tt2042568]/movie.mkv"
+	assert.Equal(t, "tt2042568", getIMDbID(&model.Item{Url: url}))
+	assert.Equal(t, "tt0000001", getIMDbID(&model.Item{Url: url,
+		ItemMetadata: model.ItemMetadata{ExternalIds: model.ExternalIds{model.EXTERNAL_ID_IMDB: "tt0000001"}}}))
+	assert.Equal(t, "", getIMDbID(&model.Item{Url: "movies/movie.mkv"}))
+}
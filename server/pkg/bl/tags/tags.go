@@ -99,6 +99,7 @@ func GetOrCreateTags(ctx context.Context, trw model.TagReaderWriter, tags []*mod
 	return result, nil
 }
 
+// RemoveTagAndItsAssociations moves the tag to the trash, it can be restored until purged
 func RemoveTagAndItsAssociations(ctx context.Context, tw model.TagWriter, tag *model.Tag) []error {
 	errors := make([]error, 0)
 	if err := tw.RemoveTag(ctx, tag.Id); err != nil {
@@ -23,10 +23,18 @@ func BuildQueueMetadata(ctx context.Context, tr model.TaskReader, ps model.Proce
 		return model.QueueMetadata{}, nil
 	}
 
+	failedTasks, err := tr.TasksCount(ctx, "dead_letter = ?", true)
+	if err != nil {
+		logger.Errorf("Unable to get failed tasks count %s", err)
+		return model.QueueMetadata{}, nil
+	}
+
 	queueMetadata := model.QueueMetadata{
 		Size:            pointer.Int64(size),
 		Paused:          pointer.Bool(ps.IsPaused()),
 		UnfinishedTasks: pointer.Int64(unfinishedTasks),
+		FailedTasks:     pointer.Int64(failedTasks),
+		Pools:           ps.PoolsMetadata(),
 	}
 
 	return queueMetadata, err
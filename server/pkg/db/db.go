@@ -19,7 +19,8 @@ import (
 var logger = logging.MustGetLogger("db")
 
 type databaseImpl struct {
-	db *gorm.DB
+	db             *gorm.DB
+	fullTextSearch bool
 }
 
 func New(dbfile string, shouldLog bool) (Database, error) {
@@ -76,10 +77,26 @@ func New(dbfile string, shouldLog bool) (Database, error) {
 		return nil, errors.Wrap(err, 0)
 	}
 
+	if err = db.AutoMigrate(&model.WatchProgress{}); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	if err = db.AutoMigrate(&model.VideoHash{}); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	fullTextSearch := isFts5Available(db)
+	if fullTextSearch {
+		if err = initSearchIndex(db); err != nil {
+			return nil, err
+		}
+	}
+
 	logger.Infof("DB initialized with db file: %s", dbfile)
 
 	result := &databaseImpl{
-		db: db,
+		db:             db,
+		fullTextSearch: fullTextSearch,
 	}
 	if shouldLog {
 		return &dbLogger{
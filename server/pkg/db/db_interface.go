@@ -17,9 +17,17 @@ type Database interface {
 	UpdateItem(ctx context.Context, item *model.Item) error
 	RemoveItem(ctx context.Context, itemId uint64) error
 	RemoveTagFromItem(ctx context.Context, itemId uint64, tagId uint64) error
+	SetItemRating(ctx context.Context, itemId uint64, rating int) error
+	SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error
+	SetItemMetadata(ctx context.Context, itemId uint64, metadata *model.ItemMetadata) error
 	GetItem(ctx context.Context, conds ...any) (*model.Item, error)
 	GetItems(ctx context.Context, conds ...any) (*[]model.Item, error)
 	GetAllItems(ctx context.Context) (*[]model.Item, error)
+	QueryItems(ctx context.Context, query *model.ItemsQuery) (*model.ItemsPage, error)
+	Search(ctx context.Context, query *model.SearchQuery) (*model.SearchResults, error)
+	RebuildSearchIndex(ctx context.Context) error
+	IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle model.Subtitle) error
+	RemoveSubtitleIndex(ctx context.Context, url string) error
 	GetItemsCount(ctx context.Context) (int64, error)
 	GetTotalDurationSeconds(ctx context.Context) (float64, error)
 	CreateTagAnnotation(ctx context.Context, tagAnnotation *model.TagAnnotation) error
@@ -36,6 +44,8 @@ type Database interface {
 	GetAllTagCustomCommands(ctx context.Context) (*[]model.TagCustomCommand, error)
 	CreateOrUpdateTag(ctx context.Context, tag *model.Tag) error
 	UpdateTag(ctx context.Context, tag *model.Tag) error
+	SetTagItems(ctx context.Context, tagId uint64, itemIds []uint64) error
+	UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error
 	GetTag(ctx context.Context, conds ...any) (*model.Tag, error)
 	GetTagsWithoutChildren(ctx context.Context, conds ...any) (*[]model.Tag, error)
 	GetTags(ctx context.Context, conds ...any) (*[]model.Tag, error)
@@ -48,4 +58,21 @@ type Database interface {
 	RemoveTasks(ctx context.Context, conds ...any) error
 	TasksCount(ctx context.Context, query any, conds ...any) (int64, error)
 	GetTasks(ctx context.Context, offset int, limit int) (*[]model.Task, error)
+	FindTasks(ctx context.Context, conds ...any) (*[]model.Task, error)
+	SaveWatchProgress(ctx context.Context, progress *model.WatchProgress) error
+	RemoveWatchProgress(ctx context.Context, itemId uint64) error
+	GetWatchProgress(ctx context.Context, itemId uint64) (*model.WatchProgress, error)
+	GetWatchHistory(ctx context.Context, onlyInProgress bool, offset int, limit int) (*[]model.WatchProgress, error)
+	SaveVideoHash(ctx context.Context, hash *model.VideoHash) error
+	RemoveVideoHash(ctx context.Context, itemId uint64) error
+	GetVideoHashes(ctx context.Context) (*[]model.VideoHash, error)
+	GetDeletedItems(ctx context.Context, conds ...any) (*[]model.Item, error)
+	GetDeletedTags(ctx context.Context, conds ...any) (*[]model.Tag, error)
+	RestoreItem(ctx context.Context, itemId uint64) error
+	RestoreTag(ctx context.Context, tagId uint64) error
+	PurgeItem(ctx context.Context, itemId uint64) error
+	PurgeTag(ctx context.Context, tagId uint64) error
+
+	GetSnapshot(ctx context.Context) (*model.Snapshot, error)
+	RestoreSnapshot(ctx context.Context, snapshot *model.Snapshot, policy model.ConflictPolicy, relocator model.StorageRelocator) (*model.RestoreResult, error)
 }
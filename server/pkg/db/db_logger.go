@@ -65,6 +65,31 @@ func formatResult(result interface{}) string {
 			return "empty"
 		}
 		return fmt.Sprintf("%d items", len(*v))
+	case *model.ItemsPage:
+		if v == nil {
+			return "empty"
+		}
+		return fmt.Sprintf("%d/%d items", len(v.Items), v.Total)
+	case *model.SearchResults:
+		if v == nil {
+			return "empty"
+		}
+		return fmt.Sprintf("%d/%d hits", len(v.Hits), v.Total)
+	case *model.WatchProgress:
+		if v == nil {
+			return "not found"
+		}
+		return fmt.Sprintf("item=%d position=%.1f completed=%t", v.ItemId, v.PositionSeconds, v.Completed)
+	case *[]model.VideoHash:
+		if v == nil {
+			return "empty"
+		}
+		return fmt.Sprintf("%d hashes", len(*v))
+	case *[]model.WatchProgress:
+		if v == nil {
+			return "empty"
+		}
+		return fmt.Sprintf("%d entries", len(*v))
 	case *model.Tag:
 		if v == nil {
 			return "not found"
@@ -188,6 +213,27 @@ func (d *dbLogger) RemoveItem(ctx context.Context, itemId uint64) error {
 	return err
 }
 
+func (d *dbLogger) SetItemRating(ctx context.Context, itemId uint64, rating int) error {
+	start := time.Now()
+	err := d.db.SetItemRating(ctx, itemId, rating)
+	d.log(ctx, "SetItemRating", start, err, fmt.Sprintf("item=%d rating=%d", itemId, rating))
+	return err
+}
+
+func (d *dbLogger) SetItemMetadata(ctx context.Context, itemId uint64, metadata *model.ItemMetadata) error {
+	start := time.Now()
+	err := d.db.SetItemMetadata(ctx, itemId, metadata)
+	d.log(ctx, "SetItemMetadata", start, err, fmt.Sprintf("item=%d year=%d", itemId, metadata.ReleaseYear))
+	return err
+}
+
+func (d *dbLogger) SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error {
+	start := time.Now()
+	err := d.db.SetItemFavorite(ctx, itemId, favorite)
+	d.log(ctx, "SetItemFavorite", start, err, fmt.Sprintf("item=%d favorite=%t", itemId, favorite))
+	return err
+}
+
 func (d *dbLogger) RemoveTagFromItem(ctx context.Context, itemId uint64, tagId uint64) error {
 	start := time.Now()
 	err := d.db.RemoveTagFromItem(ctx, itemId, tagId)
@@ -216,6 +262,41 @@ func (d *dbLogger) GetAllItems(ctx context.Context) (*[]model.Item, error) {
 	return result, err
 }
 
+func (d *dbLogger) QueryItems(ctx context.Context, query *model.ItemsQuery) (*model.ItemsPage, error) {
+	start := time.Now()
+	result, err := d.db.QueryItems(ctx, query)
+	d.log(ctx, "QueryItems", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchResults, error) {
+	start := time.Now()
+	result, err := d.db.Search(ctx, query)
+	d.log(ctx, "Search", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) RebuildSearchIndex(ctx context.Context) error {
+	start := time.Now()
+	err := d.db.RebuildSearchIndex(ctx)
+	d.log(ctx, "RebuildSearchIndex", start, err, nil)
+	return err
+}
+
+func (d *dbLogger) IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle model.Subtitle) error {
+	start := time.Now()
+	err := d.db.IndexSubtitle(ctx, itemId, url, subtitle)
+	d.log(ctx, "IndexSubtitle", start, err, fmt.Sprintf("item=%d url=%s lines=%d", itemId, url, len(subtitle.Items)))
+	return err
+}
+
+func (d *dbLogger) RemoveSubtitleIndex(ctx context.Context, url string) error {
+	start := time.Now()
+	err := d.db.RemoveSubtitleIndex(ctx, url)
+	d.log(ctx, "RemoveSubtitleIndex", start, err, fmt.Sprintf("url=%s", url))
+	return err
+}
+
 func (d *dbLogger) GetItemsCount(ctx context.Context) (int64, error) {
 	start := time.Now()
 	result, err := d.db.GetItemsCount(ctx)
@@ -332,6 +413,20 @@ func (d *dbLogger) UpdateTag(ctx context.Context, tag *model.Tag) error {
 	return err
 }
 
+func (d *dbLogger) SetTagItems(ctx context.Context, tagId uint64, itemIds []uint64) error {
+	start := time.Now()
+	err := d.db.SetTagItems(ctx, tagId, itemIds)
+	d.log(ctx, "SetTagItems", start, err, fmt.Sprintf("tag=%d items=%d", tagId, len(itemIds)))
+	return err
+}
+
+func (d *dbLogger) UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error {
+	start := time.Now()
+	err := d.db.UpdateItemsTags(ctx, itemIds, addedTagIds, removedTagIds)
+	d.log(ctx, "UpdateItemsTags", start, err, fmt.Sprintf("items=%d added=%v removed=%v", len(itemIds), addedTagIds, removedTagIds))
+	return err
+}
+
 func (d *dbLogger) GetTag(ctx context.Context, conds ...interface{}) (*model.Tag, error) {
 	start := time.Now()
 	result, err := d.db.GetTag(ctx, conds...)
@@ -416,3 +511,115 @@ func (d *dbLogger) GetTasks(ctx context.Context, offset int, limit int) (*[]mode
 	d.log(ctx, "GetTasks", start, err, result)
 	return result, err
 }
+
+func (d *dbLogger) FindTasks(ctx context.Context, conds ...interface{}) (*[]model.Task, error) {
+	start := time.Now()
+	result, err := d.db.FindTasks(ctx, conds...)
+	d.log(ctx, "FindTasks", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) SaveWatchProgress(ctx context.Context, progress *model.WatchProgress) error {
+	start := time.Now()
+	err := d.db.SaveWatchProgress(ctx, progress)
+	d.log(ctx, "SaveWatchProgress", start, err, progress)
+	return err
+}
+
+func (d *dbLogger) RemoveWatchProgress(ctx context.Context, itemId uint64) error {
+	start := time.Now()
+	err := d.db.RemoveWatchProgress(ctx, itemId)
+	d.log(ctx, "RemoveWatchProgress", start, err, fmt.Sprintf("item=%d", itemId))
+	return err
+}
+
+func (d *dbLogger) GetWatchProgress(ctx context.Context, itemId uint64) (*model.WatchProgress, error) {
+	start := time.Now()
+	result, err := d.db.GetWatchProgress(ctx, itemId)
+	d.log(ctx, "GetWatchProgress", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) GetWatchHistory(ctx context.Context, onlyInProgress bool, offset int, limit int) (*[]model.WatchProgress, error) {
+	start := time.Now()
+	result, err := d.db.GetWatchHistory(ctx, onlyInProgress, offset, limit)
+	d.log(ctx, "GetWatchHistory", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) SaveVideoHash(ctx context.Context, hash *model.VideoHash) error {
+	start := time.Now()
+	err := d.db.SaveVideoHash(ctx, hash)
+	d.log(ctx, "SaveVideoHash", start, err, fmt.Sprintf("item=%d", hash.ItemId))
+	return err
+}
+
+func (d *dbLogger) RemoveVideoHash(ctx context.Context, itemId uint64) error {
+	start := time.Now()
+	err := d.db.RemoveVideoHash(ctx, itemId)
+	d.log(ctx, "RemoveVideoHash", start, err, fmt.Sprintf("item=%d", itemId))
+	return err
+}
+
+func (d *dbLogger) GetVideoHashes(ctx context.Context) (*[]model.VideoHash, error) {
+	start := time.Now()
+	result, err := d.db.GetVideoHashes(ctx)
+	d.log(ctx, "GetVideoHashes", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) GetDeletedItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	start := time.Now()
+	result, err := d.db.GetDeletedItems(ctx, conds...)
+	d.log(ctx, "GetDeletedItems", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) GetDeletedTags(ctx context.Context, conds ...interface{}) (*[]model.Tag, error) {
+	start := time.Now()
+	result, err := d.db.GetDeletedTags(ctx, conds...)
+	d.log(ctx, "GetDeletedTags", start, err, result)
+	return result, err
+}
+
+func (d *dbLogger) RestoreItem(ctx context.Context, itemId uint64) error {
+	start := time.Now()
+	err := d.db.RestoreItem(ctx, itemId)
+	d.log(ctx, "RestoreItem", start, err, fmt.Sprintf("id=%d", itemId))
+	return err
+}
+
+func (d *dbLogger) RestoreTag(ctx context.Context, tagId uint64) error {
+	start := time.Now()
+	err := d.db.RestoreTag(ctx, tagId)
+	d.log(ctx, "RestoreTag", start, err, fmt.Sprintf("id=%d", tagId))
+	return err
+}
+
+func (d *dbLogger) PurgeItem(ctx context.Context, itemId uint64) error {
+	start := time.Now()
+	err := d.db.PurgeItem(ctx, itemId)
+	d.log(ctx, "PurgeItem", start, err, fmt.Sprintf("id=%d", itemId))
+	return err
+}
+
+func (d *dbLogger) PurgeTag(ctx context.Context, tagId uint64) error {
+	start := time.Now()
+	err := d.db.PurgeTag(ctx, tagId)
+	d.log(ctx, "PurgeTag", start, err, fmt.Sprintf("id=%d", tagId))
+	return err
+}
+
+func (d *dbLogger) GetSnapshot(ctx context.Context) (*model.Snapshot, error) {
+	start := time.Now()
+	snapshot, err := d.db.GetSnapshot(ctx)
+	d.log(ctx, "GetSnapshot", start, err, "")
+	return snapshot, err
+}
+
+func (d *dbLogger) RestoreSnapshot(ctx context.Context, snapshot *model.Snapshot, policy model.ConflictPolicy, relocator model.StorageRelocator) (*model.RestoreResult, error) {
+	start := time.Now()
+	result, err := d.db.RestoreSnapshot(ctx, snapshot, policy, relocator)
+	d.log(ctx, "RestoreSnapshot", start, err, fmt.Sprintf("policy=%s", policy))
+	return result, err
+}
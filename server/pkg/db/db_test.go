@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
 	"os"
 	"path/filepath"
 	"testing"
@@ -594,3 +595,544 @@ func TestTagCustomCommands(t *testing.T) {
 // 	assert.NoError(t, db.create(&newItem))
 // 	assert.NotEqual(t, uint64(1), newItem.Dude)
 // }
+
+func TestQueryItems(t *testing.T) {
+	db, err := setupNewDb(t, "query-items.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	action := &model.Tag{Title: "action"}
+	comedy := &model.Tag{Title: "comedy"}
+	watched := &model.Tag{Title: "watched"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, action))
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, comedy))
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, watched))
+
+	items := []*model.Item{
+		{Title: "a.mp4", Origin: "movies/old", DurationSeconds: 600, Height: 480, Width: 640, VideoCodecName: "mpeg4",
+			FileSize: 300, Tags: []*model.Tag{action}},
+		{Title: "b.mp4", Origin: "movies/new", DurationSeconds: 6000, Height: 1080, Width: 1920, VideoCodecName: "h264",
+			FileSize: 100, Tags: []*model.Tag{action, comedy}},
+		{Title: "c.mp4", Origin: "series", DurationSeconds: 1200, Height: 720, Width: 1280, VideoCodecName: "h264",
+			FileSize: 200, Tags: []*model.Tag{comedy, watched}},
+		{Title: "d_1.mp4", Origin: "movies_", DurationSeconds: 60, Height: 2160, Width: 3840, VideoCodecName: "hevc",
+			FileSize: 400},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	highlight := &model.Item{Title: "b.mp4", Origin: "movies/new-1-2", HighlightParentItemId: &items[1].Id}
+	subItem := &model.Item{Title: "c.mp4", Origin: "series-1-2", MainItemId: &items[2].Id}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, highlight))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, subItem))
+
+	titles := func(page *model.ItemsPage) []string {
+		result := make([]string, 0)
+		for _, item := range page.Items {
+			result = append(result, item.Origin+"/"+item.Title)
+		}
+		return result
+	}
+
+	page, err := db.QueryItems(ctx, &model.ItemsQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), page.Total)
+	assert.Len(t, page.Items, 6)
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{Offset: 1, Limit: 2, SortBy: model.SORT_BY_FILE_SIZE, Kind: model.ITEM_KIND_REGULAR})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, []string{"series/c.mp4", "movies/old/a.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{SortBy: model.SORT_BY_RESOLUTION, Descending: true, Kind: model.ITEM_KIND_REGULAR})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies_/d_1.mp4", "movies/new/b.mp4", "series/c.mp4", "movies/old/a.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{AllTags: []uint64{action.Id, comedy.Id}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/new/b.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{AnyTags: []uint64{action.Id, comedy.Id}, ExcludedTags: []uint64{watched.Id},
+		SortBy: model.SORT_BY_TITLE})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/old/a.mp4", "movies/new/b.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{MinDuration: pointer.Float64(600), MaxDuration: pointer.Float64(1200),
+		SortBy: model.SORT_BY_DURATION})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/old/a.mp4", "series/c.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{MinResolution: pointer.Int(720), MaxResolution: pointer.Int(1080), Codec: "h264",
+		SortBy: model.SORT_BY_TITLE})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/new/b.mp4", "series/c.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{OriginPrefix: "movies_", Kind: model.ITEM_KIND_REGULAR})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies_/d_1.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{Kind: model.ITEM_KIND_HIGHLIGHT})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/new-1-2/b.mp4"}, titles(page))
+
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{Kind: model.ITEM_KIND_SUB_ITEM})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"series-1-2/c.mp4"}, titles(page))
+
+	_, err = db.QueryItems(ctx, &model.ItemsQuery{SortBy: "unknown"})
+	assert.Error(t, err)
+	_, err = db.QueryItems(ctx, &model.ItemsQuery{Kind: "unknown"})
+	assert.Error(t, err)
+
+	// a trashed tag no longer matches the items it was on
+	assert.NoError(t, db.RemoveTag(ctx, watched.Id))
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{AnyTags: []uint64{watched.Id}})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items)
+	page, err = db.QueryItems(ctx, &model.ItemsQuery{ExcludedTags: []uint64{watched.Id}, Kind: model.ITEM_KIND_REGULAR})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+}
+
+func TestQueryItemsExpression(t *testing.T) {
+	db, err := setupNewDb(t, "query-items-expression.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	action := &model.Tag{Title: "Action"}
+	watched := &model.Tag{Title: "Watched"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, action))
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, watched))
+
+	items := []*model.Item{
+		{Title: "Matrix.mp4", Origin: "movies", DurationSeconds: 8160, Height: 1080, VideoCodecName: "h264",
+			Tags: []*model.Tag{action}},
+		{Title: "Speed.mkv", Origin: "movies/90s", DurationSeconds: 6960, Height: 480, VideoCodecName: "mpeg4",
+			Tags: []*model.Tag{action, watched}},
+		{Title: "Trailer.mp4", Origin: "movies/trailers", DurationSeconds: 120, Height: 1080, VideoCodecName: "h264",
+			Tags: []*model.Tag{action}},
+		{Title: "Episode.mp4", Origin: "moviesque", DurationSeconds: 2400, Height: 720, VideoCodecName: "hevc"},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	search := func(q string) []string {
+		expression, err := querylang.Parse(q)
+		assert.NoError(t, err, q)
+		page, err := db.QueryItems(ctx, &model.ItemsQuery{Expression: expression, SortBy: model.SORT_BY_TITLE})
+		assert.NoError(t, err, q)
+		result := make([]string, 0)
+		for _, item := range page.Items {
+			result = append(result, item.Title)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"Matrix.mp4", "Trailer.mp4"}, search(`tag:"action" AND NOT tag:"Watched" AND dir:"movies/"`))
+	assert.Equal(t, []string{"Matrix.mp4"}, search(`tag:"Action" AND NOT tag:"Watched" AND duration>20m AND dir:"movies/"`))
+	assert.Equal(t, []string{"Episode.mp4", "Matrix.mp4", "Speed.mkv"}, search(`duration>20m`))
+	assert.Equal(t, []string{"Episode.mp4", "Speed.mkv"}, search(`height<1080`))
+	assert.Equal(t, []string{"Matrix.mp4", "Trailer.mp4"}, search(`codec:H264`))
+	assert.Equal(t, []string{"Episode.mp4", "Speed.mkv"}, search(`codec!=h264`))
+	assert.Equal(t, []string{"Matrix.mp4"}, search(`dir="movies"`))
+	assert.Equal(t, []string{"Episode.mp4"}, search(`dir:moviesque`))
+	assert.Equal(t, []string{"Speed.mkv", "Trailer.mp4"}, search(`tag:watched OR title:trail`))
+	assert.Equal(t, []string{"Episode.mp4", "Matrix.mp4"}, search(`mp4 NOT (tag:Action AND duration<5m)`))
+	assert.Equal(t, []string{"Episode.mp4", "Matrix.mp4", "Speed.mkv", "Trailer.mp4"}, search(`kind:regular`))
+	assert.Empty(t, search(`kind:highlight`))
+
+	assert.NoError(t, db.RemoveTag(ctx, watched.Id))
+	assert.Empty(t, search(`tag:watched`))
+}
+
+func TestSearch(t *testing.T) {
+	db, err := setupNewDb(t, "search.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	if _, err := db.Search(ctx, &model.SearchQuery{Text: "probe"}); errors.Is(err, ErrFullTextSearchUnavailable) {
+		t.Skip("FTS5 is not available, run with -tags sqlite_fts5")
+	}
+
+	comedy := &model.Tag{Title: "Comedy"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, comedy))
+	assert.NoError(t, db.CreateTagAnnotation(ctx, &model.TagAnnotation{Title: "Classic"}))
+	annotation, err := db.GetTagAnnotation(ctx, &model.TagAnnotation{Title: "Classic"})
+	assert.NoError(t, err)
+	comedy.Annotations = []*model.TagAnnotation{annotation}
+	assert.NoError(t, db.UpdateTag(ctx, comedy))
+
+	matrix := &model.Item{Title: "The Matrix.mp4", Origin: "movies/scifi"}
+	airplane := &model.Item{Title: "Airplane.mkv", Origin: "movies/comedy", Tags: []*model.Tag{comedy}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, matrix))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, airplane))
+
+	search := func(text string, subtitles bool) []model.SearchHit {
+		results, err := db.Search(ctx, &model.SearchQuery{Text: text, Subtitles: subtitles})
+		assert.NoError(t, err, text)
+		return results.Hits
+	}
+
+	hits := search("matr", false)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, matrix.Id, hits[0].ItemId)
+	assert.Equal(t, model.SEARCH_SOURCE_ITEM, hits[0].Source)
+	assert.Contains(t, hits[0].Snippet, "<b>Matrix</b>")
+
+	assert.Len(t, search("scifi", false), 1)
+	assert.Len(t, search("movies", false), 2)
+	assert.Len(t, search("comedy", false), 1)
+	assert.Len(t, search("classic", false), 1)
+	assert.Empty(t, search(`"matrix airplane"`, false))
+	assert.Empty(t, search(`"  "`, false))
+
+	comedy.Title = "Spoof"
+	assert.NoError(t, db.UpdateTag(ctx, comedy))
+	assert.Equal(t, airplane.Id, search("spoof", false)[0].ItemId)
+
+	assert.NoError(t, db.RemoveTagFromItem(ctx, airplane.Id, comedy.Id))
+	assert.Empty(t, search("spoof", false))
+
+	assert.NoError(t, db.IndexSubtitle(ctx, matrix.Id, "matrix.srt", model.Subtitle{Items: []model.SubtitleItem{
+		{StartMillis: 1000, EndMillis: 2000, Text: "Wake up, Neo"},
+		{StartMillis: 5000, EndMillis: 7000, Text: "There is no spoon"},
+	}}))
+	assert.Empty(t, search("spoon", false))
+	hits = search("no spoon", true)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, model.SEARCH_SOURCE_SUBTITLE, hits[0].Source)
+	assert.Equal(t, matrix.Id, hits[0].ItemId)
+	assert.Equal(t, "matrix.srt", hits[0].SubtitleUrl)
+	assert.Equal(t, int64(5000), *hits[0].StartMillis)
+	assert.Equal(t, int64(7000), *hits[0].EndMillis)
+	assert.Equal(t, "There is <b>no</b> <b>spoon</b>", hits[0].Snippet)
+
+	results, err := db.Search(ctx, &model.SearchQuery{Text: "movies", Subtitles: true, Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	assert.Len(t, results.Hits, 1)
+	assert.Equal(t, int64(2), results.Total)
+
+	assert.NoError(t, db.RemoveItem(ctx, matrix.Id))
+	assert.Empty(t, search("neo", true))
+	assert.Empty(t, search("matrix", true))
+
+	assert.NoError(t, db.RebuildSearchIndex(ctx))
+	assert.Len(t, search("airplane", false), 1)
+}
+
+func TestToFtsMatch(t *testing.T) {
+	assert.Equal(t, `"wake" "up" "neo"*`, toFtsMatch("wake up  neo"))
+	assert.Equal(t, `"wake up, neo"`, toFtsMatch(`"wake up, neo"`))
+	assert.Equal(t, `"a-b" "c"*`, toFtsMatch(`a-b "c`))
+	assert.Equal(t, "", toFtsMatch("   "))
+	assert.Equal(t, "", toFtsMatch(`""`))
+}
+
+func TestSetTagItems(t *testing.T) {
+	db, err := setupNewDb(t, "set-tag-items.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	tag := &model.Tag{Title: "tag"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, tag))
+	for i := 1; i <= 4; i++ {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: fmt.Sprintf("item%d", i), Origin: "origin"}))
+	}
+
+	tagItems := func() []uint64 {
+		tag, err := db.GetTag(ctx, tag.Id)
+		assert.NoError(t, err)
+		result := make([]uint64, 0)
+		for _, item := range tag.Items {
+			result = append(result, item.Id)
+		}
+		return result
+	}
+
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, []uint64{1, 2}))
+	assert.Equal(t, []uint64{1, 2}, tagItems())
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, []uint64{2, 3, 4}))
+	assert.Equal(t, []uint64{2, 3, 4}, tagItems())
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, []uint64{}))
+	assert.Empty(t, tagItems())
+
+	// more ids than sqlite allows binding to a single statement
+	tagItemsCount := func() int64 {
+		var count int64
+		assert.NoError(t, db.(*databaseImpl).db.Model(&model.TagItem{}).Where("tag_id = ?", tag.Id).Count(&count).Error)
+		return count
+	}
+	manyItemIds := func(first uint64) []uint64 {
+		result := make([]uint64, 40000)
+		for i := range result {
+			result[i] = first + uint64(i)
+		}
+		return result
+	}
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, manyItemIds(1)))
+	assert.Equal(t, int64(40000), tagItemsCount())
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, manyItemIds(20001)))
+	assert.Equal(t, int64(40000), tagItemsCount())
+	assert.NoError(t, db.SetTagItems(ctx, tag.Id, []uint64{1}))
+	assert.Equal(t, []uint64{1}, tagItems())
+}
+
+func TestItemRatingAndFavorite(t *testing.T) {
+	db, err := setupNewDb(t, "item-rating.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	items := []*model.Item{
+		{Title: "a", Origin: "origin"},
+		{Title: "b", Origin: "origin"},
+		{Title: "c", Origin: "origin"},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	assert.NoError(t, db.SetItemRating(ctx, items[0].Id, 5))
+	assert.NoError(t, db.SetItemRating(ctx, items[1].Id, 3))
+	assert.NoError(t, db.SetItemFavorite(ctx, items[1].Id, true))
+	assert.Error(t, db.SetItemRating(ctx, 100, 3))
+
+	item, err := db.GetItem(ctx, items[1].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, item.Rating)
+	assert.True(t, item.Favorite)
+
+	// zero values are saved too
+	assert.NoError(t, db.SetItemRating(ctx, items[1].Id, 0))
+	item, err = db.GetItem(ctx, items[1].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item.Rating)
+	assert.True(t, item.Favorite)
+	assert.NoError(t, db.SetItemRating(ctx, items[1].Id, 3))
+
+	titles := func(query *model.ItemsQuery) []string {
+		page, err := db.QueryItems(ctx, query)
+		assert.NoError(t, err)
+		result := make([]string, 0)
+		for _, item := range page.Items {
+			result = append(result, item.Title)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, titles(&model.ItemsQuery{SortBy: model.SORT_BY_RATING, Descending: true}))
+	assert.Equal(t, []string{"a", "b"}, titles(&model.ItemsQuery{MinRating: pointer.Int(3)}))
+	assert.Equal(t, []string{"b", "c"}, titles(&model.ItemsQuery{MaxRating: pointer.Int(3)}))
+	assert.Equal(t, []string{"b"}, titles(&model.ItemsQuery{Favorite: pointer.Bool(true)}))
+	assert.Equal(t, []string{"a", "c"}, titles(&model.ItemsQuery{Favorite: pointer.Bool(false)}))
+
+	expression, err := querylang.Parse("rating>3")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, titles(&model.ItemsQuery{Expression: expression}))
+}
+
+func TestItemMetadata(t *testing.T) {
+	db, err := setupNewDb(t, "item-metadata.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	items := []*model.Item{
+		{Title: "a", Origin: "origin"},
+		{Title: "b", Origin: "origin"},
+		{Title: "c", Origin: "origin"},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	assert.NoError(t, db.SetItemMetadata(ctx, items[0].Id, &model.ItemMetadata{ReleaseYear: 1999, Language: "en",
+		Description: "first", ExternalIds: model.ExternalIds{model.EXTERNAL_ID_IMDB: "tt0133093"}}))
+	assert.NoError(t, db.SetItemMetadata(ctx, items[1].Id, &model.ItemMetadata{ReleaseYear: 2004, Language: "fr"}))
+	assert.Error(t, db.SetItemMetadata(ctx, 100, &model.ItemMetadata{ReleaseYear: 2004}))
+
+	item, err := db.GetItem(ctx, items[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1999, item.ReleaseYear)
+	assert.Equal(t, "first", item.Description)
+	assert.Equal(t, "tt0133093", item.ExternalIds[model.EXTERNAL_ID_IMDB])
+
+	titles := func(query *model.ItemsQuery) []string {
+		page, err := db.QueryItems(ctx, query)
+		assert.NoError(t, err)
+		result := make([]string, 0)
+		for _, item := range page.Items {
+			result = append(result, item.Title)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"b"}, titles(&model.ItemsQuery{MinYear: pointer.Int(2000)}))
+	assert.Equal(t, []string{"a"}, titles(&model.ItemsQuery{MaxYear: pointer.Int(2000)}))
+	assert.Equal(t, []string{"b"}, titles(&model.ItemsQuery{Language: "FR"}))
+	assert.Equal(t, []string{"a"}, titles(&model.ItemsQuery{
+		ExternalId: &model.ExternalId{Provider: model.EXTERNAL_ID_IMDB, Id: "tt0133093"}}))
+
+	for query, expected := range map[string][]string{
+		"year<2000":          {"a"},
+		"year>=1990":         {"a", "b"},
+		"language:en":        {"a"},
+		"imdb=tt0133093":     {"a"},
+		"not imdb=tt0133093": {"b", "c"},
+	} {
+		expression, err := querylang.Parse(query)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, titles(&model.ItemsQuery{Expression: expression}), query)
+	}
+
+	// empty fields clear the stored values
+	assert.NoError(t, db.SetItemMetadata(ctx, items[0].Id, &model.ItemMetadata{}))
+	item, err = db.GetItem(ctx, items[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ItemMetadata{}, item.ItemMetadata)
+}
+
+func TestUpdateItemsTags(t *testing.T) {
+	db, err := setupNewDb(t, "update-items-tags.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	first := &model.Tag{Title: "first"}
+	second := &model.Tag{Title: "second"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, first))
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, second))
+
+	items := []*model.Item{
+		{Title: "a", Origin: "origin", Tags: []*model.Tag{first}},
+		{Title: "b", Origin: "origin"},
+		{Title: "c", Origin: "origin", Tags: []*model.Tag{first}},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	tagIds := func(itemId uint64) []uint64 {
+		item, err := db.GetItem(ctx, itemId)
+		assert.NoError(t, err)
+		result := make([]uint64, 0)
+		for _, tag := range item.Tags {
+			result = append(result, tag.Id)
+		}
+		return result
+	}
+
+	assert.NoError(t, db.UpdateItemsTags(ctx, []uint64{items[0].Id, items[1].Id}, []uint64{second.Id}, []uint64{first.Id}))
+	assert.Equal(t, []uint64{second.Id}, tagIds(items[0].Id))
+	assert.Equal(t, []uint64{second.Id}, tagIds(items[1].Id))
+	assert.Equal(t, []uint64{first.Id}, tagIds(items[2].Id))
+
+	// adding an existing tag is a no-op
+	assert.NoError(t, db.UpdateItemsTags(ctx, []uint64{items[0].Id}, []uint64{second.Id}, nil))
+	assert.Equal(t, []uint64{second.Id}, tagIds(items[0].Id))
+}
+
+func TestVideoHashes(t *testing.T) {
+	db, err := setupNewDb(t, "video-hashes.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	item := &model.Item{Title: "item", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+
+	assert.NoError(t, db.SaveVideoHash(ctx, &model.VideoHash{ItemId: item.Id, Hashes: "0000000000000001"}))
+	assert.NoError(t, db.SaveVideoHash(ctx, &model.VideoHash{ItemId: item.Id, Hashes: "0000000000000002"}))
+
+	hashes, err := db.GetVideoHashes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.VideoHash{{ItemId: item.Id, Hashes: "0000000000000002"}}, *hashes)
+
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	hashes, err = db.GetVideoHashes(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, *hashes)
+}
+
+func TestTrash(t *testing.T) {
+	db, err := setupNewDb(t, "trash.sqlite")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	category := &model.Tag{Title: "category"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, category))
+	tag := &model.Tag{Title: "tag", ParentID: &category.Id}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, tag))
+	item := &model.Item{Title: "item", Origin: "origin", Tags: []*model.Tag{tag}, Covers: []model.Cover{{Url: "cover"}}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	highlight := &model.Item{Title: "highlight", Origin: "origin", HighlightParentItemId: &item.Id}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, highlight))
+	assert.NoError(t, db.SaveWatchProgress(ctx, &model.WatchProgress{ItemId: item.Id, PositionSeconds: 10}))
+
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	_, err = db.GetItem(ctx, item.Id)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = db.GetItem(ctx, highlight.Id)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = db.GetWatchProgress(ctx, item.Id)
+	assert.Error(t, err)
+	count, err := db.GetItemsCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	deleted, err := db.GetDeletedItems(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, *deleted, 2)
+	assert.True(t, (*deleted)[0].DeletedAt.Valid)
+
+	assert.NoError(t, db.RestoreItem(ctx, item.Id))
+	restored, err := db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Len(t, restored.Tags, 1)
+	assert.Len(t, restored.Covers, 1)
+	assert.Len(t, restored.Highlights, 1)
+	_, err = db.GetWatchProgress(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, db.RestoreItem(ctx, item.Id), gorm.ErrRecordNotFound)
+
+	assert.NoError(t, db.RemoveTag(ctx, tag.Id))
+	restored, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, restored.Tags)
+	deletedTags, err := db.GetDeletedTags(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, *deletedTags, 1)
+
+	// creating a tag with the title of a trashed one reuses it without its old items
+	revived := &model.Tag{Title: "tag", ParentID: &category.Id}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, revived))
+	assert.Equal(t, tag.Id, revived.Id)
+	revivedTag, err := db.GetTag(ctx, tag.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, revivedTag.Items)
+	restored, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, restored.Tags)
+	assert.NoError(t, db.UpdateItemsTags(ctx, []uint64{item.Id}, []uint64{tag.Id}, nil))
+
+	// same for an item whose file shows up again
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	again := &model.Item{Title: "item", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, again))
+	assert.Equal(t, item.Id, again.Id)
+	restored, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Len(t, restored.Tags, 1)
+	assert.Len(t, restored.Highlights, 1)
+
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	assert.NoError(t, db.PurgeItem(ctx, item.Id))
+	deleted, err = db.GetDeletedItems(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, *deleted)
+	assert.ErrorIs(t, db.RestoreItem(ctx, item.Id), gorm.ErrRecordNotFound)
+
+	assert.NoError(t, db.RemoveTag(ctx, tag.Id))
+	assert.NoError(t, db.PurgeTag(ctx, tag.Id))
+	deletedTags, err = db.GetDeletedTags(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, *deletedTags)
+}
@@ -22,6 +22,9 @@ func (d *databaseImpl) CreateOrUpdateItem(ctx context.Context, item *model.Item)
 		}
 
 		existing, err := d.GetItem(ctx, "title = ? and origin = ?", item.Title, item.Origin)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			existing, err = d.reviveItem(ctx, "title = ? and origin = ?", item.Title, item.Origin)
+		}
 
 		if err != nil {
 			return err
@@ -38,8 +41,51 @@ func (d *databaseImpl) UpdateItem(ctx context.Context, item *model.Item) error {
 	return d.update(ctx, item)
 }
 
+// RemoveItem moves the item to the trash along with its highlights and sub items,
+// they keep their tags and covers until purged, see PurgeItem
 func (d *databaseImpl) RemoveItem(ctx context.Context, itemId uint64) error {
-	return d.deleteWithAssociations(ctx, model.Item{Id: itemId})
+	return d.handleError(d.db.WithContext(ctx).
+		Where("id = ? or highlight_parent_item_id = ? or main_item_id = ?", itemId, itemId, itemId).
+		Delete(&model.Item{}).Error)
+}
+
+// SetItemRating and SetItemFavorite update a single column, so zero values are saved
+// as well, unlike UpdateItem which skips them.
+func (d *databaseImpl) SetItemRating(ctx context.Context, itemId uint64, rating int) error {
+	return d.updateItemColumn(ctx, itemId, "rating", rating)
+}
+
+func (d *databaseImpl) SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error {
+	return d.updateItemColumn(ctx, itemId, "favorite", favorite)
+}
+
+// SetItemMetadata replaces all the metadata fields, empty fields clear the stored values
+func (d *databaseImpl) SetItemMetadata(ctx context.Context, itemId uint64, metadata *model.ItemMetadata) error {
+	tx := d.db.WithContext(ctx).Model(&model.Item{Id: itemId}).
+		Select("release_year", "original_title", "description", "language", "external_ids").
+		Updates(&model.Item{ItemMetadata: *metadata})
+	if tx.Error != nil {
+		return d.handleError(tx.Error)
+	}
+
+	if tx.RowsAffected == 0 {
+		return d.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (d *databaseImpl) updateItemColumn(ctx context.Context, itemId uint64, column string, value any) error {
+	tx := d.db.WithContext(ctx).Model(&model.Item{Id: itemId}).Update(column, value)
+	if tx.Error != nil {
+		return d.handleError(tx.Error)
+	}
+
+	if tx.RowsAffected == 0 {
+		return d.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return nil
 }
 
 func (d *databaseImpl) RemoveTagFromItem(ctx context.Context, itemId uint64, tagId uint64) error {
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"fmt"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+var numericColumns = map[querylang.Field]string{
+	querylang.FIELD_DURATION: "duration_seconds",
+	querylang.FIELD_WIDTH:    "width",
+	querylang.FIELD_HEIGHT:   "height",
+	querylang.FIELD_SIZE:     "file_size",
+	querylang.FIELD_RATING:   "rating",
+}
+
+const externalIdCondition = "coalesce(json_extract(external_ids, ?), '') = ?"
+
+const itemsWithTagTitle = "id in (select tag_items.item_id from tag_items join tags on tags.id = tag_items.tag_id " +
+	"where tags.title = ? collate nocase and tags.deleted_at is null)"
+
+func compileExpression(node querylang.Node) (string, []any, error) {
+	switch n := node.(type) {
+	case *querylang.And:
+		return compileBinary(n.Left, n.Right, "and")
+	case *querylang.Or:
+		return compileBinary(n.Left, n.Right, "or")
+	case *querylang.Not:
+		sql, args, err := compileExpression(n.Operand)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("not (%s)", sql), args, nil
+	case *querylang.Condition:
+		sql, args, err := compileCondition(n)
+		if err != nil {
+			return "", nil, err
+		}
+		if n.Operator == querylang.OP_NOT_EQUAL {
+			return fmt.Sprintf("not (%s)", sql), args, nil
+		}
+		return sql, args, nil
+	default:
+		return "", nil, errors.Errorf("unknown expression node %v", node)
+	}
+}
+
+func compileBinary(left querylang.Node, right querylang.Node, operator string) (string, []any, error) {
+	leftSql, leftArgs, err := compileExpression(left)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rightSql, rightArgs, err := compileExpression(right)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("(%s) %s (%s)", leftSql, operator, rightSql), append(leftArgs, rightArgs...), nil
+}
+
+// Compiles the positive form of the condition, != is handled by the caller
+func compileCondition(c *querylang.Condition) (string, []any, error) {
+	if column, ok := numericColumns[c.Field]; ok {
+		return compileNumericCondition(column, c)
+	}
+
+	switch c.Field {
+	case querylang.FIELD_TAG:
+		return itemsWithTagTitle, []any{c.Text}, nil
+	case querylang.FIELD_DIR:
+		dir := strings.TrimSuffix(c.Text, "/")
+		if c.Operator == querylang.OP_EQUAL {
+			return "origin = ?", []any{dir}, nil
+		}
+		return "(origin = ? or origin like ? escape '\\')", []any{dir, escapeLike(dir) + "/%"}, nil
+	case querylang.FIELD_TITLE:
+		if c.Operator == querylang.OP_EQUAL {
+			return "title = ? collate nocase", []any{c.Text}, nil
+		}
+		return "title like ? escape '\\'", []any{"%" + escapeLike(c.Text) + "%"}, nil
+	case querylang.FIELD_CODEC:
+		return "video_codec_name = ? collate nocase", []any{c.Text}, nil
+	case querylang.FIELD_AUDIO:
+		return "audio_codec_name = ? collate nocase", []any{c.Text}, nil
+	case querylang.FIELD_YEAR:
+		// items without a known year don't match any year condition
+		sql, args, err := compileNumericCondition("release_year", c)
+		return fmt.Sprintf("release_year > 0 and %s", sql), args, err
+	case querylang.FIELD_LANGUAGE:
+		return "language = ? collate nocase", []any{c.Text}, nil
+	case querylang.FIELD_IMDB:
+		return externalIdCondition, []any{externalIdPath(model.EXTERNAL_ID_IMDB), c.Text}, nil
+	case querylang.FIELD_KIND:
+		sql, err := itemKindCondition(model.ItemKind(strings.ToLower(c.Text)))
+		return sql, nil, err
+	default:
+		return "", nil, errors.Errorf("unsupported field %s", c.Field)
+	}
+}
+
+func compileNumericCondition(column string, c *querylang.Condition) (string, []any, error) {
+	operator := string(c.Operator)
+	switch c.Operator {
+	case querylang.OP_MATCH, querylang.OP_NOT_EQUAL:
+		operator = "="
+	}
+
+	return fmt.Sprintf("%s %s ?", column, operator), []any{c.Number}, nil
+}
+
+func externalIdPath(provider string) string {
+	return fmt.Sprintf("$.%q", provider)
+}
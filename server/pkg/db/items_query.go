@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+)
+
+var itemsSortColumns = map[model.ItemsSortKey]string{
+	model.SORT_BY_ID:            "id",
+	model.SORT_BY_TITLE:         "title",
+	model.SORT_BY_DURATION:      "duration_seconds",
+	model.SORT_BY_FILE_SIZE:     "file_size",
+	model.SORT_BY_LAST_MODIFIED: "last_modified",
+	model.SORT_BY_RESOLUTION:    "width * height",
+	model.SORT_BY_RATING:        "rating",
+}
+
+func (d *databaseImpl) QueryItems(ctx context.Context, query *model.ItemsQuery) (*model.ItemsPage, error) {
+	order, err := itemsOrder(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := d.handleError(filterItems(d.db.WithContext(ctx).Model(&model.Item{}), query).Count(&total).Error); err != nil {
+		return nil, err
+	}
+
+	tx := filterItems(d.getItemModel(ctx, false), query).Order(order).Offset(query.Offset)
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	items := make([]model.Item, 0)
+	if err := d.handleError(tx.Find(&items).Error); err != nil {
+		return nil, err
+	}
+
+	return &model.ItemsPage{
+		Items:  items,
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}, nil
+}
+
+func itemsOrder(query *model.ItemsQuery) (string, error) {
+	column, ok := itemsSortColumns[query.SortBy]
+	if !ok {
+		return "", errors.Errorf("unknown sort key %s", query.SortBy)
+	}
+
+	direction := "asc"
+	if query.Descending {
+		direction = "desc"
+	}
+
+	// id is appended so pages are stable when the sort column has duplicates
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction), nil
+}
+
+func filterItems(tx *gorm.DB, query *model.ItemsQuery) *gorm.DB {
+	for _, tagId := range query.AllTags {
+		tx = tx.Where("id in (select item_id from tag_items where tag_id = ? and "+liveTagsCondition+")", tagId)
+	}
+
+	if len(query.AnyTags) > 0 {
+		tx = tx.Where("id in (select item_id from tag_items where tag_id in ? and "+liveTagsCondition+")", query.AnyTags)
+	}
+
+	if len(query.ExcludedTags) > 0 {
+		tx = tx.Where("id not in (select item_id from tag_items where tag_id in ? and "+liveTagsCondition+")", query.ExcludedTags)
+	}
+
+	if query.MinDuration != nil {
+		tx = tx.Where("duration_seconds >= ?", *query.MinDuration)
+	}
+
+	if query.MaxDuration != nil {
+		tx = tx.Where("duration_seconds <= ?", *query.MaxDuration)
+	}
+
+	if query.MinResolution != nil {
+		tx = tx.Where("height >= ?", *query.MinResolution)
+	}
+
+	if query.MaxResolution != nil {
+		tx = tx.Where("height <= ?", *query.MaxResolution)
+	}
+
+	if query.Codec != "" {
+		tx = tx.Where("video_codec_name = ?", query.Codec)
+	}
+
+	if query.MinRating != nil {
+		tx = tx.Where("rating >= ?", *query.MinRating)
+	}
+
+	if query.MaxRating != nil {
+		tx = tx.Where("rating <= ?", *query.MaxRating)
+	}
+
+	if query.Favorite != nil {
+		tx = tx.Where("favorite = ?", *query.Favorite)
+	}
+
+	if query.MinYear != nil {
+		tx = tx.Where("release_year >= ?", *query.MinYear)
+	}
+
+	if query.MaxYear != nil {
+		tx = tx.Where("release_year > 0 and release_year <= ?", *query.MaxYear)
+	}
+
+	if query.Language != "" {
+		tx = tx.Where("language = ? collate nocase", query.Language)
+	}
+
+	if query.ExternalId != nil {
+		tx = tx.Where(externalIdCondition, externalIdPath(query.ExternalId.Provider), query.ExternalId.Id)
+	}
+
+	if query.OriginPrefix != "" {
+		tx = tx.Where("origin like ? escape '\\'", escapeLike(query.OriginPrefix)+"%")
+	}
+
+	if query.Kind != model.ITEM_KIND_ANY {
+		kindSql, err := itemKindCondition(query.Kind)
+		if err != nil {
+			tx.AddError(err)
+		} else {
+			tx = tx.Where(kindSql)
+		}
+	}
+
+	if query.Expression != nil {
+		expressionSql, args, err := compileExpression(query.Expression)
+		if err != nil {
+			tx.AddError(err)
+		} else {
+			tx = tx.Where(expressionSql, args...)
+		}
+	}
+
+	return tx
+}
+
+func itemKindCondition(kind model.ItemKind) (string, error) {
+	switch kind {
+	case model.ITEM_KIND_REGULAR:
+		return "main_item_id is null and highlight_parent_item_id is null", nil
+	case model.ITEM_KIND_SUB_ITEM:
+		return "main_item_id is not null", nil
+	case model.ITEM_KIND_HIGHLIGHT:
+		return "highlight_parent_item_id is not null", nil
+	default:
+		return "", errors.Errorf("unknown item kind %s", kind)
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
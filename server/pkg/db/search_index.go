@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+)
+
+// The full text index lives in two FTS5 tables. items_fts holds one row per item
+// (rowid = item id) and is kept up to date by triggers on the items, tags and
+// annotations tables, subtitles_fts holds one row per subtitle line and is filled
+// explicitly through IndexSubtitle.
+//
+// FTS5 is only compiled into go-sqlite3 with the sqlite_fts5 build tag, without it
+// the index is not created and searching returns ErrFullTextSearchUnavailable.
+
+var ErrFullTextSearchUnavailable = errors.Errorf("full text search is unavailable, build with -tags sqlite_fts5")
+
+const (
+	snippetOpen  = "<b>"
+	snippetClose = "</b>"
+	snippetTrim  = "..."
+	snippetWords = 12
+)
+
+const itemTagsTitles = `(select coalesce(group_concat(t.title, ' '), '') from tag_items ti
+	join tags t on t.id = ti.tag_id where ti.item_id = i.id and t.deleted_at is null)`
+
+const itemAnnotationsTitles = `(select coalesce(group_concat(a.title, ' '), '') from tag_items ti
+	join tags_annotations ta on ta.tag_id = ti.tag_id
+	join tag_annotations a on a.id = ta.tag_annotation_id where ti.item_id = i.id)`
+
+// reindexItems returns the statements re-indexing the items matching the given
+// condition, the condition may refer to the trigger's new/old rows.
+func reindexItems(itemsCondition string) string {
+	return fmt.Sprintf(`delete from items_fts where rowid in (select i.id from items i where %[1]s);
+	insert into items_fts(rowid, title, origin, tags, annotations)
+		select i.id, i.title, i.origin, %[2]s, %[3]s from items i where %[1]s;`,
+		itemsCondition, itemTagsTitles, itemAnnotationsTitles)
+}
+
+var searchIndexTriggers = map[string]string{
+	"items_fts_item_insert": `after insert on items begin ` + reindexItems("i.id = new.id") + ` end`,
+	"items_fts_item_update": `after update of title, origin on items begin ` + reindexItems("i.id = new.id") + ` end`,
+	"items_fts_item_delete": `after delete on items begin
+		delete from items_fts where rowid = old.id;
+		delete from subtitles_fts where item_id = old.id; end`,
+	"items_fts_tag_item_insert": `after insert on tag_items begin ` + reindexItems("i.id = new.item_id") + ` end`,
+	"items_fts_tag_item_delete": `after delete on tag_items begin ` + reindexItems("i.id = old.item_id") + ` end`,
+	"items_fts_tag_update": `after update of title, deleted_at on tags begin ` +
+		reindexItems("i.id in (select item_id from tag_items where tag_id = new.id)") + ` end`,
+	"items_fts_tag_delete": `after delete on tags begin ` +
+		reindexItems("i.id in (select item_id from tag_items where tag_id = old.id)") + ` end`,
+	"items_fts_tag_annotation_insert": `after insert on tags_annotations begin ` +
+		reindexItems("i.id in (select item_id from tag_items where tag_id = new.tag_id)") + ` end`,
+	"items_fts_tag_annotation_delete": `after delete on tags_annotations begin ` +
+		reindexItems("i.id in (select item_id from tag_items where tag_id = old.tag_id)") + ` end`,
+	"items_fts_annotation_update": `after update of title on tag_annotations begin ` +
+		reindexItems(`i.id in (select ti.item_id from tag_items ti
+			join tags_annotations ta on ta.tag_id = ti.tag_id where ta.tag_annotation_id = new.id)`) + ` end`,
+}
+
+func isFts5Available(db *gorm.DB) bool {
+	err := db.Exec("create virtual table temp.fts5_probe using fts5(text)").Error
+	if err != nil {
+		logger.Warningf("FTS5 is not available, full text search is disabled - %s", err)
+		return false
+	}
+
+	return db.Exec("drop table temp.fts5_probe").Error == nil
+}
+
+func initSearchIndex(db *gorm.DB) error {
+	var existing int64
+	if err := db.Raw("select count(*) from sqlite_master where type = 'table' and name = 'items_fts'").
+		Scan(&existing).Error; err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`create virtual table if not exists items_fts using fts5(
+			title, origin, tags, annotations, tokenize = 'unicode61 remove_diacritics 2')`).Error; err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		if err := tx.Exec(`create virtual table if not exists subtitles_fts using fts5(
+			text, item_id unindexed, url unindexed, start_millis unindexed, end_millis unindexed,
+			tokenize = 'unicode61 remove_diacritics 2')`).Error; err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		for name, body := range searchIndexTriggers {
+			if err := tx.Exec(fmt.Sprintf("drop trigger if exists %s", name)).Error; err != nil {
+				return errors.Wrap(err, 0)
+			}
+
+			if err := tx.Exec(fmt.Sprintf("create trigger %s %s", name, body)).Error; err != nil {
+				return errors.Wrap(err, 0)
+			}
+		}
+
+		if existing != 0 {
+			return nil
+		}
+
+		logger.Infof("Building full text search index")
+		if err := tx.Exec(reindexItems("1 = 1")).Error; err != nil {
+			return errors.Wrap(err, 0)
+		}
+
+		return nil
+	})
+}
+
+func (d *databaseImpl) RebuildSearchIndex(ctx context.Context) error {
+	if !d.fullTextSearch {
+		return ErrFullTextSearchUnavailable
+	}
+
+	return d.handleError(d.db.WithContext(ctx).Exec(reindexItems("1 = 1")).Error)
+}
+
+func (d *databaseImpl) IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle model.Subtitle) error {
+	if !d.fullTextSearch {
+		return nil
+	}
+
+	return d.handleError(d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("delete from subtitles_fts where item_id = ? and url = ?", itemId, url).Error; err != nil {
+			return err
+		}
+
+		for _, line := range subtitle.Items {
+			if strings.TrimSpace(line.Text) == "" {
+				continue
+			}
+
+			if err := tx.Exec("insert into subtitles_fts(text, item_id, url, start_millis, end_millis) values (?, ?, ?, ?, ?)",
+				line.Text, itemId, url, line.StartMillis, line.EndMillis).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+}
+
+func (d *databaseImpl) RemoveSubtitleIndex(ctx context.Context, url string) error {
+	if !d.fullTextSearch {
+		return nil
+	}
+
+	return d.handleError(d.db.WithContext(ctx).Exec("delete from subtitles_fts where url = ?", url).Error)
+}
+
+func (d *databaseImpl) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchResults, error) {
+	if !d.fullTextSearch {
+		return nil, ErrFullTextSearchUnavailable
+	}
+
+	match := toFtsMatch(query.Text)
+	if match == "" {
+		return &model.SearchResults{Hits: []model.SearchHit{}, Offset: query.Offset, Limit: query.Limit}, nil
+	}
+
+	// bm25 weights: title, origin, tags, annotations
+	itemsHits := fmt.Sprintf(`select f.rowid as item_id, i.title as title, '%s' as source,
+		snippet(items_fts, -1, ?, ?, ?, %d) as snippet, bm25(items_fts, 10.0, 2.0, 5.0, 3.0) as rank,
+		'' as subtitle_url, null as start_millis, null as end_millis
+		from items_fts f join items i on i.id = f.rowid where items_fts match ? and i.deleted_at is null`,
+		model.SEARCH_SOURCE_ITEM, snippetWords)
+	args := []any{snippetOpen, snippetClose, snippetTrim, match}
+
+	sql := itemsHits
+	if query.Subtitles {
+		sql = fmt.Sprintf(`%s union all select s.item_id, i.title, '%s',
+			snippet(subtitles_fts, 0, ?, ?, ?, %d), bm25(subtitles_fts),
+			s.url, s.start_millis, s.end_millis
+			from subtitles_fts s join items i on i.id = s.item_id where subtitles_fts match ? and i.deleted_at is null`,
+			itemsHits, model.SEARCH_SOURCE_SUBTITLE, snippetWords)
+		args = append(args, snippetOpen, snippetClose, snippetTrim, match)
+	}
+
+	var total int64
+	if err := d.db.WithContext(ctx).Raw(fmt.Sprintf("select count(*) from (%s)", sql), args...).
+		Scan(&total).Error; err != nil {
+		return nil, d.handleError(err)
+	}
+
+	sql = fmt.Sprintf("select * from (%s) order by rank, item_id, start_millis", sql)
+	if query.Limit > 0 {
+		sql = fmt.Sprintf("%s limit %d offset %d", sql, query.Limit, query.Offset)
+	} else if query.Offset > 0 {
+		sql = fmt.Sprintf("%s limit -1 offset %d", sql, query.Offset)
+	}
+
+	hits := make([]model.SearchHit, 0)
+	if err := d.db.WithContext(ctx).Raw(sql, args...).Scan(&hits).Error; err != nil {
+		return nil, d.handleError(err)
+	}
+
+	return &model.SearchResults{
+		Hits:   hits,
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}, nil
+}
+
+// toFtsMatch turns free text into an FTS5 match expression, every word is quoted
+// so user input can't break the FTS5 syntax. Words are and-ed, the last one is a
+// prefix match, text wrapped in double quotes is matched as a phrase.
+func toFtsMatch(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) > 1 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
+		phrase := strings.TrimSpace(text[1 : len(text)-1])
+		if phrase == "" {
+			return ""
+		}
+
+		return quoteFtsTerm(phrase)
+	}
+
+	words := strings.Fields(strings.ReplaceAll(text, `"`, " "))
+	if len(words) == 0 {
+		return ""
+	}
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = quoteFtsTerm(word)
+	}
+
+	terms[len(terms)-1] += "*"
+	return strings.Join(terms, " ")
+}
+
+func quoteFtsTerm(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
@@ -0,0 +1,615 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"my-collection/server/pkg/model"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// A snapshot holds the live (not trashed) entities, restoring matches them to the existing ones by
+// their natural keys - items by title and origin, tags by title and parent, tag image types by
+// nickname, annotations by title and directories by path. New entities keep their archived id
+// when it's free, otherwise they get a new one and their storage urls are relocated. Unfinished
+// tasks are only restored into an empty database, the processor queues them again on start.
+
+const liveItemsCondition = "item_id in (select id from items where deleted_at is null)"
+const liveTagsCondition = "tag_id in (select id from tags where deleted_at is null)"
+
+func (d *databaseImpl) GetSnapshot(ctx context.Context) (*model.Snapshot, error) {
+	db := d.db.WithContext(ctx)
+	snapshot := &model.Snapshot{}
+	queries := []*gorm.DB{
+		db.Preload("Covers").Order("id").Find(&snapshot.Items),
+		db.Order("id").Find(&snapshot.Tags),
+		db.Table("tag_items").Where(liveItemsCondition).Where(liveTagsCondition).
+			Order("tag_id, item_id").Find(&snapshot.TagItems),
+		db.Order("id").Find(&snapshot.TagImageTypes),
+		db.Where(liveTagsCondition).Order("id").Find(&snapshot.TagImages),
+		db.Order("id").Find(&snapshot.TagAnnotations),
+		db.Table("tags_annotations").Where(liveTagsCondition).
+			Order("tag_id, tag_annotation_id").Find(&snapshot.TagsAnnotations),
+		db.Order("path").Find(&snapshot.Directories),
+		db.Table("directory_tags").Where(liveTagsCondition).
+			Order("directory_path, tag_id").Find(&snapshot.DirectoryTags),
+		db.Where(liveTagsCondition).Order("id").Find(&snapshot.TagCustomCommands),
+		db.Where(liveItemsCondition).Order("item_id").Find(&snapshot.WatchProgress),
+		db.Where("processing_end is null").Order("enequeue_time").Find(&snapshot.Tasks),
+	}
+
+	for _, query := range queries {
+		if query.Error != nil {
+			return nil, d.handleError(query.Error)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// RestoreSnapshot writes the snapshot in a single transaction, existing entities are kept or
+// overwritten according to the policy, associations are always merged
+func (d *databaseImpl) RestoreSnapshot(ctx context.Context, snapshot *model.Snapshot, policy model.ConflictPolicy,
+	relocator model.StorageRelocator) (*model.RestoreResult, error) {
+	result := &model.RestoreResult{}
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		r := &snapshotRestorer{
+			tx:            tx,
+			policy:        policy,
+			relocator:     relocator,
+			result:        result,
+			itemIds:       make(map[uint64]uint64),
+			tagIds:        make(map[uint64]uint64),
+			titIds:        make(map[uint64]uint64),
+			annotationIds: make(map[uint64]uint64),
+			restoredItems: make(map[uint64]bool),
+			restoredTags:  make(map[uint64]bool),
+		}
+
+		return r.restore(snapshot)
+	})
+
+	return result, d.handleError(err)
+}
+
+type snapshotRestorer struct {
+	tx            *gorm.DB
+	policy        model.ConflictPolicy
+	relocator     model.StorageRelocator
+	result        *model.RestoreResult
+	itemIds       map[uint64]uint64 // archived id to restored id
+	tagIds        map[uint64]uint64
+	titIds        map[uint64]uint64
+	annotationIds map[uint64]uint64
+	restoredItems map[uint64]bool // restored ids whose covers and watch progress come from the snapshot
+	restoredTags  map[uint64]bool // restored ids whose images and commands come from the snapshot
+}
+
+func (r *snapshotRestorer) restore(snapshot *model.Snapshot) error {
+	var count int64
+	if err := r.tx.Unscoped().Model(&model.Item{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	r.result.EmptyTarget = count == 0
+	if err := r.tx.Unscoped().Model(&model.Tag{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	r.result.EmptyTarget = r.result.EmptyTarget && count == 0
+	steps := []func(*model.Snapshot) error{
+		r.restoreTagImageTypes,
+		r.restoreTagAnnotations,
+		r.restoreTags,
+		r.restoreItems,
+		r.restoreTagItems,
+		r.restoreTagImages,
+		r.restoreTagsAnnotations,
+		r.restoreDirectories,
+		r.restoreTagCustomCommands,
+		r.restoreWatchProgress,
+		r.restoreTasks,
+	}
+
+	for _, step := range steps {
+		if err := step(snapshot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) overwrite() bool {
+	return r.policy == model.CONFLICT_OVERWRITE
+}
+
+// freeId returns the archived id when no row uses it, or 0 to let the database pick one
+func (r *snapshotRestorer) freeId(value any, id uint64) (uint64, error) {
+	var count int64
+	if err := r.tx.Unscoped().Model(value).Where("id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		return 0, nil
+	}
+
+	return id, nil
+}
+
+func (r *snapshotRestorer) restoreTagImageTypes(snapshot *model.Snapshot) error {
+	for _, tit := range snapshot.TagImageTypes {
+		oldId := tit.Id
+		existing := model.TagImageType{}
+		tx := r.tx.Where("nickname = ?", tit.Nickname).Limit(1).Find(&existing)
+		if tx.Error != nil {
+			return tx.Error
+		}
+
+		if tx.RowsAffected > 0 {
+			r.titIds[oldId] = existing.Id
+			if r.overwrite() {
+				url := r.relocator.RelocateTagImageTypeUrl(tit.IconUrl, oldId, existing.Id)
+				if err := r.tx.Model(&existing).Update("icon_url", url).Error; err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var err error
+		if tit.Id, err = r.freeId(&model.TagImageType{}, oldId); err != nil {
+			return err
+		}
+
+		if err := r.tx.Create(&tit).Error; err != nil {
+			return err
+		}
+
+		r.titIds[oldId] = tit.Id
+		if tit.Id != oldId {
+			url := r.relocator.RelocateTagImageTypeUrl(tit.IconUrl, oldId, tit.Id)
+			if err := r.tx.Model(&tit).Update("icon_url", url).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreTagAnnotations(snapshot *model.Snapshot) error {
+	for _, annotation := range snapshot.TagAnnotations {
+		oldId := annotation.Id
+		existing := model.TagAnnotation{}
+		tx := r.tx.Where("title = ?", annotation.Title).Limit(1).Find(&existing)
+		if tx.Error != nil {
+			return tx.Error
+		}
+
+		if tx.RowsAffected > 0 {
+			r.annotationIds[oldId] = existing.Id
+			continue
+		}
+
+		var err error
+		if annotation.Id, err = r.freeId(&model.TagAnnotation{}, oldId); err != nil {
+			return err
+		}
+
+		annotation.Tags = nil
+		if err := r.tx.Create(&annotation).Error; err != nil {
+			return err
+		}
+
+		r.annotationIds[oldId] = annotation.Id
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreTags(snapshot *model.Snapshot) error {
+	for _, tag := range sortTagsByDepth(snapshot.Tags) {
+		oldId := tag.Id
+		tag.ParentID = remapId(r.tagIds, tag.ParentID)
+		existing := model.Tag{}
+		query := r.tx.Unscoped().Where("title = ?", tag.Title)
+		if tag.ParentID == nil {
+			query = query.Where("parent_id is null")
+		} else {
+			query = query.Where("parent_id = ?", *tag.ParentID)
+		}
+
+		tx := query.Limit(1).Find(&existing)
+		if tx.Error != nil {
+			return tx.Error
+		}
+
+		if tx.RowsAffected > 0 {
+			r.tagIds[oldId] = existing.Id
+			if err := r.tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+
+			if !r.overwrite() {
+				r.result.Tags.Skipped++
+				continue
+			}
+
+			tag.Id = existing.Id
+			if err := r.tx.Model(&existing).Select("display_style", "default_sorting", "no_random", "smart_query").
+				Updates(&tag).Error; err != nil {
+				return err
+			}
+
+			r.restoredTags[existing.Id] = true
+			r.result.Tags.Updated++
+			continue
+		}
+
+		var err error
+		if tag.Id, err = r.freeId(&model.Tag{}, oldId); err != nil {
+			return err
+		}
+
+		if err := r.tx.Omit(clause.Associations).Create(&tag).Error; err != nil {
+			return err
+		}
+
+		r.tagIds[oldId] = tag.Id
+		r.restoredTags[tag.Id] = true
+		r.result.Tags.Created++
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreItems(snapshot *model.Snapshot) error {
+	// highlights and sub items reference their main item, which must be restored first
+	items := make([]model.Item, 0, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		if item.HighlightParentItemId == nil && item.MainItemId == nil {
+			items = append(items, item)
+		}
+	}
+	for _, item := range snapshot.Items {
+		if item.HighlightParentItemId != nil || item.MainItemId != nil {
+			items = append(items, item)
+		}
+	}
+
+	for _, item := range items {
+		if err := r.restoreItem(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreItem(item model.Item) error {
+	oldId := item.Id
+	covers := item.Covers
+	item.Covers = nil
+	if item.HighlightParentItemId != nil {
+		if item.HighlightParentItemId = remapId(r.itemIds, item.HighlightParentItemId); item.HighlightParentItemId == nil {
+			logger.Warningf("Skipping highlight %d, its parent is missing", oldId)
+			r.result.Items.Skipped++
+			return nil
+		}
+	}
+	if item.MainItemId != nil {
+		if item.MainItemId = remapId(r.itemIds, item.MainItemId); item.MainItemId == nil {
+			logger.Warningf("Skipping sub item %d, its main item is missing", oldId)
+			r.result.Items.Skipped++
+			return nil
+		}
+	}
+
+	existing := model.Item{}
+	tx := r.tx.Unscoped().Where("title = ? and origin = ?", item.Title, item.Origin).Limit(1).Find(&existing)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected > 0 {
+		r.itemIds[oldId] = existing.Id
+		if err := r.tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if !r.overwrite() {
+			r.result.Items.Skipped++
+			return nil
+		}
+
+		item.Id = existing.Id
+		r.relocateItemUrls(&item, oldId)
+		if err := r.tx.Model(&existing).Select("*").Omit("id", "deleted_at").Updates(&item).Error; err != nil {
+			return err
+		}
+
+		if err := r.tx.Where("item_id = ?", existing.Id).Delete(&model.Cover{}).Error; err != nil {
+			return err
+		}
+
+		r.result.Items.Updated++
+	} else {
+		var err error
+		if item.Id, err = r.freeId(&model.Item{}, oldId); err != nil {
+			return err
+		}
+
+		if err := r.tx.Omit(clause.Associations).Create(&item).Error; err != nil {
+			return err
+		}
+
+		if item.Id != oldId {
+			r.relocateItemUrls(&item, oldId)
+			if err := r.tx.Model(&item).Select("preview_url", "main_cover_url").Updates(&item).Error; err != nil {
+				return err
+			}
+		}
+
+		r.itemIds[oldId] = item.Id
+		r.result.Items.Created++
+	}
+
+	r.restoredItems[item.Id] = true
+	for _, cover := range covers {
+		cover.Id = 0
+		cover.ItemId = item.Id
+		cover.Url = r.relocator.RelocateItemUrl(cover.Url, oldId, item.Id)
+		if err := r.tx.Create(&cover).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) relocateItemUrls(item *model.Item, oldId uint64) {
+	item.PreviewUrl = r.relocator.RelocateItemUrl(item.PreviewUrl, oldId, item.Id)
+	if item.MainCoverUrl != nil {
+		url := r.relocator.RelocateItemUrl(*item.MainCoverUrl, oldId, item.Id)
+		item.MainCoverUrl = &url
+	}
+}
+
+func (r *snapshotRestorer) restoreTagItems(snapshot *model.Snapshot) error {
+	rows := make([]map[string]any, 0, len(snapshot.TagItems))
+	for _, tagItem := range snapshot.TagItems {
+		tagId, tagOk := r.tagIds[tagItem.TagId]
+		itemId, itemOk := r.itemIds[tagItem.ItemId]
+		if tagOk && itemOk {
+			rows = append(rows, map[string]any{"tag_id": tagId, "item_id": itemId})
+		}
+	}
+
+	return r.insertIgnore("tag_items", rows)
+}
+
+func (r *snapshotRestorer) restoreTagImages(snapshot *model.Snapshot) error {
+	if r.overwrite() {
+		if err := r.tx.Where("tag_id in ?", keys(r.restoredTags)).Delete(&model.TagImage{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, image := range snapshot.TagImages {
+		tagId, ok := r.tagIds[image.TagId]
+		if !ok || !r.restoredTags[tagId] {
+			continue
+		}
+
+		oldTagId := image.TagId
+		image.Id = 0
+		image.TagId = tagId
+		image.ImageTypeId = r.titIds[image.ImageTypeId]
+		image.Url = r.relocator.RelocateTagUrl(image.Url, oldTagId, tagId)
+		image.ThumbnailUrl = r.relocator.RelocateTagUrl(image.ThumbnailUrl, oldTagId, tagId)
+		if err := r.tx.Create(&image).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreTagsAnnotations(snapshot *model.Snapshot) error {
+	rows := make([]map[string]any, 0, len(snapshot.TagsAnnotations))
+	for _, link := range snapshot.TagsAnnotations {
+		tagId, tagOk := r.tagIds[link.TagId]
+		annotationId, annotationOk := r.annotationIds[link.TagAnnotationId]
+		if tagOk && annotationOk {
+			rows = append(rows, map[string]any{"tag_id": tagId, "tag_annotation_id": annotationId})
+		}
+	}
+
+	return r.insertIgnore("tags_annotations", rows)
+}
+
+func (r *snapshotRestorer) restoreDirectories(snapshot *model.Snapshot) error {
+	for _, dir := range snapshot.Directories {
+		dir.Tags = nil
+		var count int64
+		if err := r.tx.Model(&model.Directory{}).Where("path = ?", dir.Path).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			if err := r.tx.Omit(clause.Associations).Create(&dir).Error; err != nil {
+				return err
+			}
+			r.result.Directories.Created++
+			continue
+		}
+
+		if !r.overwrite() {
+			r.result.Directories.Skipped++
+			continue
+		}
+
+		if err := r.tx.Model(&model.Directory{Path: dir.Path}).Select("*").Omit("path").Updates(&dir).Error; err != nil {
+			return err
+		}
+		r.result.Directories.Updated++
+	}
+
+	rows := make([]map[string]any, 0, len(snapshot.DirectoryTags))
+	for _, dirTag := range snapshot.DirectoryTags {
+		if tagId, ok := r.tagIds[dirTag.TagId]; ok {
+			rows = append(rows, map[string]any{"directory_path": dirTag.DirectoryPath, "tag_id": tagId})
+		}
+	}
+
+	return r.insertIgnore("directory_tags", rows)
+}
+
+func (r *snapshotRestorer) restoreTagCustomCommands(snapshot *model.Snapshot) error {
+	if r.overwrite() {
+		if err := r.tx.Where("tag_id in ?", keys(r.restoredTags)).Delete(&model.TagCustomCommand{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, command := range snapshot.TagCustomCommands {
+		tagId, ok := r.tagIds[command.TagId]
+		if !ok || !r.restoredTags[tagId] {
+			continue
+		}
+
+		command.Id = 0
+		command.TagId = tagId
+		if err := r.tx.Create(&command).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) restoreWatchProgress(snapshot *model.Snapshot) error {
+	for _, progress := range snapshot.WatchProgress {
+		itemId, ok := r.itemIds[progress.ItemId]
+		if !ok || !r.restoredItems[itemId] {
+			continue
+		}
+
+		progress.ItemId = itemId
+		if err := r.tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&progress).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreTasks keeps the tasks of the restored items, the params of every task type hold the
+// item id
+func (r *snapshotRestorer) restoreTasks(snapshot *model.Snapshot) error {
+	if !r.result.EmptyTarget {
+		return nil
+	}
+
+	for _, task := range snapshot.Tasks {
+		params := make(map[string]json.RawMessage)
+		if err := json.Unmarshal([]byte(task.Params), &params); err != nil {
+			logger.Warningf("Skipping task %s with invalid params %s", task.Id, task.Params)
+			continue
+		}
+
+		if raw, ok := params["id"]; ok {
+			var oldId uint64
+			if err := json.Unmarshal(raw, &oldId); err != nil {
+				logger.Warningf("Skipping task %s with invalid item id %s", task.Id, raw)
+				continue
+			}
+
+			itemId, ok := r.itemIds[oldId]
+			if !ok {
+				continue
+			}
+
+			if itemId != oldId {
+				params["id"] = json.RawMessage(strconv.FormatUint(itemId, 10))
+				data, err := json.Marshal(params)
+				if err != nil {
+					return err
+				}
+				task.Params = string(data)
+			}
+		}
+
+		// interrupted tasks start over
+		task.ProcessingStart = nil
+		tx := r.tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&task)
+		if tx.Error != nil {
+			return tx.Error
+		}
+
+		r.result.Tasks += int(tx.RowsAffected)
+	}
+
+	return nil
+}
+
+func (r *snapshotRestorer) insertIgnore(table string, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return r.tx.Table(table).Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error
+}
+
+func remapId(ids map[uint64]uint64, id *uint64) *uint64 {
+	if id == nil {
+		return nil
+	}
+
+	newId, ok := ids[*id]
+	if !ok {
+		return nil
+	}
+
+	return &newId
+}
+
+func keys(set map[uint64]bool) []uint64 {
+	result := make([]uint64, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+
+	return result
+}
+
+// sortTagsByDepth orders the tags so parents come before their children, a tag whose parent
+// isn't in the snapshot is restored as a root
+func sortTagsByDepth(tags []model.Tag) []model.Tag {
+	parents := make(map[uint64]*uint64, len(tags))
+	for _, tag := range tags {
+		parents[tag.Id] = tag.ParentID
+	}
+
+	depth := func(tag model.Tag) int {
+		result := 0
+		for parent := tag.ParentID; parent != nil && result <= len(tags); result++ {
+			next, ok := parents[*parent]
+			if !ok {
+				break
+			}
+			parent = next
+		}
+		return result
+	}
+
+	sorted := make([]model.Tag, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool { return depth(sorted[i]) < depth(sorted[j]) })
+	return sorted
+}
@@ -10,7 +10,7 @@ func (d *databaseImpl) CreateTagAnnotation(ctx context.Context, tagAnnotation *m
 }
 
 func (d *databaseImpl) RemoveTag(ctx context.Context, tagId uint64) error {
-	return d.delete(ctx, model.Tag{Id: tagId})
+	return d.delete(ctx, &model.Tag{Id: tagId})
 }
 
 func (d *databaseImpl) RemoveTagAnnotationFromTag(ctx context.Context, tagId uint64, annotationId uint64) error {
@@ -7,6 +7,7 @@ import (
 	"github.com/go-errors/errors"
 	"github.com/mattn/go-sqlite3"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (d *databaseImpl) CreateOrUpdateTag(ctx context.Context, tag *model.Tag) error {
@@ -22,6 +23,9 @@ func (d *databaseImpl) CreateOrUpdateTag(ctx context.Context, tag *model.Tag) er
 		}
 
 		existing, err := d.GetTag(ctx, "title = ? and parent_id = ?", tag.Title, tag.ParentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			existing, err = d.reviveTag(ctx, "title = ? and parent_id = ?", tag.Title, tag.ParentID)
+		}
 
 		if err != nil {
 			return err
@@ -38,6 +42,80 @@ func (d *databaseImpl) UpdateTag(ctx context.Context, tag *model.Tag) error {
 	return d.update(ctx, tag)
 }
 
+// SetTagItems makes itemIds the exact items set of the tag, only the difference
+// from the current set is written. The kept ids go through a temp table, binding
+// all of them to a single statement would pass the sqlite variables limit.
+func (d *databaseImpl) SetTagItems(ctx context.Context, tagId uint64, itemIds []uint64) error {
+	return d.handleError(d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(itemIds) == 0 {
+			return tx.Where("tag_id = ?", tagId).Delete(&model.TagItem{}).Error
+		}
+
+		if err := tx.Exec("create temp table if not exists kept_tag_items (item_id integer primary key)").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("delete from temp.kept_tag_items").Error; err != nil {
+			return err
+		}
+
+		kept := make([]map[string]any, len(itemIds))
+		for i, itemId := range itemIds {
+			kept[i] = map[string]any{"item_id": itemId}
+		}
+
+		if err := tx.Table("temp.kept_tag_items").Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(kept, 500).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("tag_id = ? and item_id not in (select item_id from temp.kept_tag_items)", tagId).
+			Delete(&model.TagItem{}).Error; err != nil {
+			return err
+		}
+
+		rows := make([]model.TagItem, len(itemIds))
+		for i, itemId := range itemIds {
+			rows[i] = model.TagItem{TagId: tagId, ItemId: itemId}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("delete from temp.kept_tag_items").Error
+	}))
+}
+
+// UpdateItemsTags adds and removes tags of many items in a single transaction,
+// either all of the items are updated or none of them.
+func (d *databaseImpl) UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error {
+	if len(itemIds) == 0 {
+		return nil
+	}
+
+	return d.handleError(d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(removedTagIds) > 0 {
+			if err := tx.Where("item_id in ? and tag_id in ?", itemIds, removedTagIds).Delete(&model.TagItem{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(addedTagIds) == 0 {
+			return nil
+		}
+
+		rows := make([]model.TagItem, 0, len(itemIds)*len(addedTagIds))
+		for _, itemId := range itemIds {
+			for _, tagId := range addedTagIds {
+				rows = append(rows, model.TagItem{TagId: tagId, ItemId: itemId})
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500).Error
+	}))
+}
+
 func (d *databaseImpl) getTagModel(ctx context.Context, withChildren bool) *gorm.DB {
 	itemsPreloading := func(db *gorm.DB) *gorm.DB {
 		return db.Select("ID")
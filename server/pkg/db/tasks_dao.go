@@ -9,8 +9,9 @@ func (d *databaseImpl) CreateTask(ctx context.Context, task *model.Task) error {
 	return d.create(ctx, task)
 }
 
+// UpdateTask writes all the fields, a resumed or retried task clears its processing times
 func (d *databaseImpl) UpdateTask(ctx context.Context, task *model.Task) error {
-	return d.update(ctx, task)
+	return d.handleError(d.db.WithContext(ctx).Select("*").Updates(task).Error)
 }
 
 func (d *databaseImpl) RemoveTasks(ctx context.Context, conds ...interface{}) error {
@@ -28,3 +29,9 @@ func (d *databaseImpl) GetTasks(ctx context.Context, offset int, limit int) (*[]
 	err := d.handleError(d.db.WithContext(ctx).Model(model.Task{}).Offset(offset).Limit(limit).Find(&tasks).Error)
 	return &tasks, err
 }
+
+func (d *databaseImpl) FindTasks(ctx context.Context, conds ...interface{}) (*[]model.Task, error) {
+	var tasks []model.Task
+	err := d.handleError(d.db.WithContext(ctx).Model(model.Task{}).Order("enequeue_time").Find(&tasks, conds...).Error)
+	return &tasks, err
+}
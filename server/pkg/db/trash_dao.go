@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+// Items and tags are soft deleted, gorm hides rows with a deleted_at from every query that
+// isn't Unscoped. Their associations are kept so restoring brings back the tags, covers and
+// highlights, purging deletes them for good.
+
+const itemFamilyCondition = "id = ? or highlight_parent_item_id = ? or main_item_id = ?"
+
+func (d *databaseImpl) GetDeletedItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	var items []model.Item
+	err := d.handleError(d.getItemModel(ctx, false).Unscoped().
+		Where("deleted_at is not null").Order("deleted_at desc").Find(&items, conds...).Error)
+	return &items, err
+}
+
+func (d *databaseImpl) GetDeletedTags(ctx context.Context, conds ...interface{}) (*[]model.Tag, error) {
+	var tags []model.Tag
+	err := d.handleError(d.getTagModel(ctx, false).Unscoped().
+		Where("deleted_at is not null").Order("deleted_at desc").Find(&tags, conds...).Error)
+	return &tags, err
+}
+
+// RestoreItem takes the item out of the trash, with the highlights and sub items that were
+// removed along with it
+func (d *databaseImpl) RestoreItem(ctx context.Context, itemId uint64) error {
+	item := &model.Item{}
+	if err := d.db.WithContext(ctx).Unscoped().Select("id", "deleted_at").
+		First(item, "id = ? and deleted_at is not null", itemId).Error; err != nil {
+		return d.handleError(err)
+	}
+
+	return d.handleError(d.db.WithContext(ctx).Unscoped().Model(&model.Item{}).
+		Where(itemFamilyCondition, itemId, itemId, itemId).
+		Where("deleted_at = ?", item.DeletedAt).
+		Update("deleted_at", nil).Error)
+}
+
+func (d *databaseImpl) RestoreTag(ctx context.Context, tagId uint64) error {
+	tx := d.db.WithContext(ctx).Unscoped().Model(&model.Tag{}).
+		Where("id = ? and deleted_at is not null", tagId).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return d.handleError(tx.Error)
+	}
+
+	if tx.RowsAffected == 0 {
+		return d.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// PurgeItem deletes the item, its highlights and sub items and everything attached to them
+func (d *databaseImpl) PurgeItem(ctx context.Context, itemId uint64) error {
+	var ids []uint64
+	if err := d.db.WithContext(ctx).Unscoped().Model(&model.Item{}).
+		Where(itemFamilyCondition, itemId, itemId, itemId).Pluck("id", &ids).Error; err != nil {
+		return d.handleError(err)
+	}
+
+	for _, id := range ids {
+		if err := d.RemoveWatchProgress(ctx, id); err != nil {
+			return err
+		}
+
+		if err := d.RemoveVideoHash(ctx, id); err != nil {
+			return err
+		}
+
+		if err := d.db.WithContext(ctx).Unscoped().Select("Tags", "Covers").
+			Delete(&model.Item{Id: id}).Error; err != nil {
+			return d.handleError(err)
+		}
+	}
+
+	return nil
+}
+
+func (d *databaseImpl) PurgeTag(ctx context.Context, tagId uint64) error {
+	return d.handleError(d.db.WithContext(ctx).Unscoped().Select("Items", "Images", "Annotations").
+		Delete(&model.Tag{Id: tagId}).Error)
+}
+
+// reviveItem restores a trashed item matching the conditions, used when a new item collides
+// with a trashed one on the title and origin, for example when a removed file shows up again
+func (d *databaseImpl) reviveItem(ctx context.Context, query string, args ...any) (*model.Item, error) {
+	id, err := d.findDeletedId(ctx, &model.Item{}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.RestoreItem(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return d.GetItem(ctx, id)
+}
+
+// reviveTag reuses a trashed tag matching the conditions for a new tag with the same title and
+// parent, the new tag starts empty so the items, images and annotations of the trashed one are
+// deleted rather than restored
+func (d *databaseImpl) reviveTag(ctx context.Context, query string, args ...any) (*model.Tag, error) {
+	id, err := d.findDeletedId(ctx, &model.Tag{}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.handleError(d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&model.TagItem{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("tag_id = ?", id).Delete(&model.TagImage{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("delete from tags_annotations where tag_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&model.Tag{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})); err != nil {
+		return nil, err
+	}
+
+	return d.GetTag(ctx, id)
+}
+
+func (d *databaseImpl) findDeletedId(ctx context.Context, value any, query string, args ...any) (uint64, error) {
+	var ids []uint64
+	if err := d.db.WithContext(ctx).Unscoped().Model(value).Where(query, args...).
+		Where("deleted_at is not null").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, d.handleError(err)
+	}
+
+	if len(ids) == 0 {
+		return 0, d.handleError(gorm.ErrRecordNotFound)
+	}
+
+	return ids[0], nil
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+
+	"gorm.io/gorm/clause"
+)
+
+func (d *databaseImpl) SaveVideoHash(ctx context.Context, hash *model.VideoHash) error {
+	return d.handleError(d.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(hash).Error)
+}
+
+func (d *databaseImpl) RemoveVideoHash(ctx context.Context, itemId uint64) error {
+	return d.delete(ctx, model.VideoHash{}, "item_id = ?", itemId)
+}
+
+func (d *databaseImpl) GetVideoHashes(ctx context.Context) (*[]model.VideoHash, error) {
+	var hashes []model.VideoHash
+	err := d.handleError(d.db.WithContext(ctx).
+		Where("item_id in (select id from items where deleted_at is null)").Find(&hashes).Error)
+	return &hashes, err
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+
+	"gorm.io/gorm/clause"
+)
+
+func (d *databaseImpl) SaveWatchProgress(ctx context.Context, progress *model.WatchProgress) error {
+	return d.handleError(d.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(progress).Error)
+}
+
+func (d *databaseImpl) RemoveWatchProgress(ctx context.Context, itemId uint64) error {
+	return d.delete(ctx, model.WatchProgress{}, "item_id = ?", itemId)
+}
+
+func (d *databaseImpl) GetWatchProgress(ctx context.Context, itemId uint64) (*model.WatchProgress, error) {
+	progress := &model.WatchProgress{}
+	err := d.handleError(d.db.WithContext(ctx).
+		First(progress, "item_id = ? and item_id in (select id from items where deleted_at is null)", itemId).Error)
+	return progress, err
+}
+
+// GetWatchHistory returns the progress of main items, most recently watched first,
+// highlights and sub-items are left out as their progress is rolled up into the main item.
+func (d *databaseImpl) GetWatchHistory(ctx context.Context, onlyInProgress bool, offset int, limit int) (*[]model.WatchProgress, error) {
+	tx := d.db.WithContext(ctx).Model(&model.WatchProgress{}).
+		Where("item_id in (select id from items where highlight_parent_item_id is null and main_item_id is null and deleted_at is null)")
+	if onlyInProgress {
+		tx = tx.Where("completed = ? and position_seconds > 0", false)
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+
+	var history []model.WatchProgress
+	err := d.handleError(tx.Order("timestamp desc").Offset(offset).Find(&history).Error)
+	return &history, err
+}
@@ -18,12 +18,12 @@ func newDiff() *Diff {
 }
 
 type Diff struct {
-	AddedDirectories   []Change
-	RemovedDirectories []Change
-	AddedFiles         []Change
-	RemovedFiles       []Change
-	MovedDirectories   []Change
-	MovedFiles         []Change
+	AddedDirectories   []Change `json:"addedDirectories"`
+	RemovedDirectories []Change `json:"removedDirectories"`
+	AddedFiles         []Change `json:"addedFiles"`
+	RemovedFiles       []Change `json:"removedFiles"`
+	MovedDirectories   []Change `json:"movedDirectories"`
+	MovedFiles         []Change `json:"movedFiles"`
 }
 
 func (s *Diff) HasChanges() bool {
@@ -56,9 +56,9 @@ const (
 )
 
 type Change struct {
-	Path1      string
-	Path2      string
-	ChangeType ChangeType
+	Path1      string     `json:"path1"`
+	Path2      string     `json:"path2,omitempty"`
+	ChangeType ChangeType `json:"type"`
 }
 
 func (c *Change) String() string {
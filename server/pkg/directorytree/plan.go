@@ -0,0 +1,103 @@
+package directorytree
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan is a dry run of a sync, the changes that would be applied to the db without applying them
+type Plan struct {
+	Id              string     `json:"id"`
+	CreatedAt       int64      `json:"createdAt"`
+	Scopes          []string   `json:"scopes"`
+	Counts          PlanCounts `json:"counts"`
+	ItemsCount      int64      `json:"itemsCount"`
+	RemovalFraction float64    `json:"removalFraction"`
+	Held            bool       `json:"held"`
+	Diff            *Diff      `json:"diff"`
+	Stale           *Stale     `json:"stale"`
+}
+
+type PlanCounts struct {
+	AddedDirectories   int `json:"addedDirectories"`
+	RemovedDirectories int `json:"removedDirectories"`
+	AddedFiles         int `json:"addedFiles"`
+	RemovedFiles       int `json:"removedFiles"`
+	MovedDirectories   int `json:"movedDirectories"`
+	MovedFiles         int `json:"movedFiles"`
+	StaleDirectories   int `json:"staleDirectories"`
+	StaleFiles         int `json:"staleFiles"`
+	RemovedItems       int `json:"removedItems"`
+}
+
+// NewPlan summarizes the diff and stales, itemsCount is the number of items in the db and
+// used to calculate the fraction of items the plan removes
+func NewPlan(scopes []string, diff *Diff, stale *Stale, itemsCount int64) *Plan {
+	plan := &Plan{
+		Id:         uuid.NewString(),
+		CreatedAt:  time.Now().UnixMilli(),
+		Scopes:     scopes,
+		ItemsCount: itemsCount,
+		Diff:       diff,
+		Stale:      stale,
+		Counts: PlanCounts{
+			AddedDirectories:   len(diff.AddedDirectories),
+			RemovedDirectories: len(diff.RemovedDirectories),
+			AddedFiles:         len(diff.AddedFiles),
+			RemovedFiles:       len(diff.RemovedFiles),
+			MovedDirectories:   len(diff.MovedDirectories),
+			MovedFiles:         len(diff.MovedFiles),
+			StaleDirectories:   len(stale.Dirs),
+			StaleFiles:         len(stale.Files),
+			RemovedItems:       len(diff.RemovedFiles) + len(stale.Files),
+		},
+	}
+
+	if itemsCount > 0 {
+		plan.RemovalFraction = float64(plan.Counts.RemovedItems) / float64(itemsCount)
+	}
+
+	return plan
+}
+
+func (p *Plan) HasChanges() bool {
+	return p.Diff.HasChanges() || p.Stale.HasChanges()
+}
+
+// ExceedsRemovalFraction is true when the plan removes more than maxFraction of the items,
+// a non positive maxFraction never exceeds
+func (p *Plan) ExceedsRemovalFraction(maxFraction float64) bool {
+	return maxFraction > 0 && p.Counts.RemovedItems > 0 && p.RemovalFraction > maxFraction
+}
+
+// IsCoveredBy is true when every file and directory this plan removes is also removed by other,
+// applying it doesn't remove anything that wasn't confirmed in other
+func (p *Plan) IsCoveredBy(other *Plan) bool {
+	confirmed := other.removedPaths()
+	for path := range p.removedPaths() {
+		if !confirmed[path] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Plan) removedPaths() map[string]bool {
+	result := make(map[string]bool)
+	for _, change := range p.Diff.RemovedDirectories {
+		result[change.Path1] = true
+	}
+	for _, change := range p.Diff.RemovedFiles {
+		result[change.Path1] = true
+	}
+	for _, path := range p.Stale.Dirs {
+		result[path] = true
+	}
+	for _, path := range p.Stale.Files {
+		result[path] = true
+	}
+
+	return result
+}
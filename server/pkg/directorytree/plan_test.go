@@ -0,0 +1,56 @@
+package directorytree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	diff := newDiff()
+	diff.AddedFiles = append(diff.AddedFiles, Change{Path1: "a/new", ChangeType: FILE_ADDED})
+	diff.RemovedDirectories = append(diff.RemovedDirectories, Change{Path1: "b", ChangeType: DIRECTORY_REMOVED})
+	diff.RemovedFiles = append(diff.RemovedFiles,
+		Change{Path1: "b/1", ChangeType: FILE_REMOVED},
+		Change{Path1: "b/2", ChangeType: FILE_REMOVED})
+	stale := &Stale{Files: []string{"c/3"}}
+
+	plan := NewPlan([]string{""}, diff, stale, 10)
+	assert.NotEmpty(t, plan.Id)
+	assert.True(t, plan.HasChanges())
+	assert.Equal(t, 1, plan.Counts.AddedFiles)
+	assert.Equal(t, 1, plan.Counts.RemovedDirectories)
+	assert.Equal(t, 2, plan.Counts.RemovedFiles)
+	assert.Equal(t, 1, plan.Counts.StaleFiles)
+	assert.Equal(t, 3, plan.Counts.RemovedItems)
+	assert.InDelta(t, 0.3, plan.RemovalFraction, 0.0001)
+
+	assert.True(t, plan.ExceedsRemovalFraction(0.2))
+	assert.False(t, plan.ExceedsRemovalFraction(0.5))
+	assert.False(t, plan.ExceedsRemovalFraction(0))
+
+	raw, err := json.Marshal(plan)
+	assert.NoError(t, err)
+	var decoded Plan
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, plan.Counts, decoded.Counts)
+	assert.Equal(t, "b/1", decoded.Diff.RemovedFiles[0].Path1)
+	assert.Equal(t, []string{"c/3"}, decoded.Stale.Files)
+}
+
+func TestPlanIsCoveredBy(t *testing.T) {
+	confirmedDiff := newDiff()
+	confirmedDiff.RemovedFiles = append(confirmedDiff.RemovedFiles,
+		Change{Path1: "b/1", ChangeType: FILE_REMOVED},
+		Change{Path1: "b/2", ChangeType: FILE_REMOVED})
+	confirmed := NewPlan(nil, confirmedDiff, &Stale{}, 2)
+
+	partialDiff := newDiff()
+	partialDiff.RemovedFiles = append(partialDiff.RemovedFiles, Change{Path1: "b/1", ChangeType: FILE_REMOVED})
+	partialDiff.AddedFiles = append(partialDiff.AddedFiles, Change{Path1: "b/3", ChangeType: FILE_ADDED})
+	assert.True(t, NewPlan(nil, partialDiff, &Stale{}, 2).IsCoveredBy(confirmed))
+
+	assert.False(t, NewPlan(nil, newDiff(), &Stale{Files: []string{"c/3"}}, 2).IsCoveredBy(confirmed))
+	assert.True(t, NewPlan(nil, newDiff(), &Stale{}, 2).IsCoveredBy(confirmed))
+}
@@ -0,0 +1,134 @@
+package directorytree
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/directories"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// NormalizeScopes removes duplicated scopes and scopes nested in other scopes, scopes are
+// directories relative to the root, an empty scope is the root itself.
+func NormalizeScopes(scopes []string) []string {
+	cleaned := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if scope == model.ROOT_DIRECTORY_PATH || scope == "." {
+			scope = ""
+		}
+		cleaned = append(cleaned, strings.Trim(filepath.Clean("/"+scope), "/"))
+	}
+
+	sort.Strings(cleaned)
+	result := make([]string, 0, len(cleaned))
+	for _, scope := range cleaned {
+		if IsInAnyScope(scope, result) {
+			continue
+		}
+
+		result = append(result, scope)
+	}
+
+	return result
+}
+
+func isInScope(path string, scope string) bool {
+	return scope == "" || path == scope || strings.HasPrefix(path, scope+string(os.PathSeparator))
+}
+
+// IsInAnyScope tells whether the path is one of the scopes or inside one of them
+func IsInAnyScope(path string, scopes []string) bool {
+	for _, scope := range scopes {
+		if isInScope(path, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAncestorOfAnyScope(path string, scopes []string) bool {
+	for _, scope := range scopes {
+		if path == "" || strings.HasPrefix(scope, path+string(os.PathSeparator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isFullScope(scopes []string) bool {
+	return len(scopes) == 0 || (len(scopes) == 1 && scopes[0] == "")
+}
+
+// BuildFromPathScoped builds a tree of the given root that only contains the scopes and the
+// chain of their parents, comparing it with a tree built by BuildFromDbScoped with the same
+// scopes only reports changes inside the scopes.
+func BuildFromPathScoped(path string, scopes []string, filter FilesFilter) (*DirectoryNode, error) {
+	scopes = NormalizeScopes(scopes)
+	if isFullScope(scopes) {
+		return BuildFromPath(path, filter)
+	}
+
+	root := createDirectoryNode(nil, "")
+	for _, scope := range scopes {
+		parent := root.getOrCreateChild(parentPath(scope))
+		node, err := buildFromDir(parent, filepath.Join(path, scope), filter)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// the scope was removed, it's missing from the tree like any removed directory
+				continue
+			}
+			return nil, err
+		}
+
+		parent.Children = append(parent.Children, node)
+	}
+
+	return root, nil
+}
+
+func BuildFromDbScoped(ctx context.Context, dr model.DirectoryReader, dig model.DirectoryItemsGetter, scopes []string) (*DirectoryNode, error) {
+	scopes = NormalizeScopes(scopes)
+	if isFullScope(scopes) {
+		return BuildFromDb(ctx, dr, dig)
+	}
+
+	dirs, err := dr.GetAllDirectories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root := createDirectoryNode(nil, "")
+	for _, dir := range *dirs {
+		path := dir.Path
+		if path == model.ROOT_DIRECTORY_PATH {
+			path = ""
+		}
+
+		if IsInAnyScope(path, scopes) {
+			child := root.getOrCreateChild(path)
+			child.Excluded = directories.IsExcluded(&dir)
+			if err := child.readFilesFromDb(ctx, dig); err != nil {
+				logger.Errorf("Error reading files from db %s", err)
+			}
+		} else if isAncestorOfAnyScope(path, scopes) {
+			root.getOrCreateChild(path).Excluded = directories.IsExcluded(&dir)
+		}
+	}
+
+	return root, nil
+}
+
+func parentPath(path string) string {
+	parent := filepath.Dir(path)
+	if parent == "." {
+		return ""
+	}
+
+	return parent
+}
@@ -0,0 +1,58 @@
+package directorytree
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	assert.Equal(t, []string{"1/2", "a"}, NormalizeScopes([]string{"a", "1/2/3", "1/2", "a/", "a/b"}))
+	assert.Equal(t, []string{""}, NormalizeScopes([]string{"1/2", model.ROOT_DIRECTORY_PATH}))
+	assert.Equal(t, []string{"1/2", "1/2.1/3"}, NormalizeScopes([]string{"1/2.1/3", "1/2", "1/2/3"}))
+}
+
+func TestCompareScoped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rootDir, err := os.MkdirTemp("", "mc-build-scoped-*")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+	buildTestFs(t, rootDir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "out-of-scope"), 0755))
+	_, err = os.Create(filepath.Join(rootDir, "1", "2", "3", "4", "new-file"))
+	assert.NoError(t, err)
+
+	dig := model.NewMockDirectoryItemsGetter(ctrl)
+	dig.EXPECT().GetBelongingItems(context.Background(), "1/2/3/4").Return(&[]model.Item{
+		{Title: "file4-1"},
+		{Title: "file4-2"},
+		{Title: "file4-3"},
+	}, nil)
+
+	dbRoot, err := BuildFromDbScoped(context.Background(), buildTestDirectoryReader(ctrl), dig, []string{"1/2/3"})
+	assert.NoError(t, err)
+	fsRoot, err := BuildFromPathScoped(rootDir, []string{"1/2/3"}, testFileFilter{})
+	assert.NoError(t, err)
+
+	diff := Compare(fsRoot, dbRoot)
+	assert.Equal(t, 1, diff.ChangesTotal())
+	assert.Equal(t, []Change{{Path1: "1/2/3/4/new-file", ChangeType: FILE_ADDED}}, diff.AddedFiles)
+}
+
+func TestBuildFromPathScoped_RemovedScope(t *testing.T) {
+	rootDir, err := os.MkdirTemp("", "mc-build-scoped-*")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+	buildTestFs(t, rootDir)
+
+	root, err := BuildFromPathScoped(rootDir, []string{"1/missing"}, testFileFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(root.getOrCreateChild("1").Children))
+}
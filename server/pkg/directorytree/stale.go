@@ -6,8 +6,8 @@ import (
 )
 
 type Stale struct {
-	Dirs  []string
-	Files []string
+	Dirs  []string `json:"dirs"`
+	Files []string `json:"files"`
 }
 
 func (s *Stale) HasChanges() bool {
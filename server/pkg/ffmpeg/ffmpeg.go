@@ -2,6 +2,7 @@ package ffmpeg
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"my-collection/server/pkg/model"
@@ -9,6 +10,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-errors/errors"
 	"github.com/op/go-logging"
@@ -35,10 +37,15 @@ type FfprobeShowStreamOutput struct {
 	Height    int    `json:"height"`
 }
 
-func execute(name string, arg ...string) ([]byte, error) {
+// the process is killed when the context is done, the output pipes are closed after this delay
+// in case it left children holding them
+const killWaitDelay = 5 * time.Second
+
+func execute(ctx context.Context, name string, arg ...string) ([]byte, error) {
 	logger.Debugf("Running %s \"%s\"", name, strings.Join(arg, "\" \""))
 
-	cmd := exec.Command(name, arg...)
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.WaitDelay = killWaitDelay
 	var stderr bytes.Buffer
 	var stdout bytes.Buffer
 	cmd.Stderr = &stderr
@@ -49,6 +56,10 @@ func execute(name string, arg ...string) ([]byte, error) {
 	}
 
 	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.WrapPrefix(ctx.Err(), fmt.Sprintf("%s killed", name), 0)
+		}
+
 		return nil, errors.Errorf("Error running process, exit code: %d, err: %s %v",
 			cmd.ProcessState.ExitCode(), stderr.String(), err)
 	}
@@ -60,8 +71,8 @@ func execute(name string, arg ...string) ([]byte, error) {
 	return stdout.Bytes(), nil
 }
 
-func GetDurationInSeconds(videoFile string) (float64, error) {
-	output, err := execute("ffprobe", videoFile, "-show_format", "-v", "quiet", "-print_format", "json")
+func GetDurationInSeconds(ctx context.Context, videoFile string) (float64, error) {
+	output, err := execute(ctx, "ffprobe", videoFile, "-show_format", "-v", "quiet", "-print_format", "json")
 	if err != nil {
 		return 0, err
 	}
@@ -79,8 +90,8 @@ func GetDurationInSeconds(videoFile string) (float64, error) {
 	return durationInSeconds, nil
 }
 
-func GetVideoMetadata(videoFile string) (FfprobeShowStreamOutput, error) {
-	output, err := execute("ffprobe", "-show_streams", "-print_format", "json", videoFile)
+func GetVideoMetadata(ctx context.Context, videoFile string) (FfprobeShowStreamOutput, error) {
+	output, err := execute(ctx, "ffprobe", "-show_streams", "-print_format", "json", videoFile)
 	if err != nil {
 		return FfprobeShowStreamOutput{}, err
 	}
@@ -99,8 +110,8 @@ func GetVideoMetadata(videoFile string) (FfprobeShowStreamOutput, error) {
 	return FfprobeShowStreamOutput{}, errors.Errorf("Video stream not found for %s", videoFile)
 }
 
-func GetAudioMetadata(videoFile string) (FfprobeShowStreamOutput, error) {
-	output, err := execute("ffprobe", "-show_streams", "-print_format", "json", videoFile)
+func GetAudioMetadata(ctx context.Context, videoFile string) (FfprobeShowStreamOutput, error) {
+	output, err := execute(ctx, "ffprobe", "-show_streams", "-print_format", "json", videoFile)
 	if err != nil {
 		return FfprobeShowStreamOutput{}, err
 	}
@@ -119,8 +130,8 @@ func GetAudioMetadata(videoFile string) (FfprobeShowStreamOutput, error) {
 	return FfprobeShowStreamOutput{}, errors.Errorf("Audio stream not found for %s", videoFile)
 }
 
-func TakeScreenshot(videoFile string, second float64, targetFile string) error {
-	_, err := execute("ffmpeg", "-y", "-ss", fmt.Sprintf("%f", second), "-i", videoFile, "-vframes", "1", targetFile)
+func TakeScreenshot(ctx context.Context, videoFile string, second float64, targetFile string) error {
+	_, err := execute(ctx, "ffmpeg", "-y", "-ss", fmt.Sprintf("%f", second), "-i", videoFile, "-vframes", "1", targetFile)
 	if err != nil {
 		return err
 	}
@@ -128,17 +139,18 @@ func TakeScreenshot(videoFile string, second float64, targetFile string) error {
 	return nil
 }
 
-func CropScreenshot(videoFile string, second float64, rect model.RectFloat, targetFile string) error {
+func CropScreenshot(ctx context.Context, videoFile string, second float64, rect model.RectFloat, targetFile string) error {
 	cropFilter := fmt.Sprintf("crop=%f:%f:%f:%f", rect.W, rect.H, rect.X, rect.Y)
-	_, err := execute("ffmpeg", "-y", "-ss", fmt.Sprintf("%f", second), "-i", videoFile, "-vf", cropFilter, "-vframes", "1", targetFile)
+	_, err := execute(ctx, "ffmpeg", "-y", "-ss", fmt.Sprintf("%f", second), "-i", videoFile, "-vf", cropFilter, "-vframes", "1", targetFile)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
-func ExtractPartOfVideo(videoFile string, second float64, duration int, targetFile string) error {
-	_, err := execute("ffmpeg", "-ss", fmt.Sprintf("%f", second), "-i", videoFile,
+
+func ExtractPartOfVideo(ctx context.Context, videoFile string, second float64, duration int, targetFile string) error {
+	_, err := execute(ctx, "ffmpeg", "-ss", fmt.Sprintf("%f", second), "-i", videoFile,
 		"-t", fmt.Sprintf("%d", duration), "-vcodec", "copy", "-acodec", "aac", "-ac", "4", targetFile)
 	if err != nil {
 		return err
@@ -147,7 +159,7 @@ func ExtractPartOfVideo(videoFile string, second float64, duration int, targetFi
 	return nil
 }
 
-func JoinVideoFiles(videoFiles []string, targetFile string) error {
+func JoinVideoFiles(ctx context.Context, videoFiles []string, targetFile string) error {
 	tempFile, err := os.CreateTemp("", "my-collection-join-video-files-*.txt")
 	if err != nil {
 		return err
@@ -163,28 +175,31 @@ func JoinVideoFiles(videoFiles []string, targetFile string) error {
 		return err
 	}
 
-	_, err = execute("ffmpeg", "-y", "-safe", "0", "-f", "concat", "-i", tempFile.Name(), targetFile)
+	_, err = execute(ctx, "ffmpeg", "-y", "-safe", "0", "-f", "concat", "-i", tempFile.Name(), targetFile)
 	if err != nil {
+		os.Remove(targetFile)
 		return err
 	}
 
 	return nil
 }
 
-func OptimizeVideoForPreview(videoFile string, tempFile string) error {
-	_, err := execute("ffmpeg", "-y", "-i", videoFile, "-b:v", "2M", "-an", tempFile)
+func OptimizeVideoForPreview(ctx context.Context, videoFile string, tempFile string) error {
+	_, err := execute(ctx, "ffmpeg", "-y", "-i", videoFile, "-b:v", "2M", "-an", tempFile)
 	if err != nil {
+		os.Remove(tempFile)
 		return err
 	}
 
 	return os.Rename(tempFile, videoFile)
 }
 
-func ChangeVideoResolution(videoFile string, tempFile string, w int, h int) error {
+func ChangeVideoResolution(ctx context.Context, videoFile string, tempFile string, w int, h int) error {
 	logger.Infof("Changing video resolution for %s to %d:%d", videoFile, w, h)
 
-	_, err := execute("ffmpeg", "-i", videoFile, "-vf", fmt.Sprintf("scale=%d:%d", w, h), "-c:a", "copy", tempFile)
+	_, err := execute(ctx, "ffmpeg", "-i", videoFile, "-vf", fmt.Sprintf("scale=%d:%d", w, h), "-c:a", "copy", tempFile)
 	if err != nil {
+		os.Remove(tempFile)
 		return err
 	}
 
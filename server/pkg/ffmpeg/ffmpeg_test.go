@@ -1,8 +1,10 @@
 package ffmpeg
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,17 +14,27 @@ var sampleMp4 = filepath.Join(testFiles, "sample.mp4")
 var sample3SecondsScreenshotPng = filepath.Join(testFiles, "sample-3-second-screenshot.png")
 
 func TestGetDuration(t *testing.T) {
-	duration, err := GetDurationInSeconds(filepath.Join(testFiles, "sample.mp4"))
+	duration, err := GetDurationInSeconds(context.Background(), filepath.Join(testFiles, "sample.mp4"))
 	assert.NoError(t, err)
 	assert.Equal(t, duration, 5.568)
 }
 
 func TestGetDurationOfMissingFile(t *testing.T) {
-	_, err := GetDurationInSeconds("missing.mp4")
+	_, err := GetDurationInSeconds(context.Background(), "missing.mp4")
 	assert.Error(t, err)
 }
 
 func TestTakeScreenshot(t *testing.T) {
-	err := TakeScreenshot(sampleMp4, 3, sample3SecondsScreenshotPng)
+	err := TakeScreenshot(context.Background(), sampleMp4, 3, sample3SecondsScreenshotPng)
 	assert.NoError(t, err)
 }
+
+func TestExecuteKilledOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := execute(ctx, "sleep", "10")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
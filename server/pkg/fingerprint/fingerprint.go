@@ -0,0 +1,45 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-errors/errors"
+)
+
+const chunkSize = 64 * 1024
+
+// File returns a fast identity of the file content, the size and a hash of a chunk from the
+// head, the middle and the tail of the file. Small files are hashed entirely. The fingerprint
+// doesn't depend on the file name or location, so it survives renames and moves.
+func File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+
+	size := stat.Size()
+	hash := sha256.New()
+	if size <= 3*chunkSize {
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", errors.Wrap(err, 0)
+		}
+	} else {
+		for _, offset := range []int64{0, size/2 - chunkSize/2, size - chunkSize} {
+			if _, err := io.Copy(hash, io.NewSectionReader(file, offset, chunkSize)); err != nil {
+				return "", errors.Wrap(err, 0)
+			}
+		}
+	}
+
+	return fmt.Sprintf("%d-%s", size, hex.EncodeToString(hash.Sum(nil))[:32]), nil
+}
@@ -0,0 +1,46 @@
+package fingerprint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path string, content []byte) {
+	assert.NoError(t, os.WriteFile(path, content, 0644))
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	content := make([]byte, 4*chunkSize)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	writeFile(t, filepath.Join(dir, "original.mp4"), content)
+	writeFile(t, filepath.Join(dir, "renamed.mkv"), content)
+
+	changed := append([]byte{}, content...)
+	changed[len(changed)-1]++
+	writeFile(t, filepath.Join(dir, "changed.mp4"), changed)
+
+	writeFile(t, filepath.Join(dir, "small.mp4"), []byte("small"))
+
+	original, err := File(filepath.Join(dir, "original.mp4"))
+	assert.NoError(t, err)
+	renamed, err := File(filepath.Join(dir, "renamed.mkv"))
+	assert.NoError(t, err)
+	other, err := File(filepath.Join(dir, "changed.mp4"))
+	assert.NoError(t, err)
+	small, err := File(filepath.Join(dir, "small.mp4"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, original, renamed)
+	assert.NotEqual(t, original, other)
+	assert.Regexp(t, "^5-[0-9a-f]{32}$", small)
+
+	_, err = File(filepath.Join(dir, "missing.mp4"))
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package fssync
 
 import (
 	"context"
+	"fmt"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/bl/tags"
 	"my-collection/server/pkg/db"
@@ -10,6 +11,8 @@ import (
 	"my-collection/server/pkg/relativasor"
 	"my-collection/server/pkg/utils"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-errors/errors"
@@ -18,25 +21,43 @@ import (
 
 var logger = logging.MustGetLogger("fsmanager")
 
-func NewFsManager(ctx context.Context, db db.Database, filesFilter directorytree.FilesFilter, checkInterval time.Duration) (*FsManager, error) {
+const watchDebounce = 2 * time.Second
+
+// NewFsManager creates a manager that fully syncs the root directory every checkInterval,
+// when watch is set, changes are also picked up as they happen and synced incrementally.
+// Syncs that would remove more than holdFraction of the items are held until confirmed,
+// a non positive holdFraction applies every sync. New items are filled by the metadataImporter,
+// when not nil.
+func NewFsManager(ctx context.Context, db db.Database, filesFilter directorytree.FilesFilter,
+	checkInterval time.Duration, watch bool, holdFraction float64,
+	metadataImporter model.ItemMetadataImporter) (*FsManager, error) {
 	if err := directories.AddRootDirectory(ctx, db); err != nil {
 		return nil, err
 	}
 
 	return &FsManager{
-		filesFilter:   filesFilter,
-		checkInterval: checkInterval,
-		db:            db,
-		changeChannel: make(chan bool),
+		filesFilter:      filesFilter,
+		checkInterval:    checkInterval,
+		watch:            watch,
+		holdFraction:     holdFraction,
+		metadataImporter: metadataImporter,
+		db:               db,
+		changeChannel:    make(chan bool),
 	}, nil
 }
 
 type FsManager struct {
 	utils.PushSender
-	filesFilter   directorytree.FilesFilter
-	checkInterval time.Duration
-	db            db.Database
-	changeChannel chan bool
+	filesFilter      directorytree.FilesFilter
+	checkInterval    time.Duration
+	watch            bool
+	holdFraction     float64
+	metadataImporter model.ItemMetadataImporter
+	syncLock         sync.Mutex
+	planLock         sync.Mutex
+	heldPlan         *directorytree.Plan
+	db               db.Database
+	changeChannel    chan bool
 }
 
 func (f *FsManager) Watch(ctx context.Context) error {
@@ -46,15 +67,26 @@ func (f *FsManager) Watch(ctx context.Context) error {
 		utils.LogError("Error in FS Watch", err)
 	}
 
+	watcher, changes := f.startWatcher(ctx)
+	ticker := time.NewTicker(f.checkInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-f.changeChannel:
 			if err := f.Sync(ctx); err != nil {
 				utils.LogError("Error in FS Watch", err)
 			}
+			if watcher != nil {
+				utils.LogError("Error refreshing FS watcher", watcher.refresh(ctx, f.db))
+			}
+		case scopes := <-changes:
+			if err := f.SyncDirectories(ctx, scopes); err != nil {
+				utils.LogError("Error in FS Watch", err)
+			}
 		case <-ctx.Done():
 			return nil
-		case <-time.After(f.checkInterval):
+		case <-ticker.C:
 			if err := f.Sync(ctx); err != nil {
 				utils.LogError("Error in FS Watch", err)
 			}
@@ -62,6 +94,29 @@ func (f *FsManager) Watch(ctx context.Context) error {
 	}
 }
 
+// startWatcher returns a nil watcher when watching is disabled or unavailable, the periodic
+// full sync keeps working either way
+func (f *FsManager) startWatcher(ctx context.Context) (*fsWatcher, chan []string) {
+	changes := make(chan []string)
+	if !f.watch {
+		return nil, changes
+	}
+
+	watcher, err := newFsWatcher(relativasor.GetRootDirectory(), watchDebounce)
+	if err != nil {
+		utils.LogError("Error creating FS watcher, falling back to periodic sync", err)
+		return nil, changes
+	}
+
+	if err := watcher.refresh(ctx, f.db); err != nil {
+		utils.LogError("Error initializing FS watcher, falling back to periodic sync", err)
+		return nil, changes
+	}
+
+	go watcher.run(ctx, changes)
+	return watcher, changes
+}
+
 func (f *FsManager) DirectoryChanged() {
 	select {
 	case f.changeChannel <- true:
@@ -78,6 +133,12 @@ func (f *FsManager) GetBelongingItem(ctx context.Context, path string, filename
 }
 
 func (f *FsManager) AddBelongingItem(ctx context.Context, item *model.Item) error {
+	if f.metadataImporter != nil {
+		if err := f.metadataImporter.ImportItemMetadata(ctx, item); err != nil {
+			utils.LogError(fmt.Sprintf("Error importing metadata of %s", item.Url), err)
+		}
+	}
+
 	return newFsDirectory(item.Origin).addItem(ctx, f.db, f.db, item)
 }
 
@@ -99,16 +160,38 @@ func (f *FsManager) GetFileMetadata(path string) (int64, int64, error) {
 	return file.ModTime().UnixMilli(), file.Size(), nil
 }
 
-func (f *FsManager) runSync(ctx context.Context) (bool, error) {
-	dig, err := NewCachedDig(ctx, f.db, f.db)
+func (f *FsManager) newSyncer(ctx context.Context, scopes []string) (*fsSyncer, error) {
+	var dig model.DirectoryItemsGetter = f
+	if directorytree.IsInAnyScope("", scopes) {
+		cachedDig, err := NewCachedDig(ctx, f.db, f.db)
+		if err != nil {
+			return nil, err
+		}
+		dig = cachedDig
+	}
+
+	return newScopedFsSyncer(ctx, relativasor.GetRootDirectory(), scopes, f.db, dig, f.filesFilter)
+}
+
+// runSync applies the changes in the scopes, unless they remove too many items and weren't
+// confirmed by the user, in which case the plan is held and ErrSyncHeld is returned
+func (f *FsManager) runSync(ctx context.Context, scopes []string, confirmed *directorytree.Plan) (bool, error) {
+	fsSync, err := f.newSyncer(ctx, scopes)
 	if err != nil {
 		return false, err
 	}
-	fsSync, err := newFsSyncer(ctx, relativasor.GetRootDirectory(), f.db, dig, f.filesFilter)
+
+	plan, err := f.buildPlan(ctx, scopes, fsSync)
 	if err != nil {
 		return false, err
 	}
 
+	if plan.Held && (confirmed == nil || !plan.IsCoveredBy(confirmed)) {
+		f.holdPlan(plan)
+		return false, ErrSyncHeld
+	}
+
+	f.releasePlans(scopes)
 	hasChanges, errors := fsSync.sync(ctx, f.db, f, f, f)
 
 	if len(errors) > 0 {
@@ -124,21 +207,54 @@ func (f *FsManager) runSync(ctx context.Context) (bool, error) {
 }
 
 func (f *FsManager) Sync(ctx context.Context) error {
+	return f.syncScopes(ctx, []string{""}, nil)
+}
+
+// SyncDirectories only syncs the given directories and their sub directories, paths are
+// relative to the root directory. Directories that weren't modified since their last sync
+// are skipped.
+func (f *FsManager) SyncDirectories(ctx context.Context, paths []string) error {
+	scopes, err := changedScopes(ctx, f.db, directorytree.NormalizeScopes(paths))
+	if err != nil {
+		return err
+	}
+
+	if len(scopes) == 0 {
+		logger.Debugf("Directories [%s] unchanged since last sync", strings.Join(paths, ", "))
+		return nil
+	}
+
+	logger.Debugf("Syncing directories [%s]", strings.Join(scopes, ", "))
+	return f.syncScopes(ctx, scopes, nil)
+}
+
+func (f *FsManager) syncScopes(ctx context.Context, scopes []string, confirmed *directorytree.Plan) error {
+	f.syncLock.Lock()
+	defer f.syncLock.Unlock()
+
 	var lastError error
 	hasAnyChange := false
+	syncStart := time.Now().UnixMilli()
 
 	hasChanges := true
 	for hasChanges {
 		var err error
-		hasChanges, err = f.runSync(ctx)
+		hasChanges, err = f.runSync(ctx, scopes, confirmed)
 		if err != nil {
 			lastError = err
 		}
+		if errors.Is(err, ErrSyncHeld) {
+			break
+		}
 		if hasChanges {
 			hasAnyChange = hasChanges
 		}
 	}
 
+	if lastError == nil {
+		lastError = markSynced(ctx, f.db, scopes, syncStart)
+	}
+
 	if hasAnyChange {
 		f.Push(model.PushMessage{MessageType: model.PUSH_FS_CHANGE, Payload: ""})
 	}
@@ -0,0 +1,111 @@
+package fssync
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/model"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+)
+
+// ErrSyncHeld is returned when a sync removes too many items, the plan is kept
+// until it's applied or discarded
+var ErrSyncHeld = errors.Errorf("sync plan held, it removes too many items")
+
+var ErrPlanNotFound = fmt.Errorf("sync plan not found: %w", gorm.ErrRecordNotFound)
+
+func (f *FsManager) buildPlan(ctx context.Context, scopes []string, fsSync *fsSyncer) (*directorytree.Plan, error) {
+	itemsCount, err := f.db.GetItemsCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := fsSync.plan(scopes, itemsCount)
+	plan.Held = plan.ExceedsRemovalFraction(f.holdFraction)
+	return plan, nil
+}
+
+// PlanSync is a dry run of syncing the given directories, relative to the root directory,
+// no paths means the whole tree
+func (f *FsManager) PlanSync(ctx context.Context, paths []string) (*directorytree.Plan, error) {
+	scopes := directorytree.NormalizeScopes(paths)
+	if len(scopes) == 0 {
+		scopes = []string{""}
+	}
+
+	f.syncLock.Lock()
+	defer f.syncLock.Unlock()
+
+	fsSync, err := f.newSyncer(ctx, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.buildPlan(ctx, scopes, fsSync)
+}
+
+func (f *FsManager) HeldPlan() *directorytree.Plan {
+	f.planLock.Lock()
+	defer f.planLock.Unlock()
+	return f.heldPlan
+}
+
+// ApplyPlan applies the held plan with the given id. The fs is synced again, if it now removes
+// anything the held plan didn't, the new plan is held instead and ErrSyncHeld is returned.
+func (f *FsManager) ApplyPlan(ctx context.Context, id string) error {
+	plan := f.HeldPlan()
+	if plan == nil || plan.Id != id {
+		return ErrPlanNotFound
+	}
+
+	logger.Infof("Applying held sync plan %s, removing %d items", plan.Id, plan.Counts.RemovedItems)
+	return f.syncScopes(ctx, plan.Scopes, plan)
+}
+
+func (f *FsManager) DiscardPlan(id string) error {
+	f.planLock.Lock()
+	defer f.planLock.Unlock()
+
+	if f.heldPlan == nil || f.heldPlan.Id != id {
+		return ErrPlanNotFound
+	}
+
+	logger.Infof("Discarding held sync plan %s", id)
+	f.heldPlan = nil
+	return nil
+}
+
+func (f *FsManager) holdPlan(plan *directorytree.Plan) {
+	f.planLock.Lock()
+	defer f.planLock.Unlock()
+
+	if f.heldPlan != nil && plan.IsCoveredBy(f.heldPlan) && f.heldPlan.IsCoveredBy(plan) {
+		// same removals as the held plan, keep its id so a pending confirmation stays valid
+		return
+	}
+
+	logger.Warningf("Holding sync plan %s, it removes %d of %d items",
+		plan.Id, plan.Counts.RemovedItems, plan.ItemsCount)
+	f.heldPlan = plan
+	f.Push(model.PushMessage{MessageType: model.PUSH_FS_SYNC_HELD, Payload: plan})
+}
+
+// releasePlans drops the held plan once a sync of its scopes went through
+func (f *FsManager) releasePlans(scopes []string) {
+	f.planLock.Lock()
+	defer f.planLock.Unlock()
+
+	if f.heldPlan == nil {
+		return
+	}
+
+	for _, scope := range f.heldPlan.Scopes {
+		if !directorytree.IsInAnyScope(scope, scopes) {
+			return
+		}
+	}
+
+	f.heldPlan = nil
+}
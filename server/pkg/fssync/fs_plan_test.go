@@ -0,0 +1,52 @@
+package fssync
+
+import (
+	"context"
+	"my-collection/server/pkg/directorytree"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func removingPlan(scopes []string, files ...string) *directorytree.Plan {
+	diff := &directorytree.Diff{}
+	for _, file := range files {
+		diff.RemovedFiles = append(diff.RemovedFiles, directorytree.Change{Path1: file, ChangeType: directorytree.FILE_REMOVED})
+	}
+
+	return directorytree.NewPlan(scopes, diff, &directorytree.Stale{}, int64(len(files)))
+}
+
+func TestHoldPlan(t *testing.T) {
+	f := &FsManager{}
+	plan := removingPlan([]string{""}, "a/1", "a/2")
+	f.holdPlan(plan)
+	assert.Equal(t, plan, f.HeldPlan())
+
+	// same removals keep the held plan and its id
+	f.holdPlan(removingPlan([]string{""}, "a/1", "a/2"))
+	assert.Equal(t, plan.Id, f.HeldPlan().Id)
+
+	other := removingPlan([]string{""}, "a/1", "a/2", "a/3")
+	f.holdPlan(other)
+	assert.Equal(t, other.Id, f.HeldPlan().Id)
+
+	assert.ErrorIs(t, f.DiscardPlan(plan.Id), ErrPlanNotFound)
+	assert.ErrorIs(t, f.ApplyPlan(context.Background(), plan.Id), ErrPlanNotFound)
+	assert.NoError(t, f.DiscardPlan(other.Id))
+	assert.Nil(t, f.HeldPlan())
+}
+
+func TestReleasePlans(t *testing.T) {
+	f := &FsManager{}
+	f.holdPlan(removingPlan([]string{"a", "b"}, "a/1", "b/1"))
+
+	f.releasePlans([]string{"a"})
+	assert.NotNil(t, f.HeldPlan())
+
+	f.releasePlans([]string{"a", "b/c"})
+	assert.NotNil(t, f.HeldPlan())
+
+	f.releasePlans([]string{""})
+	assert.Nil(t, f.HeldPlan())
+}
@@ -0,0 +1,76 @@
+package fssync
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/directories"
+	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"os"
+)
+
+// changedScopes narrows the scopes down to the directories modified since their last sync.
+// The modification time of a directory changes whenever an entry is added, removed or
+// renamed in it, so unmodified directories are skipped while their sub directories are
+// still checked. Scopes unknown to the db are always considered changed.
+func changedScopes(ctx context.Context, dr model.DirectoryReader, scopes []string) ([]string, error) {
+	allDirectories, err := dr.GetAllDirectories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	changed := make([]string, 0)
+	for _, dir := range *allDirectories {
+		path := scopePath(dir.Path)
+		if !directorytree.IsInAnyScope(path, scopes) {
+			continue
+		}
+
+		known[path] = true
+		if directories.IsExcluded(&dir) {
+			continue
+		}
+
+		stat, err := os.Stat(relativasor.GetAbsoluteFile(path))
+		if err != nil || stat.ModTime().UnixMilli() >= dir.LastSynced {
+			changed = append(changed, path)
+		}
+	}
+
+	for _, scope := range scopes {
+		if !known[scope] {
+			changed = append(changed, scope)
+		}
+	}
+
+	return directorytree.NormalizeScopes(changed), nil
+}
+
+// markSynced sets the last synced time of every directory in the scopes
+func markSynced(ctx context.Context, drw model.DirectoryReaderWriter, scopes []string, syncStart int64) error {
+	allDirectories, err := drw.GetAllDirectories(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range *allDirectories {
+		if !directorytree.IsInAnyScope(scopePath(dir.Path), scopes) {
+			continue
+		}
+
+		if err := drw.UpdateDirectory(ctx, &model.Directory{Path: dir.Path, LastSynced: syncStart}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scopePath(directoryPath string) string {
+	if directoryPath == model.ROOT_DIRECTORY_PATH {
+		return ""
+	}
+
+	return directoryPath
+}
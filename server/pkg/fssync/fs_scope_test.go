@@ -0,0 +1,72 @@
+package fssync
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+	"k8s.io/utils/pointer"
+)
+
+func TestChangedScopes(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir, err := createTestTempDirectory()
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	relativasor.Init(testDir)
+
+	for _, dir := range []string{"movies/old", "movies/modified", "movies/excluded", "shows"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(testDir, dir), 0750))
+	}
+
+	synced := time.Now().Add(time.Hour).UnixMilli()
+	dr := model.NewMockDirectoryReader(ctrl)
+	dr.EXPECT().GetAllDirectories(gomock.Any()).Return(&[]model.Directory{
+		{Path: model.ROOT_DIRECTORY_PATH, LastSynced: synced},
+		{Path: "movies", LastSynced: synced},
+		{Path: "movies/old", LastSynced: synced},
+		{Path: "movies/modified", LastSynced: 0},
+		{Path: "movies/removed", LastSynced: synced},
+		{Path: "movies/excluded", LastSynced: 0, Excluded: pointer.Bool(true)},
+		{Path: "shows", LastSynced: 0},
+	}, nil).AnyTimes()
+
+	scopes, err := changedScopes(ctx, dr, []string{"movies", "movies/new"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/modified", "movies/new", "movies/removed"}, scopes)
+
+	scopes, err = changedScopes(ctx, dr, []string{"movies/old"})
+	assert.NoError(t, err)
+	assert.Empty(t, scopes)
+
+	scopes, err = changedScopes(ctx, dr, []string{""})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"movies/modified", "movies/removed", "shows"}, scopes)
+}
+
+func TestMarkSynced(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	drw := model.NewMockDirectoryReaderWriter(ctrl)
+	drw.EXPECT().GetAllDirectories(gomock.Any()).Return(&[]model.Directory{
+		{Path: model.ROOT_DIRECTORY_PATH},
+		{Path: "movies"},
+		{Path: "movies/new"},
+		{Path: "shows"},
+	}, nil)
+	drw.EXPECT().UpdateDirectory(gomock.Any(), &model.Directory{Path: "movies", LastSynced: 1234}).Return(nil)
+	drw.EXPECT().UpdateDirectory(gomock.Any(), &model.Directory{Path: "movies/new", LastSynced: 1234}).Return(nil)
+
+	assert.NoError(t, markSynced(ctx, drw, []string{"movies"}, 1234))
+}
@@ -6,8 +6,10 @@ import (
 	"my-collection/server/pkg/bl/items"
 	"my-collection/server/pkg/bl/tags"
 	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/fingerprint"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +18,13 @@ import (
 )
 
 func newFsSyncer(ctx context.Context, path string, db model.Database, dig model.DirectoryItemsGetter, filter directorytree.FilesFilter) (*fsSyncer, error) {
+	return newScopedFsSyncer(ctx, path, nil, db, dig, filter)
+}
+
+// newScopedFsSyncer only looks for changes inside the scopes, directories relative to path,
+// no scopes means the whole tree
+func newScopedFsSyncer(ctx context.Context, path string, scopes []string, db model.Database,
+	dig model.DirectoryItemsGetter, filter directorytree.FilesFilter) (*fsSyncer, error) {
 	exists, err := directories.DirectoryExists(ctx, db, path)
 	if err != nil {
 		return nil, err
@@ -24,17 +33,18 @@ func newFsSyncer(ctx context.Context, path string, db model.Database, dig model.
 		return nil, errors.Errorf("directory not found in db %s", path)
 	}
 
-	rootfs, err := directorytree.BuildFromPath(path, filter)
+	rootfs, err := directorytree.BuildFromPathScoped(path, scopes, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	rootdb, err := directorytree.BuildFromDb(ctx, db, dig)
+	rootdb, err := directorytree.BuildFromDbScoped(ctx, db, dig, scopes)
 	if err != nil {
 		return nil, err
 	}
 
 	diff := directorytree.Compare(rootfs, rootdb)
+	reconcileMovesByFingerprint(ctx, dig, db, scopes, diff)
 	stales := directorytree.FindStales(rootdb)
 
 	return &fsSyncer{
@@ -50,6 +60,149 @@ type fsSyncer struct {
 	ctx    context.Context
 }
 
+// reconcileMovesByFingerprint turns a removed file and an added file with the same content
+// into a move. Compare only detects moves of files that kept their name, anything else would
+// delete the item and create a new one, losing its covers, highlights, sub items and tags.
+// A scoped sync doesn't see the removal of a file moved into the scope from outside of it,
+// those are matched against the items outside of the scopes.
+func reconcileMovesByFingerprint(ctx context.Context, dig model.DirectoryItemsGetter, ir model.ItemReader,
+	scopes []string, diff *directorytree.Diff) {
+	if len(diff.AddedFiles) == 0 {
+		return
+	}
+
+	removedByFingerprint := make(map[string]int)
+	removedSizes := make(map[int64]bool)
+	for i, change := range diff.RemovedFiles {
+		dirpath := directories.NormalizeDirectoryPath(filepath.Dir(change.Path1))
+		item, err := dig.GetBelongingItem(ctx, dirpath, filepath.Base(change.Path1))
+		if err != nil {
+			logger.Warningf("Error getting item of removed file %s - %s", change.Path1, err)
+			continue
+		}
+
+		if item != nil && item.Fingerprint != "" {
+			removedByFingerprint[item.Fingerprint] = i
+			removedSizes[item.FileSize] = true
+		}
+	}
+
+	outsideScopes := len(scopes) > 0 && !directorytree.IsInAnyScope("", scopes)
+	if len(removedByFingerprint) == 0 && !outsideScopes {
+		return
+	}
+
+	moved := make(map[int]bool)
+	movedFromOutside := make(map[uint64]bool)
+	addedFiles := make([]directorytree.Change, 0, len(diff.AddedFiles))
+	for _, change := range diff.AddedFiles {
+		if i, ok := findRemovedByFingerprint(change.Path1, removedByFingerprint, removedSizes); ok && !moved[i] {
+			moved[i] = true
+			diff.MovedFiles = append(diff.MovedFiles, directorytree.Change{
+				Path1:      diff.RemovedFiles[i].Path1,
+				Path2:      change.Path1,
+				ChangeType: directorytree.FILE_MOVED,
+			})
+			continue
+		}
+
+		if outsideScopes {
+			if item, ok := findOutsideScopesByFingerprint(ctx, ir, scopes, change.Path1, movedFromOutside); ok {
+				movedFromOutside[item.Id] = true
+				diff.MovedFiles = append(diff.MovedFiles, directorytree.Change{
+					Path1:      itemPath(item),
+					Path2:      change.Path1,
+					ChangeType: directorytree.FILE_MOVED,
+				})
+				continue
+			}
+		}
+
+		addedFiles = append(addedFiles, change)
+	}
+
+	removedFiles := make([]directorytree.Change, 0, len(diff.RemovedFiles))
+	for i, change := range diff.RemovedFiles {
+		if !moved[i] {
+			removedFiles = append(removedFiles, change)
+		}
+	}
+
+	diff.AddedFiles = addedFiles
+	diff.RemovedFiles = removedFiles
+}
+
+func findRemovedByFingerprint(path string, removedByFingerprint map[string]int, removedSizes map[int64]bool) (int, bool) {
+	absolutePath := relativasor.GetAbsoluteFile(path)
+	stat, err := os.Stat(absolutePath)
+	if err != nil || !removedSizes[stat.Size()] {
+		// only files with the size of a removed file are worth reading
+		return 0, false
+	}
+
+	fingerprint, err := fingerprint.File(absolutePath)
+	if err != nil {
+		logger.Warningf("Error fingerprinting added file %s - %s", path, err)
+		return 0, false
+	}
+
+	i, ok := removedByFingerprint[fingerprint]
+	return i, ok
+}
+
+// findOutsideScopesByFingerprint looks for a live item outside of the scopes with the content
+// of the added file, its file must be gone, otherwise the added file is a copy
+func findOutsideScopesByFingerprint(ctx context.Context, ir model.ItemReader, scopes []string,
+	path string, taken map[uint64]bool) (*model.Item, bool) {
+	absolutePath := relativasor.GetAbsoluteFile(path)
+	stat, err := os.Stat(absolutePath)
+	if err != nil {
+		return nil, false
+	}
+
+	candidates, err := ir.GetItems(ctx, "file_size = ? and fingerprint != ''", stat.Size())
+	if err != nil {
+		logger.Warningf("Error getting items with the size of added file %s - %s", path, err)
+		return nil, false
+	}
+	if len(*candidates) == 0 {
+		return nil, false
+	}
+
+	fingerprint, err := fingerprint.File(absolutePath)
+	if err != nil {
+		logger.Warningf("Error fingerprinting added file %s - %s", path, err)
+		return nil, false
+	}
+
+	for _, item := range *candidates {
+		if item.Fingerprint != fingerprint || taken[item.Id] || directorytree.IsInAnyScope(item.Origin, scopes) {
+			continue
+		}
+
+		if _, err := os.Stat(relativasor.GetAbsoluteFile(itemPath(&item))); !os.IsNotExist(err) {
+			continue
+		}
+
+		return &item, true
+	}
+
+	return nil, false
+}
+
+// itemPath is the file of the item relative to the root, as the diff changes are
+func itemPath(item *model.Item) string {
+	if item.Origin == model.ROOT_DIRECTORY_PATH {
+		return item.Title
+	}
+
+	return filepath.Join(item.Origin, item.Title)
+}
+
+func (f *fsSyncer) plan(scopes []string, itemsCount int64) *directorytree.Plan {
+	return directorytree.NewPlan(scopes, f.diff, f.stales, itemsCount)
+}
+
 func (f *fsSyncer) hasFsChanges() bool {
 	return f.stales.HasChanges() || f.diff.HasChanges()
 }
@@ -257,6 +410,10 @@ func handleNewFile(ctx context.Context, digs model.DirectoryItemsGetterSetter, f
 		return err
 	}
 
+	if item.Fingerprint, err = fingerprint.File(relativasor.GetAbsoluteFile(path)); err != nil {
+		logger.Warningf("Error fingerprinting new file %s - %s", path, err)
+	}
+
 	item.Tags = autoTags
 	return digs.AddBelongingItem(ctx, item)
 }
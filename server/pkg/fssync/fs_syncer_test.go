@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/fingerprint"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
 	"os"
@@ -1345,3 +1346,86 @@ func TestRenameFiles2(t *testing.T) {
 	})
 	assert.Equal(t, 0, len(errs))
 }
+
+func TestReconcileMovesByFingerprint(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dig := model.NewMockDirectoryItemsGetter(ctrl)
+
+	testDir, err := createTestTempDirectory()
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	relativasor.Init(testDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "other"), 0750))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "other", "renamed.mp4"), []byte("moved content"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "new.mp4"), []byte("new content!!"), 0644))
+	movedFingerprint, err := fingerprint.File(filepath.Join(testDir, "other", "renamed.mp4"))
+	assert.NoError(t, err)
+
+	dig.EXPECT().GetBelongingItem(gomock.Any(), "dir", "original.mp4").
+		Return(&model.Item{Id: 1, FileSize: 13, Fingerprint: movedFingerprint}, nil)
+	dig.EXPECT().GetBelongingItem(gomock.Any(), "dir", "deleted.mp4").
+		Return(&model.Item{Id: 2, FileSize: 13, Fingerprint: "13-deleted"}, nil)
+
+	diff := &directorytree.Diff{
+		AddedFiles: []directorytree.Change{
+			{Path1: "other/renamed.mp4", ChangeType: directorytree.FILE_ADDED},
+			{Path1: "new.mp4", ChangeType: directorytree.FILE_ADDED},
+		},
+		RemovedFiles: []directorytree.Change{
+			{Path1: "dir/original.mp4", ChangeType: directorytree.FILE_REMOVED},
+			{Path1: "dir/deleted.mp4", ChangeType: directorytree.FILE_REMOVED},
+		},
+	}
+
+	reconcileMovesByFingerprint(ctx, dig, nil, nil, diff)
+	assert.Equal(t, []directorytree.Change{
+		{Path1: "dir/original.mp4", Path2: "other/renamed.mp4", ChangeType: directorytree.FILE_MOVED},
+	}, diff.MovedFiles)
+	assert.Equal(t, []directorytree.Change{{Path1: "new.mp4", ChangeType: directorytree.FILE_ADDED}}, diff.AddedFiles)
+	assert.Equal(t, []directorytree.Change{{Path1: "dir/deleted.mp4", ChangeType: directorytree.FILE_REMOVED}}, diff.RemovedFiles)
+}
+
+func TestReconcileMovesByFingerprintIntoScope(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dig := model.NewMockDirectoryItemsGetter(ctrl)
+	ir := model.NewMockItemReader(ctrl)
+
+	testDir, err := createTestTempDirectory()
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	relativasor.Init(testDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "inside"), 0750))
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "kept"), 0750))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "inside", "moved.mp4"), []byte("moved content"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "inside", "copy.mp4"), []byte("copied conten"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "kept", "kept.mp4"), []byte("copied conten"), 0644))
+	movedFingerprint, err := fingerprint.File(filepath.Join(testDir, "inside", "moved.mp4"))
+	assert.NoError(t, err)
+	copiedFingerprint, err := fingerprint.File(filepath.Join(testDir, "kept", "kept.mp4"))
+	assert.NoError(t, err)
+
+	// the removal of the moved file is outside of the scope, only the items know about it
+	ir.EXPECT().GetItems(gomock.Any(), "file_size = ? and fingerprint != ''", int64(13)).Return(&[]model.Item{
+		{Id: 1, Title: "original.mp4", Origin: "outside", FileSize: 13, Fingerprint: movedFingerprint},
+		{Id: 2, Title: "kept.mp4", Origin: "kept", FileSize: 13, Fingerprint: copiedFingerprint},
+	}, nil).Times(2)
+
+	diff := &directorytree.Diff{
+		AddedFiles: []directorytree.Change{
+			{Path1: "inside/moved.mp4", ChangeType: directorytree.FILE_ADDED},
+			{Path1: "inside/copy.mp4", ChangeType: directorytree.FILE_ADDED},
+		},
+	}
+
+	reconcileMovesByFingerprint(ctx, dig, ir, []string{"inside"}, diff)
+	assert.Equal(t, []directorytree.Change{
+		{Path1: "outside/original.mp4", Path2: "inside/moved.mp4", ChangeType: directorytree.FILE_MOVED},
+	}, diff.MovedFiles)
+	assert.Equal(t, []directorytree.Change{{Path1: "inside/copy.mp4", ChangeType: directorytree.FILE_ADDED}}, diff.AddedFiles)
+}
@@ -0,0 +1,160 @@
+package fssync
+
+import (
+	"context"
+	"io/fs"
+	"my-collection/server/pkg/bl/directories"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-errors/errors"
+)
+
+// fsWatcher watches every included directory under the root with inotify, and reports the
+// directories that changed once no more events arrive for the debounce duration
+type fsWatcher struct {
+	watcher       *fsnotify.Watcher
+	root          string
+	debounce      time.Duration
+	excludedMutex sync.RWMutex // refresh runs on the manager goroutine, events are handled by run
+	excluded      map[string]bool
+}
+
+func newFsWatcher(root string, debounce time.Duration) (*fsWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	return &fsWatcher{
+		watcher:  watcher,
+		root:     root,
+		debounce: debounce,
+		excluded: make(map[string]bool),
+	}, nil
+}
+
+// refresh reloads the excluded directories and updates the watches accordingly
+func (w *fsWatcher) refresh(ctx context.Context, dr model.DirectoryReader) error {
+	allDirectories, err := dr.GetAllDirectories(ctx)
+	if err != nil {
+		return err
+	}
+
+	excluded := make(map[string]bool)
+	for _, dir := range *allDirectories {
+		if directories.IsExcluded(&dir) {
+			excluded[dir.Path] = true
+		}
+	}
+
+	w.excludedMutex.Lock()
+	w.excluded = excluded
+	w.excludedMutex.Unlock()
+
+	for _, path := range w.watcher.WatchList() {
+		if w.isExcluded(path) {
+			if err := w.watcher.Remove(path); err != nil {
+				logger.Warningf("Error removing watch of %s - %s", path, err)
+			}
+		}
+	}
+
+	w.addRecursive(w.root)
+	return nil
+}
+
+func (w *fsWatcher) isExcluded(path string) bool {
+	w.excludedMutex.RLock()
+	defer w.excludedMutex.RUnlock()
+	return w.excluded[directories.NormalizeDirectoryPath(path)]
+}
+
+func (w *fsWatcher) addRecursive(path string) {
+	err := filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+
+		if current != w.root && (strings.HasPrefix(entry.Name(), ".") || w.isExcluded(current)) {
+			return filepath.SkipDir
+		}
+
+		if err := w.watcher.Add(current); err != nil {
+			logger.Warningf("Error watching %s - %s", current, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.Warningf("Error walking %s - %s", path, err)
+	}
+}
+
+// run sends the changed directories, relative to the root, until the context is done
+func (w *fsWatcher) run(ctx context.Context, changes chan<- []string) {
+	defer w.watcher.Close()
+
+	pending := make(map[string]bool)
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if w.handleEvent(event, pending) {
+				flush = time.After(w.debounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			logger.Warningf("FS watcher error %s", err)
+		case <-flush:
+			flush = nil
+			scopes := make([]string, 0, len(pending))
+			for dir := range pending {
+				scopes = append(scopes, dir)
+			}
+			pending = make(map[string]bool)
+
+			select {
+			case changes <- scopes:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (w *fsWatcher) handleEvent(event fsnotify.Event, pending map[string]bool) bool {
+	if event.Has(fsnotify.Chmod) || strings.HasPrefix(filepath.Base(event.Name), ".") {
+		return false
+	}
+
+	dir := filepath.Dir(event.Name)
+	if w.isExcluded(dir) {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if stat, err := os.Stat(event.Name); err == nil && stat.IsDir() {
+			w.addRecursive(event.Name)
+		}
+	}
+
+	pending[relativasor.GetRelativePath(dir)] = true
+	return true
+}
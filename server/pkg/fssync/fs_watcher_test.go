@@ -0,0 +1,72 @@
+package fssync
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+	"k8s.io/utils/pointer"
+)
+
+func waitForChanges(t *testing.T, changes chan []string) []string {
+	select {
+	case scopes := <-changes:
+		return scopes
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "no changes reported")
+		return nil
+	}
+}
+
+func TestFsWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir, err := createTestTempDirectory()
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	relativasor.Init(testDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "included", "deep"), 0750))
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "excluded"), 0750))
+
+	dr := model.NewMockDirectoryReader(ctrl)
+	dr.EXPECT().GetAllDirectories(gomock.Any()).Return(&[]model.Directory{
+		{Path: "included", Excluded: pointer.Bool(false)},
+		{Path: "excluded", Excluded: pointer.Bool(true)},
+	}, nil).Times(2)
+
+	watcher, err := newFsWatcher(testDir, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.NoError(t, watcher.refresh(ctx, dr))
+	assert.NotContains(t, watcher.watcher.WatchList(), filepath.Join(testDir, "excluded"))
+
+	changes := make(chan []string)
+	go watcher.run(ctx, changes)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "excluded", "file.mp4"), []byte{}, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "included", "deep", "file.mp4"), []byte{}, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "included", "deep", ".hidden"), []byte{}, 0644))
+	assert.Equal(t, []string{"included/deep"}, waitForChanges(t, changes))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(testDir, "new"), 0750))
+	assert.Equal(t, []string{""}, waitForChanges(t, changes))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "new", "file.mp4"), []byte{}, 0644))
+	assert.Equal(t, []string{"new"}, waitForChanges(t, changes))
+
+	// directories are refreshed while events are being handled
+	refreshed := make(chan error)
+	go func() { refreshed <- watcher.refresh(ctx, dr) }()
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "included", "other.mp4"), []byte{}, 0644))
+	assert.Equal(t, []string{"included"}, waitForChanges(t, changes))
+	assert.NoError(t, <-refreshed)
+}
@@ -60,6 +60,7 @@ func (d *ItemsOptimizer) HandleItem(ctx context.Context, item *model.Item) {
 	}
 
 	logger.Infof("Item with high resolution %v", item.Title)
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
 	d.processor.EnqueueChangeResolution(ctx, item.Id, -1, d.maxResolution, item.Title)
 }
 
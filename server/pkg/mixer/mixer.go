@@ -0,0 +1,39 @@
+package mixer
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/model"
+
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("mixer")
+
+// Strategy picks up to count items out of the candidates, used by the DailyMix
+// and the Mod mixes.
+type Strategy interface {
+	Pick(ctx context.Context, candidates []*model.Item, count int) ([]*model.Item, error)
+}
+
+// GetItems picks count items out of all the items passing the filter, items
+// tagged with a NoRandom tag are never picked.
+func GetItems(ctx context.Context, ir model.ItemReader, strategy Strategy, count int, filter items.ItemsFilter) ([]*model.Item, error) {
+	allItems, err := ir.GetAllItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return strategy.Pick(ctx, FilterCandidates(*allItems, filter), count)
+}
+
+func FilterCandidates(all []model.Item, filter items.ItemsFilter) []*model.Item {
+	candidates := make([]*model.Item, 0, len(all))
+	for i := range all {
+		if filter(&all[i]) && !items.IsNoRandom(&all[i]) {
+			candidates = append(candidates, &all[i])
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,137 @@
+package mixer
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/bl/special_tags"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+type fakeCategories struct {
+	categories []model.Tag
+}
+
+func (f *fakeCategories) GetUserCategories(ctx context.Context) (*[]model.Tag, error) {
+	return &f.categories, nil
+}
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func allItems(item *model.Item) bool {
+	return true
+}
+
+func getCandidates(t *testing.T, db db.Database) []*model.Item {
+	all, err := db.GetAllItems(context.Background())
+	assert.NoError(t, err)
+	return FilterCandidates(*all, allItems)
+}
+
+func TestWeightedSkipsNoRandom(t *testing.T) {
+	db := setupNewDb(t, "norandom.sqlite")
+	ctx := context.Background()
+
+	noRandom := &model.Tag{Title: "hidden", NoRandom: pointer.Bool(true)}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, noRandom))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: "visible", Origin: "origin"}))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: "hidden", Origin: "origin", Tags: []*model.Tag{noRandom}}))
+
+	weighted := NewWeighted(db, &fakeCategories{})
+	for i := 0; i < 20; i++ {
+		picked, err := GetItems(ctx, db, weighted, 2, allItems)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(picked))
+		assert.Equal(t, "visible", picked[0].Title)
+	}
+
+	// even when asked directly, the weight of NoRandom items is 0
+	all, err := db.GetAllItems(ctx)
+	assert.NoError(t, err)
+	candidates := make([]*model.Item, 0)
+	for i := range *all {
+		candidates = append(candidates, &(*all)[i])
+	}
+
+	picked, err := weighted.Pick(ctx, candidates, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(picked))
+}
+
+func TestWeightedCoversCategories(t *testing.T) {
+	db := setupNewDb(t, "categories.sqlite")
+	ctx := context.Background()
+
+	category := &model.Tag{Title: "genre"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, category))
+	tags := make([]*model.Tag, 0)
+	for _, title := range []string{"action", "drama", "comedy"} {
+		tag := &model.Tag{Title: title, ParentID: &category.Id}
+		assert.NoError(t, db.CreateOrUpdateTag(ctx, tag))
+		tags = append(tags, tag)
+	}
+
+	// many more action items, so a plain random pick would mostly miss the others
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: fmt.Sprintf("action-%d", i), Origin: "origin", Tags: []*model.Tag{tags[0]}}))
+	}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: "drama", Origin: "origin", Tags: []*model.Tag{tags[1]}}))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: "comedy", Origin: "origin", Tags: []*model.Tag{tags[2]}}))
+
+	weighted := NewWeighted(db, &fakeCategories{categories: []model.Tag{*category}})
+	for i := 0; i < 10; i++ {
+		picked, err := weighted.Pick(ctx, getCandidates(t, db), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(picked))
+
+		covered := make(map[uint64]bool)
+		for _, item := range picked {
+			covered[item.Tags[0].Id] = true
+		}
+		assert.Equal(t, 3, len(covered))
+	}
+}
+
+func TestWeightedPrefersFreshItems(t *testing.T) {
+	db := setupNewDb(t, "fresh.sqlite")
+	ctx := context.Background()
+
+	fresh := &model.Item{Title: "fresh", Origin: "origin"}
+	stale := &model.Item{Title: "stale", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, fresh))
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, stale))
+
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, special_tags.DailymixTag))
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, &model.Tag{Title: "mix", ParentID: &special_tags.DailymixTag.Id, Items: []*model.Item{stale}}))
+	assert.NoError(t, db.SaveWatchProgress(ctx, &model.WatchProgress{ItemId: stale.Id, Timestamp: time.Now().UnixMilli()}))
+
+	weighted := NewWeighted(db, &fakeCategories{})
+	freshCount := 0
+	for i := 0; i < 200; i++ {
+		picked, err := weighted.Pick(ctx, getCandidates(t, db), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(picked))
+		if picked[0].Id == fresh.Id {
+			freshCount++
+		}
+	}
+
+	// stale weight is 0.1 * 0.25 vs 2 for the fresh item
+	assert.Greater(t, freshCount, 190)
+}
@@ -0,0 +1,262 @@
+package mixer
+
+import (
+	"context"
+	"math/rand"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/special_tags"
+	"my-collection/server/pkg/model"
+	"sort"
+	"time"
+)
+
+const (
+	recentMixesCount      = 7 // of each kind, DailyMix and Mod
+	recentlyWatchedPeriod = 14 * 24 * time.Hour
+
+	recentlyMixedFactor   = 0.1
+	recentlyWatchedFactor = 0.25
+	neverWatchedFactor    = 2
+
+	uncategorized = 0
+)
+
+type UserCategoriesGetter interface {
+	GetUserCategories(ctx context.Context) (*[]model.Tag, error)
+}
+
+// Weigher returns a factor the weight of an item is multiplied by, 1 is neutral
+// and 0 means the item is never picked.
+type Weigher interface {
+	Weight(item *model.Item) float64
+}
+
+type weightedDb interface {
+	model.TagReader
+	model.WatchProgressReader
+}
+
+func NewWeighted(db weightedDb, ucg UserCategoriesGetter, weighers ...Weigher) *Weighted {
+	return &Weighted{
+		db:       db,
+		ucg:      ucg,
+		weighers: weighers,
+	}
+}
+
+// Weighted picks items randomly with a weight per item, recently mixed and
+// recently watched items are less likely to be picked and never watched items
+// more likely. Items of user category tags that weren't picked yet are preferred,
+// so the mix covers as many of them as possible.
+type Weighted struct {
+	db       weightedDb
+	ucg      UserCategoriesGetter
+	weighers []Weigher
+}
+
+type weightedCandidate struct {
+	item         *model.Item
+	weight       float64
+	categoryTags []uint64
+}
+
+func (w *Weighted) GetCurrentTime() time.Time {
+	return time.Now()
+}
+
+func (w *Weighted) Pick(ctx context.Context, candidates []*model.Item, count int) ([]*model.Item, error) {
+	recentlyMixed, err := w.getRecentlyMixed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastWatched, err := w.getLastWatched(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := w.ucg.GetUserCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIds := make(map[uint64]bool)
+	for _, category := range *categories {
+		categoryIds[category.Id] = true
+	}
+
+	now := w.GetCurrentTime()
+	pool := make([]*weightedCandidate, 0, len(candidates))
+	for _, item := range candidates {
+		pool = append(pool, &weightedCandidate{
+			item:         item,
+			weight:       w.weight(item, recentlyMixed, lastWatched, now),
+			categoryTags: getCategoryTags(item, categoryIds),
+		})
+	}
+
+	result := make([]*model.Item, 0, count)
+	covered := make(map[uint64]bool)
+	for len(result) < count {
+		chosen := pickWeighted(pool, func(c *weightedCandidate) bool {
+			return hasUncoveredTag(c, covered)
+		})
+		if chosen < 0 {
+			chosen = pickWeighted(pool, func(c *weightedCandidate) bool { return true })
+		}
+		if chosen < 0 {
+			break
+		}
+
+		result = append(result, pool[chosen].item)
+		for _, tagId := range pool[chosen].categoryTags {
+			covered[tagId] = true
+		}
+
+		pool = append(pool[:chosen], pool[chosen+1:]...)
+	}
+
+	logger.Debugf("Picked %d out of %d candidates covering %d category tags", len(result), len(candidates), len(covered))
+	return result, nil
+}
+
+func (w *Weighted) weight(item *model.Item, recentlyMixed map[uint64]bool, lastWatched map[uint64]int64, now time.Time) float64 {
+	if items.IsNoRandom(item) {
+		return 0
+	}
+
+	weight := 1.0
+	if recentlyMixed[item.Id] {
+		weight *= recentlyMixedFactor
+	}
+
+	watched, ok := getItemLastWatched(item, lastWatched)
+	if !ok {
+		weight *= neverWatchedFactor
+	} else if now.Sub(time.UnixMilli(watched)) < recentlyWatchedPeriod {
+		weight *= recentlyWatchedFactor
+	}
+
+	for _, weigher := range w.weighers {
+		weight *= weigher.Weight(item)
+	}
+
+	return weight
+}
+
+func getItemLastWatched(item *model.Item, lastWatched map[uint64]int64) (int64, bool) {
+	if watched, ok := lastWatched[item.Id]; ok {
+		return watched, true
+	}
+
+	// progress of highlights and sub-items is rolled up into the main item
+	if item.MainItemId != nil {
+		watched, ok := lastWatched[*item.MainItemId]
+		return watched, ok
+	}
+
+	if item.HighlightParentItemId != nil {
+		watched, ok := lastWatched[*item.HighlightParentItemId]
+		return watched, ok
+	}
+
+	return 0, false
+}
+
+func (w *Weighted) getRecentlyMixed(ctx context.Context) (map[uint64]bool, error) {
+	result := make(map[uint64]bool)
+	for _, parentId := range []uint64{special_tags.DailymixTag.Id, special_tags.MixOnDemandTag.Id} {
+		mixes, err := w.db.GetTags(ctx, "parent_id = ?", parentId)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(*mixes, func(i, j int) bool {
+			return (*mixes)[i].Id > (*mixes)[j].Id
+		})
+
+		for i, mix := range *mixes {
+			if i == recentMixesCount {
+				break
+			}
+
+			for _, item := range mix.Items {
+				result[item.Id] = true
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (w *Weighted) getLastWatched(ctx context.Context) (map[uint64]int64, error) {
+	history, err := w.db.GetWatchHistory(ctx, false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]int64)
+	for _, progress := range *history {
+		result[progress.ItemId] = progress.Timestamp
+	}
+
+	return result, nil
+}
+
+// getCategoryTags returns the ids of the item's tags that belong to user categories,
+// items without any are grouped together under the uncategorized id.
+func getCategoryTags(item *model.Item, categoryIds map[uint64]bool) []uint64 {
+	result := make([]uint64, 0)
+	for _, tag := range item.Tags {
+		if tag.ParentID != nil && categoryIds[*tag.ParentID] {
+			result = append(result, tag.Id)
+		}
+	}
+
+	if len(result) == 0 {
+		result = append(result, uncategorized)
+	}
+
+	return result
+}
+
+func hasUncoveredTag(c *weightedCandidate, covered map[uint64]bool) bool {
+	for _, tagId := range c.categoryTags {
+		if !covered[tagId] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pickWeighted returns the index of a random candidate out of the ones accepted,
+// or -1 if none of them has a positive weight.
+func pickWeighted(pool []*weightedCandidate, accept func(c *weightedCandidate) bool) int {
+	total := 0.0
+	for _, c := range pool {
+		if c.weight > 0 && accept(c) {
+			total += c.weight
+		}
+	}
+
+	if total == 0 {
+		return -1
+	}
+
+	target := rand.Float64() * total
+	last := -1
+	for i, c := range pool {
+		if c.weight <= 0 || !accept(c) {
+			continue
+		}
+
+		last = i
+		target -= c.weight
+		if target < 0 {
+			return i
+		}
+	}
+
+	return last
+}
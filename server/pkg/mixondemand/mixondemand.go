@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"my-collection/server/pkg/bl/items"
 	"my-collection/server/pkg/bl/special_tags"
 	"my-collection/server/pkg/bl/tag_annotations"
 	"my-collection/server/pkg/bl/tags"
+	"my-collection/server/pkg/mixer"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/suggestions"
 	"time"
@@ -18,7 +20,7 @@ type mixOnDemandDb interface {
 	model.TagAnnotationReaderWriter
 }
 
-func New(ctx context.Context, db mixOnDemandDb, mixOnDemandItemsCount int) (*MixOnDemand, error) {
+func New(ctx context.Context, db mixOnDemandDb, strategy mixer.Strategy, mixOnDemandItemsCount int) (*MixOnDemand, error) {
 	d, err := db.GetTag(ctx, special_tags.MixOnDemandTag)
 	if err != nil {
 		if err := db.CreateOrUpdateTag(ctx, special_tags.MixOnDemandTag); err != nil {
@@ -30,12 +32,14 @@ func New(ctx context.Context, db mixOnDemandDb, mixOnDemandItemsCount int) (*Mix
 
 	return &MixOnDemand{
 		db:                    db,
+		strategy:              strategy,
 		mixOnDemandItemsCount: mixOnDemandItemsCount,
 	}, nil
 }
 
 type MixOnDemand struct {
 	db                    mixOnDemandDb
+	strategy              mixer.Strategy
 	mixOnDemandItemsCount int
 }
 
@@ -49,7 +53,7 @@ func (d *MixOnDemand) GenerateMixOnDemand(ctx context.Context, ctg model.Current
 		return nil, err
 	}
 
-	result, err := suggestions.GetSuggestionsForTags(ctx, d.db, d.db, &tags, d.mixOnDemandItemsCount)
+	result, err := d.pickItems(ctx, &tags)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +62,40 @@ func (d *MixOnDemand) GenerateMixOnDemand(ctx context.Context, ctg model.Current
 	return tag, d.db.CreateOrUpdateTag(ctx, tag)
 }
 
+// pickItems picks the mix out of the items of the given tags, and completes it
+// from all the items if there are not enough of them.
+func (d *MixOnDemand) pickItems(ctx context.Context, tags *[]model.Tag) ([]*model.Item, error) {
+	relatedItems, err := suggestions.GetItemsOfTags(ctx, d.db, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*model.Item, 0, len(relatedItems))
+	for _, item := range relatedItems {
+		if !items.IsNoRandom(item) {
+			candidates = append(candidates, item)
+		}
+	}
+
+	result, err := d.strategy.Pick(ctx, candidates, d.mixOnDemandItemsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) >= d.mixOnDemandItemsCount {
+		return result, nil
+	}
+
+	more, err := mixer.GetItems(ctx, d.db, d.strategy, d.mixOnDemandItemsCount-len(result), func(item *model.Item) bool {
+		return !items.IsHighlight(item) && !items.IsSplittedItem(item) && !items.ItemExists(result, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, more...), nil
+}
+
 func getCurrentMixOnDemandTitle(desc string, ctg model.CurrentTimeGetter) string {
 	return fmt.Sprintf("%s - %s", desc, ctg.GetCurrentTime().Format("02-Jan-2006 15:04:05"))
 }
@@ -1,5 +1,11 @@
 package model
 
+import (
+	"my-collection/server/pkg/querylang"
+
+	"gorm.io/gorm"
+)
+
 type ItemsAndTags struct {
 	Items []Item `json:"items"`
 	Tags  []Tag  `json:"tags"`
@@ -16,6 +22,8 @@ type Tag struct {
 	DisplayStyle   string           `json:"display_style,omitempty"`
 	DefaultSorting string           `json:"default_sorting,omitempty"`
 	NoRandom       *bool            `json:"no_random,omitempty"`
+	SmartQuery     string           `json:"smart_query,omitempty"` // querylang expression, see smarttags
+	DeletedAt      gorm.DeletedAt   `json:"-" gorm:"index"`        // set while the tag is in the trash
 }
 
 type TagImageType struct {
@@ -37,30 +45,45 @@ type TagImage struct {
 }
 
 type Item struct {
-	Id                    uint64  `json:"id,omitempty"`
-	Title                 string  `json:"title,omitempty" gorm:"uniqueIndex:title_and_dir_idx"`
-	Origin                string  `json:"origin,omitempty" gorm:"uniqueIndex:title_and_dir_idx"`
-	DurationSeconds       float64 `json:"duration_seconds,omitempty"`
-	FileSize              int64   `json:"file_size,omitempty"`
-	Width                 int     `json:"width,omitempty"`
-	Height                int     `json:"height,omitempty"`
-	VideoCodecName        string  `json:"video_codec,omitempty"`
-	AudioCodecName        string  `json:"audio_codec,omitempty"`
-	Url                   string  `json:"url,omitempty"`
-	PreviewUrl            string  `json:"preview_url,omitempty"`
-	PreviewMode           string  `json:"preview_mode,omitempty"`
-	LastModified          int64   `json:"last_modified,omitempty"`
-	Covers                []Cover `json:"covers,omitempty"`
-	MainCoverUrl          *string `json:"main_cover_url,omitempty"`
-	MainCoverSecond       float64 `json:"main_cover_second,omitempty"`
-	MainCoverNonce        int64   `json:"main_cover_nonce,omitempty"`
-	Tags                  []*Tag  `json:"tags,omitempty" gorm:"many2many:tag_items;"`
-	StartPosition         float64 `json:"start_position,omitempty"`
-	EndPosition           float64 `json:"end_position,omitempty"`
-	Highlights            []*Item `json:"highlights,omitempty" gorm:"foreignkey:HighlightParentItemId"`
-	HighlightParentItemId *uint64 `json:"highlight_parent_id,omitempty"`
-	SubItems              []*Item `json:"sub_items,omitempty" gorm:"foreignkey:MainItemId"`
-	MainItemId            *uint64 `json:"main_item,omitempty"`
+	Id                    uint64         `json:"id,omitempty"`
+	Title                 string         `json:"title,omitempty" gorm:"uniqueIndex:title_and_dir_idx"`
+	Origin                string         `json:"origin,omitempty" gorm:"uniqueIndex:title_and_dir_idx"`
+	DurationSeconds       float64        `json:"duration_seconds,omitempty"`
+	FileSize              int64          `json:"file_size,omitempty"`
+	Width                 int            `json:"width,omitempty"`
+	Height                int            `json:"height,omitempty"`
+	VideoCodecName        string         `json:"video_codec,omitempty"`
+	AudioCodecName        string         `json:"audio_codec,omitempty"`
+	Url                   string         `json:"url,omitempty"`
+	PreviewUrl            string         `json:"preview_url,omitempty"`
+	PreviewMode           string         `json:"preview_mode,omitempty"`
+	LastModified          int64          `json:"last_modified,omitempty"`
+	Fingerprint           string         `json:"fingerprint,omitempty" gorm:"index"` // identifies the file content across renames and moves
+	Covers                []Cover        `json:"covers,omitempty"`
+	MainCoverUrl          *string        `json:"main_cover_url,omitempty"`
+	MainCoverSecond       float64        `json:"main_cover_second,omitempty"`
+	MainCoverNonce        int64          `json:"main_cover_nonce,omitempty"`
+	Tags                  []*Tag         `json:"tags,omitempty" gorm:"many2many:tag_items;"`
+	StartPosition         float64        `json:"start_position,omitempty"`
+	EndPosition           float64        `json:"end_position,omitempty"`
+	Highlights            []*Item        `json:"highlights,omitempty" gorm:"foreignkey:HighlightParentItemId"`
+	HighlightParentItemId *uint64        `json:"highlight_parent_id,omitempty"`
+	SubItems              []*Item        `json:"sub_items,omitempty" gorm:"foreignkey:MainItemId"`
+	MainItemId            *uint64        `json:"main_item,omitempty"`
+	Rating                int            `json:"rating,omitempty"` // 1 to 5, 0 is unrated
+	Favorite              bool           `json:"favorite,omitempty"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"` // set while the item is in the trash
+	ItemMetadata
+}
+
+// ItemMetadata describes the content of an item rather than its file, it's edited by the user
+// or imported from nfo files, see bl/metadata
+type ItemMetadata struct {
+	ReleaseYear   int         `json:"release_year,omitempty" gorm:"index"`
+	OriginalTitle string      `json:"original_title,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Language      string      `json:"language,omitempty"` // ISO 639-1 code of the original language
+	ExternalIds   ExternalIds `json:"external_ids,omitempty" gorm:"type:json"`
 }
 
 type Subtitle struct {
@@ -114,19 +137,38 @@ type FsNode struct {
 }
 
 type Task struct {
-	Id              string   `json:"id,omitempty" gorm:"primarykey"`
-	EnequeueTime    *int64   `json:"enqueueTime,omitempty"`
-	ProcessingStart *int64   `json:"processingStart,omitempty"`
-	ProcessingEnd   *int64   `json:"processingEnd,omitempty"`
-	TaskType        TaskType `json:"type,omitempty"`
-	Description     string   `json:"description,omitempty" gorm:"-:all"`
-	Params          string   // a json string containing specific task parameters based on its type
+	Id              string       `json:"id,omitempty" gorm:"primarykey"`
+	EnequeueTime    *int64       `json:"enqueueTime,omitempty"`
+	ProcessingStart *int64       `json:"processingStart,omitempty"`
+	ProcessingEnd   *int64       `json:"processingEnd,omitempty"`
+	TaskType        TaskType     `json:"type,omitempty"`
+	Description     string       `json:"description,omitempty" gorm:"-:all"`
+	Params          string       // a json string containing specific task parameters based on its type
+	Attempts        int          `json:"attempts,omitempty"`
+	Error           string       `json:"error,omitempty"`                   // of the last failed attempt
+	RetryTime       *int64       `json:"retryTime,omitempty"`               // of the next attempt, while waiting for it
+	DeadLetter      bool         `json:"deadLetter,omitempty" gorm:"index"` // failed all its attempts
+	Priority        TaskPriority `json:"priority,omitempty"`
+	IdempotencyKey  string       `json:"-" gorm:"index"` // equal for tasks doing the same work
 }
 
 type QueueMetadata struct {
-	Size            *int64 `json:"size,omitempty"`
-	Paused          *bool  `json:"paused,omitempty"`
-	UnfinishedTasks *int64 `json:"unfinishedTasks,omitempty"`
+	Size            *int64         `json:"size,omitempty"`
+	Paused          *bool          `json:"paused,omitempty"`
+	UnfinishedTasks *int64         `json:"unfinishedTasks,omitempty"`
+	FailedTasks     *int64         `json:"failedTasks,omitempty"`
+	Pools           []PoolMetadata `json:"pools,omitempty"`
+}
+
+// PoolMetadata describes a worker pool of the processor, the counters are since the server started
+type PoolMetadata struct {
+	Name          string `json:"name,omitempty"`
+	Workers       int    `json:"workers,omitempty"`
+	Busy          int64  `json:"busy"`
+	Queued        int64  `json:"queued"`
+	Processed     int64  `json:"processed"`
+	Failed        int64  `json:"failed"`
+	AverageMillis int64  `json:"averageMillis"`
 }
 
 type PushMessage struct {
@@ -149,3 +191,253 @@ type Stats struct {
 	ItemsCount           int64   `json:"items_count,omitempty"`
 	TotalDurationSeconds float64 `json:"total_duration_seconds,omitempty"`
 }
+
+type ItemsQuery struct {
+	Offset        int            `json:"offset,omitempty"`
+	Limit         int            `json:"limit,omitempty"` // 0 means no limit
+	SortBy        ItemsSortKey   `json:"sortBy,omitempty"`
+	Descending    bool           `json:"descending,omitempty"`
+	AllTags       []uint64       `json:"allTags,omitempty"`
+	AnyTags       []uint64       `json:"anyTags,omitempty"`
+	ExcludedTags  []uint64       `json:"excludedTags,omitempty"`
+	MinDuration   *float64       `json:"minDuration,omitempty"`
+	MaxDuration   *float64       `json:"maxDuration,omitempty"`
+	MinResolution *int           `json:"minResolution,omitempty"` // height in pixels
+	MaxResolution *int           `json:"maxResolution,omitempty"` // height in pixels
+	Codec         string         `json:"codec,omitempty"`
+	OriginPrefix  string         `json:"origin,omitempty"`
+	Kind          ItemKind       `json:"kind,omitempty"`
+	MinRating     *int           `json:"minRating,omitempty"`
+	MaxRating     *int           `json:"maxRating,omitempty"`
+	Favorite      *bool          `json:"favorite,omitempty"`
+	MinYear       *int           `json:"minYear,omitempty"`
+	MaxYear       *int           `json:"maxYear,omitempty"`
+	Language      string         `json:"language,omitempty"`
+	ExternalId    *ExternalId    `json:"externalId,omitempty"`
+	Expression    querylang.Node `json:"-"`
+}
+
+type ItemsPage struct {
+	Items  []Item `json:"items"`
+	Total  int64  `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type SearchQuery struct {
+	Text      string `json:"text"`
+	Subtitles bool   `json:"subtitles,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	Limit     int    `json:"limit,omitempty"` // 0 means no limit
+}
+
+type SearchHit struct {
+	ItemId      uint64       `json:"itemId"`
+	Title       string       `json:"title"`
+	Source      SearchSource `json:"source"`
+	Snippet     string       `json:"snippet"` // matched terms are wrapped with <b></b>
+	Rank        float64      `json:"rank"`    // lower is better
+	SubtitleUrl string       `json:"subtitleUrl,omitempty"`
+	StartMillis *int64       `json:"startMillis,omitempty"`
+	EndMillis   *int64       `json:"endMillis,omitempty"`
+}
+
+type SearchResults struct {
+	Hits   []SearchHit `json:"hits"`
+	Total  int64       `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
+
+type WatchProgress struct {
+	ItemId          uint64  `json:"item_id" gorm:"primaryKey;autoIncrement:false"`
+	PositionSeconds float64 `json:"position_seconds"` // in the item's file time, like StartPosition
+	Timestamp       int64   `json:"timestamp"`        // unix millis of the last report
+	Completed       bool    `json:"completed"`
+}
+
+type SuggestionReason struct {
+	Kind        SuggestionReasonKind `json:"kind"`
+	Description string               `json:"description"`
+	Score       float64              `json:"score"`
+}
+
+type Suggestion struct {
+	Item    *Item              `json:"item"`
+	Score   float64            `json:"score"`
+	Reasons []SuggestionReason `json:"reasons"`
+}
+
+type RatingImport struct {
+	ItemId   uint64 `json:"id,omitempty"`
+	Title    string `json:"title,omitempty"` // with origin, used when the id is missing
+	Origin   string `json:"origin,omitempty"`
+	Rating   int    `json:"rating"`
+	Favorite *bool  `json:"favorite,omitempty"`
+}
+
+type RatingImportResult struct {
+	Imported int      `json:"imported"`
+	Failed   []string `json:"failed"`
+}
+
+type BulkOperation struct {
+	Type           BulkOperationType `json:"type"`
+	TagIds         []uint64          `json:"tags,omitempty"`           // of add-tags and remove-tags
+	DeleteRealFile bool              `json:"deleteRealFile,omitempty"` // of delete
+}
+
+// BulkRequest selects items either by ids or by a query in the items query language
+type BulkRequest struct {
+	ItemIds    []uint64        `json:"items,omitempty"`
+	Query      string          `json:"query,omitempty"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+type BulkItemResult struct {
+	ItemId  uint64   `json:"itemId"`
+	Title   string   `json:"title"`
+	Success bool     `json:"success"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+type BulkResult struct {
+	Items     []BulkItemResult `json:"items"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+type VideoHash struct {
+	ItemId uint64 `json:"itemId" gorm:"primaryKey;autoIncrement:false"`
+	Hashes string `json:"hashes"` // comma separated hex dHashes of frames sampled evenly along the item
+}
+
+type DuplicateGroup struct {
+	Items    []*Item `json:"items"`
+	KeeperId uint64  `json:"keeperId"` // the suggested item to keep, highest resolution then largest file
+	Distance float64 `json:"distance"` // the largest frames distance between the keeper and the others
+}
+
+type ContinueWatching struct {
+	Item     *Item         `json:"item"`
+	Progress WatchProgress `json:"progress"`
+}
+
+type TrashedItem struct {
+	Item      Item  `json:"item"`
+	DeletedAt int64 `json:"deletedAt"`
+}
+
+type TrashedTag struct {
+	Tag       Tag   `json:"tag"`
+	DeletedAt int64 `json:"deletedAt"`
+}
+
+type Trash struct {
+	Items []TrashedItem `json:"items"`
+	Tags  []TrashedTag  `json:"tags"`
+}
+
+type PurgeResult struct {
+	Items int `json:"items"`
+	Tags  int `json:"tags"`
+}
+
+// EnqueueSummary counts the tasks of a batch, coalesced tasks were already pending
+type EnqueueSummary struct {
+	Enqueued  int `json:"enqueued"`
+	Coalesced int `json:"coalesced"`
+}
+
+type StorageGcReport struct {
+	DryRun           bool     `json:"dryRun"`
+	ScannedFiles     int      `json:"scannedFiles"`
+	OrphanFiles      int      `json:"orphanFiles"`
+	ReclaimableBytes int64    `json:"reclaimableBytes"`
+	DeletedFiles     int      `json:"deletedFiles"`
+	Orphans          []string `json:"orphans"`
+}
+
+// MetadataCandidate is a search result of a metadata provider, see scraper
+type MetadataCandidate struct {
+	Provider      string `json:"provider"`
+	Id            string `json:"id"`
+	Title         string `json:"title"`
+	OriginalTitle string `json:"originalTitle,omitempty"`
+	ReleaseYear   int    `json:"releaseYear,omitempty"`
+	Score         int    `json:"score"` // how well it matches the item, see scraper.Score
+}
+
+// ScrapedMetadata is what a metadata provider knows about a title, PosterUrl is resolved by
+// the same provider
+type ScrapedMetadata struct {
+	Provider  string       `json:"provider"`
+	Id        string       `json:"id"`
+	Title     string       `json:"title"`
+	Metadata  ItemMetadata `json:"metadata"`
+	Genres    []string     `json:"genres,omitempty"`
+	Cast      []string     `json:"cast,omitempty"` // ordered by billing
+	Directors []string     `json:"directors,omitempty"`
+	PosterUrl string       `json:"posterUrl,omitempty"`
+}
+
+type ScrapeResult struct {
+	ItemId   uint64 `json:"itemId"`
+	Matched  bool   `json:"matched"`
+	Provider string `json:"provider,omitempty"`
+	Id       string `json:"id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Tags     int    `json:"tags"`
+	Poster   bool   `json:"poster"`
+}
+
+// Snapshot is the whole collection model, associations are kept as id pairs so it can be
+// restored into another database, see backup
+type Snapshot struct {
+	Items             []Item              `json:"items"`
+	Tags              []Tag               `json:"tags"`
+	TagItems          []TagItem           `json:"tagItems"`
+	TagImageTypes     []TagImageType      `json:"tagImageTypes"`
+	TagImages         []TagImage          `json:"tagImages"`
+	TagAnnotations    []TagAnnotation     `json:"tagAnnotations"`
+	TagsAnnotations   []TagAnnotationLink `json:"tagsAnnotations"`
+	Directories       []Directory         `json:"directories"`
+	DirectoryTags     []DirectoryTag      `json:"directoryTags"`
+	TagCustomCommands []TagCustomCommand  `json:"tagCustomCommands"`
+	WatchProgress     []WatchProgress     `json:"watchProgress"`
+	Tasks             []Task              `json:"tasks"` // the unfinished ones
+}
+
+type TagItem struct {
+	TagId  uint64 `json:"tagId"`
+	ItemId uint64 `json:"itemId"`
+}
+
+func (TagItem) TableName() string {
+	return "tag_items"
+}
+
+type TagAnnotationLink struct {
+	TagId           uint64 `json:"tagId"`
+	TagAnnotationId uint64 `json:"tagAnnotationId"`
+}
+
+type DirectoryTag struct {
+	DirectoryPath string `json:"directoryPath"`
+	TagId         uint64 `json:"tagId"`
+}
+
+type RestoreCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type RestoreResult struct {
+	Items        RestoreCounts `json:"items"`
+	Tags         RestoreCounts `json:"tags"`
+	Directories  RestoreCounts `json:"directories"`
+	StorageFiles int           `json:"storageFiles"`
+	Tasks        int           `json:"tasks"`
+	EmptyTarget  bool          `json:"emptyTarget"` // the database had no items and tags before the restore
+}
@@ -20,6 +20,28 @@ type ItemWriter interface {
 	RemoveTagFromItem(ctx context.Context, itemId uint64, tagId uint64) error
 }
 
+type ItemPreferencesWriter interface {
+	SetItemRating(ctx context.Context, itemId uint64, rating int) error
+	SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error
+}
+
+type ItemMetadataWriter interface {
+	SetItemMetadata(ctx context.Context, itemId uint64, metadata *ItemMetadata) error
+}
+
+type ItemsQuerier interface {
+	QueryItems(ctx context.Context, query *ItemsQuery) (*ItemsPage, error)
+}
+
+type Searcher interface {
+	Search(ctx context.Context, query *SearchQuery) (*SearchResults, error)
+}
+
+type SubtitlesIndexer interface {
+	IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle Subtitle) error
+	RemoveSubtitleIndex(ctx context.Context, url string) error
+}
+
 type ItemReaderWriter interface {
 	ItemReader
 	ItemWriter
@@ -39,6 +61,14 @@ type TagWriter interface {
 	RemoveTagImageFromTag(ctx context.Context, tagId uint64, imageId uint64) error
 }
 
+type TagItemsSetter interface {
+	SetTagItems(ctx context.Context, tagId uint64, itemIds []uint64) error
+}
+
+type ItemsTagsUpdater interface {
+	UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error
+}
+
 type TagReaderWriter interface {
 	TagReader
 	TagWriter
@@ -128,10 +158,76 @@ type DirectoryItemsGetterSetter interface {
 type TaskReader interface {
 	GetTasks(ctx context.Context, offset int, limit int) (*[]Task, error)
 	TasksCount(ctx context.Context, query interface{}, conds ...interface{}) (int64, error)
+	FindTasks(ctx context.Context, conds ...interface{}) (*[]Task, error)
+}
+
+type WatchProgressReader interface {
+	GetWatchProgress(ctx context.Context, itemId uint64) (*WatchProgress, error)
+	GetWatchHistory(ctx context.Context, onlyInProgress bool, offset int, limit int) (*[]WatchProgress, error)
+}
+
+type WatchProgressWriter interface {
+	SaveWatchProgress(ctx context.Context, progress *WatchProgress) error
+	RemoveWatchProgress(ctx context.Context, itemId uint64) error
+}
+
+type WatchProgressReaderWriter interface {
+	WatchProgressReader
+	WatchProgressWriter
+}
+
+type VideoHashReader interface {
+	GetVideoHashes(ctx context.Context) (*[]VideoHash, error)
+}
+
+type VideoHashWriter interface {
+	SaveVideoHash(ctx context.Context, hash *VideoHash) error
+}
+
+type TrashReader interface {
+	GetDeletedItems(ctx context.Context, conds ...interface{}) (*[]Item, error)
+	GetDeletedTags(ctx context.Context, conds ...interface{}) (*[]Tag, error)
+}
+
+type TrashWriter interface {
+	RestoreItem(ctx context.Context, itemId uint64) error
+	RestoreTag(ctx context.Context, tagId uint64) error
+	PurgeItem(ctx context.Context, itemId uint64) error
+	PurgeTag(ctx context.Context, tagId uint64) error
+}
+
+type TrashReaderWriter interface {
+	TrashReader
+	TrashWriter
+}
+
+type StorageRemover interface {
+	Remove(name string) error
+}
+
+// ItemMetadataImporter fills a new item from metadata found next to its file, before it's saved
+type ItemMetadataImporter interface {
+	ImportItemMetadata(ctx context.Context, item *Item) error
+}
+
+type SnapshotReader interface {
+	GetSnapshot(ctx context.Context) (*Snapshot, error)
+}
+
+type SnapshotRestorer interface {
+	RestoreSnapshot(ctx context.Context, snapshot *Snapshot, policy ConflictPolicy, relocator StorageRelocator) (*RestoreResult, error)
+}
+
+// StorageRelocator rewrites the storage urls of a restored entity that got a new id
+type StorageRelocator interface {
+	RelocateItemUrl(url string, oldId uint64, newId uint64) string
+	RelocateTagUrl(url string, oldId uint64, newId uint64) string
+	RelocateTagImageTypeUrl(url string, oldId uint64, newId uint64) string
 }
 
 type ProcessorStatus interface {
 	IsPaused() bool
+	PoolsMetadata() []PoolMetadata
 }
 
 type DirectoryAutoTagsGetter interface {
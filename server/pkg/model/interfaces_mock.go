@@ -176,6 +176,226 @@ func (mr *MockItemWriterMockRecorder) UpdateItem(ctx, item any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockItemWriter)(nil).UpdateItem), ctx, item)
 }
 
+// MockItemPreferencesWriter is a mock of ItemPreferencesWriter interface.
+type MockItemPreferencesWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemPreferencesWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockItemPreferencesWriterMockRecorder is the mock recorder for MockItemPreferencesWriter.
+type MockItemPreferencesWriterMockRecorder struct {
+	mock *MockItemPreferencesWriter
+}
+
+// NewMockItemPreferencesWriter creates a new mock instance.
+func NewMockItemPreferencesWriter(ctrl *gomock.Controller) *MockItemPreferencesWriter {
+	mock := &MockItemPreferencesWriter{ctrl: ctrl}
+	mock.recorder = &MockItemPreferencesWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemPreferencesWriter) EXPECT() *MockItemPreferencesWriterMockRecorder {
+	return m.recorder
+}
+
+// SetItemFavorite mocks base method.
+func (m *MockItemPreferencesWriter) SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetItemFavorite", ctx, itemId, favorite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetItemFavorite indicates an expected call of SetItemFavorite.
+func (mr *MockItemPreferencesWriterMockRecorder) SetItemFavorite(ctx, itemId, favorite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetItemFavorite", reflect.TypeOf((*MockItemPreferencesWriter)(nil).SetItemFavorite), ctx, itemId, favorite)
+}
+
+// SetItemRating mocks base method.
+func (m *MockItemPreferencesWriter) SetItemRating(ctx context.Context, itemId uint64, rating int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetItemRating", ctx, itemId, rating)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetItemRating indicates an expected call of SetItemRating.
+func (mr *MockItemPreferencesWriterMockRecorder) SetItemRating(ctx, itemId, rating any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetItemRating", reflect.TypeOf((*MockItemPreferencesWriter)(nil).SetItemRating), ctx, itemId, rating)
+}
+
+// MockItemMetadataWriter is a mock of ItemMetadataWriter interface.
+type MockItemMetadataWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemMetadataWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockItemMetadataWriterMockRecorder is the mock recorder for MockItemMetadataWriter.
+type MockItemMetadataWriterMockRecorder struct {
+	mock *MockItemMetadataWriter
+}
+
+// NewMockItemMetadataWriter creates a new mock instance.
+func NewMockItemMetadataWriter(ctrl *gomock.Controller) *MockItemMetadataWriter {
+	mock := &MockItemMetadataWriter{ctrl: ctrl}
+	mock.recorder = &MockItemMetadataWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemMetadataWriter) EXPECT() *MockItemMetadataWriterMockRecorder {
+	return m.recorder
+}
+
+// SetItemMetadata mocks base method.
+func (m *MockItemMetadataWriter) SetItemMetadata(ctx context.Context, itemId uint64, metadata *ItemMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetItemMetadata", ctx, itemId, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetItemMetadata indicates an expected call of SetItemMetadata.
+func (mr *MockItemMetadataWriterMockRecorder) SetItemMetadata(ctx, itemId, metadata any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetItemMetadata", reflect.TypeOf((*MockItemMetadataWriter)(nil).SetItemMetadata), ctx, itemId, metadata)
+}
+
+// MockItemsQuerier is a mock of ItemsQuerier interface.
+type MockItemsQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemsQuerierMockRecorder
+	isgomock struct{}
+}
+
+// MockItemsQuerierMockRecorder is the mock recorder for MockItemsQuerier.
+type MockItemsQuerierMockRecorder struct {
+	mock *MockItemsQuerier
+}
+
+// NewMockItemsQuerier creates a new mock instance.
+func NewMockItemsQuerier(ctrl *gomock.Controller) *MockItemsQuerier {
+	mock := &MockItemsQuerier{ctrl: ctrl}
+	mock.recorder = &MockItemsQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemsQuerier) EXPECT() *MockItemsQuerierMockRecorder {
+	return m.recorder
+}
+
+// QueryItems mocks base method.
+func (m *MockItemsQuerier) QueryItems(ctx context.Context, query *ItemsQuery) (*ItemsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryItems", ctx, query)
+	ret0, _ := ret[0].(*ItemsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryItems indicates an expected call of QueryItems.
+func (mr *MockItemsQuerierMockRecorder) QueryItems(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryItems", reflect.TypeOf((*MockItemsQuerier)(nil).QueryItems), ctx, query)
+}
+
+// MockSearcher is a mock of Searcher interface.
+type MockSearcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearcherMockRecorder
+	isgomock struct{}
+}
+
+// MockSearcherMockRecorder is the mock recorder for MockSearcher.
+type MockSearcherMockRecorder struct {
+	mock *MockSearcher
+}
+
+// NewMockSearcher creates a new mock instance.
+func NewMockSearcher(ctrl *gomock.Controller) *MockSearcher {
+	mock := &MockSearcher{ctrl: ctrl}
+	mock.recorder = &MockSearcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearcher) EXPECT() *MockSearcherMockRecorder {
+	return m.recorder
+}
+
+// Search mocks base method.
+func (m *MockSearcher) Search(ctx context.Context, query *SearchQuery) (*SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].(*SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockSearcherMockRecorder) Search(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSearcher)(nil).Search), ctx, query)
+}
+
+// MockSubtitlesIndexer is a mock of SubtitlesIndexer interface.
+type MockSubtitlesIndexer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubtitlesIndexerMockRecorder
+	isgomock struct{}
+}
+
+// MockSubtitlesIndexerMockRecorder is the mock recorder for MockSubtitlesIndexer.
+type MockSubtitlesIndexerMockRecorder struct {
+	mock *MockSubtitlesIndexer
+}
+
+// NewMockSubtitlesIndexer creates a new mock instance.
+func NewMockSubtitlesIndexer(ctrl *gomock.Controller) *MockSubtitlesIndexer {
+	mock := &MockSubtitlesIndexer{ctrl: ctrl}
+	mock.recorder = &MockSubtitlesIndexerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubtitlesIndexer) EXPECT() *MockSubtitlesIndexerMockRecorder {
+	return m.recorder
+}
+
+// IndexSubtitle mocks base method.
+func (m *MockSubtitlesIndexer) IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle Subtitle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexSubtitle", ctx, itemId, url, subtitle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexSubtitle indicates an expected call of IndexSubtitle.
+func (mr *MockSubtitlesIndexerMockRecorder) IndexSubtitle(ctx, itemId, url, subtitle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexSubtitle", reflect.TypeOf((*MockSubtitlesIndexer)(nil).IndexSubtitle), ctx, itemId, url, subtitle)
+}
+
+// RemoveSubtitleIndex mocks base method.
+func (m *MockSubtitlesIndexer) RemoveSubtitleIndex(ctx context.Context, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSubtitleIndex", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSubtitleIndex indicates an expected call of RemoveSubtitleIndex.
+func (mr *MockSubtitlesIndexerMockRecorder) RemoveSubtitleIndex(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSubtitleIndex", reflect.TypeOf((*MockSubtitlesIndexer)(nil).RemoveSubtitleIndex), ctx, url)
+}
+
 // MockItemReaderWriter is a mock of ItemReaderWriter interface.
 type MockItemReaderWriter struct {
 	ctrl     *gomock.Controller
@@ -490,6 +710,82 @@ func (mr *MockTagWriterMockRecorder) UpdateTag(ctx, tag any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockTagWriter)(nil).UpdateTag), ctx, tag)
 }
 
+// MockTagItemsSetter is a mock of TagItemsSetter interface.
+type MockTagItemsSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagItemsSetterMockRecorder
+	isgomock struct{}
+}
+
+// MockTagItemsSetterMockRecorder is the mock recorder for MockTagItemsSetter.
+type MockTagItemsSetterMockRecorder struct {
+	mock *MockTagItemsSetter
+}
+
+// NewMockTagItemsSetter creates a new mock instance.
+func NewMockTagItemsSetter(ctrl *gomock.Controller) *MockTagItemsSetter {
+	mock := &MockTagItemsSetter{ctrl: ctrl}
+	mock.recorder = &MockTagItemsSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagItemsSetter) EXPECT() *MockTagItemsSetterMockRecorder {
+	return m.recorder
+}
+
+// SetTagItems mocks base method.
+func (m *MockTagItemsSetter) SetTagItems(ctx context.Context, tagId uint64, itemIds []uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTagItems", ctx, tagId, itemIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTagItems indicates an expected call of SetTagItems.
+func (mr *MockTagItemsSetterMockRecorder) SetTagItems(ctx, tagId, itemIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTagItems", reflect.TypeOf((*MockTagItemsSetter)(nil).SetTagItems), ctx, tagId, itemIds)
+}
+
+// MockItemsTagsUpdater is a mock of ItemsTagsUpdater interface.
+type MockItemsTagsUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemsTagsUpdaterMockRecorder
+	isgomock struct{}
+}
+
+// MockItemsTagsUpdaterMockRecorder is the mock recorder for MockItemsTagsUpdater.
+type MockItemsTagsUpdaterMockRecorder struct {
+	mock *MockItemsTagsUpdater
+}
+
+// NewMockItemsTagsUpdater creates a new mock instance.
+func NewMockItemsTagsUpdater(ctrl *gomock.Controller) *MockItemsTagsUpdater {
+	mock := &MockItemsTagsUpdater{ctrl: ctrl}
+	mock.recorder = &MockItemsTagsUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemsTagsUpdater) EXPECT() *MockItemsTagsUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateItemsTags mocks base method.
+func (m *MockItemsTagsUpdater) UpdateItemsTags(ctx context.Context, itemIds, addedTagIds, removedTagIds []uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItemsTags", ctx, itemIds, addedTagIds, removedTagIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateItemsTags indicates an expected call of UpdateItemsTags.
+func (mr *MockItemsTagsUpdaterMockRecorder) UpdateItemsTags(ctx, itemIds, addedTagIds, removedTagIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItemsTags", reflect.TypeOf((*MockItemsTagsUpdater)(nil).UpdateItemsTags), ctx, itemIds, addedTagIds, removedTagIds)
+}
+
 // MockTagReaderWriter is a mock of TagReaderWriter interface.
 type MockTagReaderWriter struct {
 	ctrl     *gomock.Controller
@@ -1714,6 +2010,26 @@ func (m *MockTaskReader) EXPECT() *MockTaskReaderMockRecorder {
 	return m.recorder
 }
 
+// FindTasks mocks base method.
+func (m *MockTaskReader) FindTasks(ctx context.Context, conds ...any) (*[]Task, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindTasks", varargs...)
+	ret0, _ := ret[0].(*[]Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTasks indicates an expected call of FindTasks.
+func (mr *MockTaskReaderMockRecorder) FindTasks(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTasks", reflect.TypeOf((*MockTaskReader)(nil).FindTasks), varargs...)
+}
+
 // GetTasks mocks base method.
 func (m *MockTaskReader) GetTasks(ctx context.Context, offset, limit int) (*[]Task, error) {
 	m.ctrl.T.Helper()
@@ -1749,42 +2065,805 @@ func (mr *MockTaskReaderMockRecorder) TasksCount(ctx, query any, conds ...any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TasksCount", reflect.TypeOf((*MockTaskReader)(nil).TasksCount), varargs...)
 }
 
-// MockProcessorStatus is a mock of ProcessorStatus interface.
-type MockProcessorStatus struct {
+// MockWatchProgressReader is a mock of WatchProgressReader interface.
+type MockWatchProgressReader struct {
 	ctrl     *gomock.Controller
-	recorder *MockProcessorStatusMockRecorder
+	recorder *MockWatchProgressReaderMockRecorder
 	isgomock struct{}
 }
 
-// MockProcessorStatusMockRecorder is the mock recorder for MockProcessorStatus.
-type MockProcessorStatusMockRecorder struct {
-	mock *MockProcessorStatus
+// MockWatchProgressReaderMockRecorder is the mock recorder for MockWatchProgressReader.
+type MockWatchProgressReaderMockRecorder struct {
+	mock *MockWatchProgressReader
 }
 
-// NewMockProcessorStatus creates a new mock instance.
-func NewMockProcessorStatus(ctrl *gomock.Controller) *MockProcessorStatus {
-	mock := &MockProcessorStatus{ctrl: ctrl}
-	mock.recorder = &MockProcessorStatusMockRecorder{mock}
+// NewMockWatchProgressReader creates a new mock instance.
+func NewMockWatchProgressReader(ctrl *gomock.Controller) *MockWatchProgressReader {
+	mock := &MockWatchProgressReader{ctrl: ctrl}
+	mock.recorder = &MockWatchProgressReaderMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockProcessorStatus) EXPECT() *MockProcessorStatusMockRecorder {
+func (m *MockWatchProgressReader) EXPECT() *MockWatchProgressReaderMockRecorder {
 	return m.recorder
 }
 
-// IsPaused mocks base method.
-func (m *MockProcessorStatus) IsPaused() bool {
+// GetWatchHistory mocks base method.
+func (m *MockWatchProgressReader) GetWatchHistory(ctx context.Context, onlyInProgress bool, offset, limit int) (*[]WatchProgress, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsPaused")
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetWatchHistory", ctx, onlyInProgress, offset, limit)
+	ret0, _ := ret[0].(*[]WatchProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatchHistory indicates an expected call of GetWatchHistory.
+func (mr *MockWatchProgressReaderMockRecorder) GetWatchHistory(ctx, onlyInProgress, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatchHistory", reflect.TypeOf((*MockWatchProgressReader)(nil).GetWatchHistory), ctx, onlyInProgress, offset, limit)
+}
+
+// GetWatchProgress mocks base method.
+func (m *MockWatchProgressReader) GetWatchProgress(ctx context.Context, itemId uint64) (*WatchProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWatchProgress", ctx, itemId)
+	ret0, _ := ret[0].(*WatchProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatchProgress indicates an expected call of GetWatchProgress.
+func (mr *MockWatchProgressReaderMockRecorder) GetWatchProgress(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatchProgress", reflect.TypeOf((*MockWatchProgressReader)(nil).GetWatchProgress), ctx, itemId)
+}
+
+// MockWatchProgressWriter is a mock of WatchProgressWriter interface.
+type MockWatchProgressWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchProgressWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockWatchProgressWriterMockRecorder is the mock recorder for MockWatchProgressWriter.
+type MockWatchProgressWriterMockRecorder struct {
+	mock *MockWatchProgressWriter
+}
+
+// NewMockWatchProgressWriter creates a new mock instance.
+func NewMockWatchProgressWriter(ctrl *gomock.Controller) *MockWatchProgressWriter {
+	mock := &MockWatchProgressWriter{ctrl: ctrl}
+	mock.recorder = &MockWatchProgressWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchProgressWriter) EXPECT() *MockWatchProgressWriterMockRecorder {
+	return m.recorder
+}
+
+// RemoveWatchProgress mocks base method.
+func (m *MockWatchProgressWriter) RemoveWatchProgress(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWatchProgress", ctx, itemId)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IsPaused indicates an expected call of IsPaused.
-func (mr *MockProcessorStatusMockRecorder) IsPaused() *gomock.Call {
+// RemoveWatchProgress indicates an expected call of RemoveWatchProgress.
+func (mr *MockWatchProgressWriterMockRecorder) RemoveWatchProgress(ctx, itemId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPaused", reflect.TypeOf((*MockProcessorStatus)(nil).IsPaused))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWatchProgress", reflect.TypeOf((*MockWatchProgressWriter)(nil).RemoveWatchProgress), ctx, itemId)
+}
+
+// SaveWatchProgress mocks base method.
+func (m *MockWatchProgressWriter) SaveWatchProgress(ctx context.Context, progress *WatchProgress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWatchProgress", ctx, progress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWatchProgress indicates an expected call of SaveWatchProgress.
+func (mr *MockWatchProgressWriterMockRecorder) SaveWatchProgress(ctx, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWatchProgress", reflect.TypeOf((*MockWatchProgressWriter)(nil).SaveWatchProgress), ctx, progress)
+}
+
+// MockWatchProgressReaderWriter is a mock of WatchProgressReaderWriter interface.
+type MockWatchProgressReaderWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWatchProgressReaderWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockWatchProgressReaderWriterMockRecorder is the mock recorder for MockWatchProgressReaderWriter.
+type MockWatchProgressReaderWriterMockRecorder struct {
+	mock *MockWatchProgressReaderWriter
+}
+
+// NewMockWatchProgressReaderWriter creates a new mock instance.
+func NewMockWatchProgressReaderWriter(ctrl *gomock.Controller) *MockWatchProgressReaderWriter {
+	mock := &MockWatchProgressReaderWriter{ctrl: ctrl}
+	mock.recorder = &MockWatchProgressReaderWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWatchProgressReaderWriter) EXPECT() *MockWatchProgressReaderWriterMockRecorder {
+	return m.recorder
+}
+
+// GetWatchHistory mocks base method.
+func (m *MockWatchProgressReaderWriter) GetWatchHistory(ctx context.Context, onlyInProgress bool, offset, limit int) (*[]WatchProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWatchHistory", ctx, onlyInProgress, offset, limit)
+	ret0, _ := ret[0].(*[]WatchProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatchHistory indicates an expected call of GetWatchHistory.
+func (mr *MockWatchProgressReaderWriterMockRecorder) GetWatchHistory(ctx, onlyInProgress, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatchHistory", reflect.TypeOf((*MockWatchProgressReaderWriter)(nil).GetWatchHistory), ctx, onlyInProgress, offset, limit)
+}
+
+// GetWatchProgress mocks base method.
+func (m *MockWatchProgressReaderWriter) GetWatchProgress(ctx context.Context, itemId uint64) (*WatchProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWatchProgress", ctx, itemId)
+	ret0, _ := ret[0].(*WatchProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWatchProgress indicates an expected call of GetWatchProgress.
+func (mr *MockWatchProgressReaderWriterMockRecorder) GetWatchProgress(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWatchProgress", reflect.TypeOf((*MockWatchProgressReaderWriter)(nil).GetWatchProgress), ctx, itemId)
+}
+
+// RemoveWatchProgress mocks base method.
+func (m *MockWatchProgressReaderWriter) RemoveWatchProgress(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWatchProgress", ctx, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWatchProgress indicates an expected call of RemoveWatchProgress.
+func (mr *MockWatchProgressReaderWriterMockRecorder) RemoveWatchProgress(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWatchProgress", reflect.TypeOf((*MockWatchProgressReaderWriter)(nil).RemoveWatchProgress), ctx, itemId)
+}
+
+// SaveWatchProgress mocks base method.
+func (m *MockWatchProgressReaderWriter) SaveWatchProgress(ctx context.Context, progress *WatchProgress) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWatchProgress", ctx, progress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWatchProgress indicates an expected call of SaveWatchProgress.
+func (mr *MockWatchProgressReaderWriterMockRecorder) SaveWatchProgress(ctx, progress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWatchProgress", reflect.TypeOf((*MockWatchProgressReaderWriter)(nil).SaveWatchProgress), ctx, progress)
+}
+
+// MockVideoHashReader is a mock of VideoHashReader interface.
+type MockVideoHashReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockVideoHashReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockVideoHashReaderMockRecorder is the mock recorder for MockVideoHashReader.
+type MockVideoHashReaderMockRecorder struct {
+	mock *MockVideoHashReader
+}
+
+// NewMockVideoHashReader creates a new mock instance.
+func NewMockVideoHashReader(ctrl *gomock.Controller) *MockVideoHashReader {
+	mock := &MockVideoHashReader{ctrl: ctrl}
+	mock.recorder = &MockVideoHashReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVideoHashReader) EXPECT() *MockVideoHashReaderMockRecorder {
+	return m.recorder
+}
+
+// GetVideoHashes mocks base method.
+func (m *MockVideoHashReader) GetVideoHashes(ctx context.Context) (*[]VideoHash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVideoHashes", ctx)
+	ret0, _ := ret[0].(*[]VideoHash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVideoHashes indicates an expected call of GetVideoHashes.
+func (mr *MockVideoHashReaderMockRecorder) GetVideoHashes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVideoHashes", reflect.TypeOf((*MockVideoHashReader)(nil).GetVideoHashes), ctx)
+}
+
+// MockVideoHashWriter is a mock of VideoHashWriter interface.
+type MockVideoHashWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVideoHashWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockVideoHashWriterMockRecorder is the mock recorder for MockVideoHashWriter.
+type MockVideoHashWriterMockRecorder struct {
+	mock *MockVideoHashWriter
+}
+
+// NewMockVideoHashWriter creates a new mock instance.
+func NewMockVideoHashWriter(ctrl *gomock.Controller) *MockVideoHashWriter {
+	mock := &MockVideoHashWriter{ctrl: ctrl}
+	mock.recorder = &MockVideoHashWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVideoHashWriter) EXPECT() *MockVideoHashWriterMockRecorder {
+	return m.recorder
+}
+
+// SaveVideoHash mocks base method.
+func (m *MockVideoHashWriter) SaveVideoHash(ctx context.Context, hash *VideoHash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVideoHash", ctx, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVideoHash indicates an expected call of SaveVideoHash.
+func (mr *MockVideoHashWriterMockRecorder) SaveVideoHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVideoHash", reflect.TypeOf((*MockVideoHashWriter)(nil).SaveVideoHash), ctx, hash)
+}
+
+// MockTrashReader is a mock of TrashReader interface.
+type MockTrashReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockTrashReaderMockRecorder is the mock recorder for MockTrashReader.
+type MockTrashReaderMockRecorder struct {
+	mock *MockTrashReader
+}
+
+// NewMockTrashReader creates a new mock instance.
+func NewMockTrashReader(ctrl *gomock.Controller) *MockTrashReader {
+	mock := &MockTrashReader{ctrl: ctrl}
+	mock.recorder = &MockTrashReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrashReader) EXPECT() *MockTrashReaderMockRecorder {
+	return m.recorder
+}
+
+// GetDeletedItems mocks base method.
+func (m *MockTrashReader) GetDeletedItems(ctx context.Context, conds ...any) (*[]Item, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetDeletedItems", varargs...)
+	ret0, _ := ret[0].(*[]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedItems indicates an expected call of GetDeletedItems.
+func (mr *MockTrashReaderMockRecorder) GetDeletedItems(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedItems", reflect.TypeOf((*MockTrashReader)(nil).GetDeletedItems), varargs...)
+}
+
+// GetDeletedTags mocks base method.
+func (m *MockTrashReader) GetDeletedTags(ctx context.Context, conds ...any) (*[]Tag, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetDeletedTags", varargs...)
+	ret0, _ := ret[0].(*[]Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedTags indicates an expected call of GetDeletedTags.
+func (mr *MockTrashReaderMockRecorder) GetDeletedTags(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedTags", reflect.TypeOf((*MockTrashReader)(nil).GetDeletedTags), varargs...)
+}
+
+// MockTrashWriter is a mock of TrashWriter interface.
+type MockTrashWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockTrashWriterMockRecorder is the mock recorder for MockTrashWriter.
+type MockTrashWriterMockRecorder struct {
+	mock *MockTrashWriter
+}
+
+// NewMockTrashWriter creates a new mock instance.
+func NewMockTrashWriter(ctrl *gomock.Controller) *MockTrashWriter {
+	mock := &MockTrashWriter{ctrl: ctrl}
+	mock.recorder = &MockTrashWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrashWriter) EXPECT() *MockTrashWriterMockRecorder {
+	return m.recorder
+}
+
+// PurgeItem mocks base method.
+func (m *MockTrashWriter) PurgeItem(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeItem", ctx, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeItem indicates an expected call of PurgeItem.
+func (mr *MockTrashWriterMockRecorder) PurgeItem(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeItem", reflect.TypeOf((*MockTrashWriter)(nil).PurgeItem), ctx, itemId)
+}
+
+// PurgeTag mocks base method.
+func (m *MockTrashWriter) PurgeTag(ctx context.Context, tagId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTag", ctx, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTag indicates an expected call of PurgeTag.
+func (mr *MockTrashWriterMockRecorder) PurgeTag(ctx, tagId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTag", reflect.TypeOf((*MockTrashWriter)(nil).PurgeTag), ctx, tagId)
+}
+
+// RestoreItem mocks base method.
+func (m *MockTrashWriter) RestoreItem(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItem", ctx, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreItem indicates an expected call of RestoreItem.
+func (mr *MockTrashWriterMockRecorder) RestoreItem(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItem", reflect.TypeOf((*MockTrashWriter)(nil).RestoreItem), ctx, itemId)
+}
+
+// RestoreTag mocks base method.
+func (m *MockTrashWriter) RestoreTag(ctx context.Context, tagId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTag", ctx, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTag indicates an expected call of RestoreTag.
+func (mr *MockTrashWriterMockRecorder) RestoreTag(ctx, tagId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTag", reflect.TypeOf((*MockTrashWriter)(nil).RestoreTag), ctx, tagId)
+}
+
+// MockTrashReaderWriter is a mock of TrashReaderWriter interface.
+type MockTrashReaderWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashReaderWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockTrashReaderWriterMockRecorder is the mock recorder for MockTrashReaderWriter.
+type MockTrashReaderWriterMockRecorder struct {
+	mock *MockTrashReaderWriter
+}
+
+// NewMockTrashReaderWriter creates a new mock instance.
+func NewMockTrashReaderWriter(ctrl *gomock.Controller) *MockTrashReaderWriter {
+	mock := &MockTrashReaderWriter{ctrl: ctrl}
+	mock.recorder = &MockTrashReaderWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrashReaderWriter) EXPECT() *MockTrashReaderWriterMockRecorder {
+	return m.recorder
+}
+
+// GetDeletedItems mocks base method.
+func (m *MockTrashReaderWriter) GetDeletedItems(ctx context.Context, conds ...any) (*[]Item, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetDeletedItems", varargs...)
+	ret0, _ := ret[0].(*[]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedItems indicates an expected call of GetDeletedItems.
+func (mr *MockTrashReaderWriterMockRecorder) GetDeletedItems(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedItems", reflect.TypeOf((*MockTrashReaderWriter)(nil).GetDeletedItems), varargs...)
+}
+
+// GetDeletedTags mocks base method.
+func (m *MockTrashReaderWriter) GetDeletedTags(ctx context.Context, conds ...any) (*[]Tag, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetDeletedTags", varargs...)
+	ret0, _ := ret[0].(*[]Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedTags indicates an expected call of GetDeletedTags.
+func (mr *MockTrashReaderWriterMockRecorder) GetDeletedTags(ctx any, conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, conds...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedTags", reflect.TypeOf((*MockTrashReaderWriter)(nil).GetDeletedTags), varargs...)
+}
+
+// PurgeItem mocks base method.
+func (m *MockTrashReaderWriter) PurgeItem(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeItem", ctx, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeItem indicates an expected call of PurgeItem.
+func (mr *MockTrashReaderWriterMockRecorder) PurgeItem(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeItem", reflect.TypeOf((*MockTrashReaderWriter)(nil).PurgeItem), ctx, itemId)
+}
+
+// PurgeTag mocks base method.
+func (m *MockTrashReaderWriter) PurgeTag(ctx context.Context, tagId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTag", ctx, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTag indicates an expected call of PurgeTag.
+func (mr *MockTrashReaderWriterMockRecorder) PurgeTag(ctx, tagId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTag", reflect.TypeOf((*MockTrashReaderWriter)(nil).PurgeTag), ctx, tagId)
+}
+
+// RestoreItem mocks base method.
+func (m *MockTrashReaderWriter) RestoreItem(ctx context.Context, itemId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItem", ctx, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreItem indicates an expected call of RestoreItem.
+func (mr *MockTrashReaderWriterMockRecorder) RestoreItem(ctx, itemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItem", reflect.TypeOf((*MockTrashReaderWriter)(nil).RestoreItem), ctx, itemId)
+}
+
+// RestoreTag mocks base method.
+func (m *MockTrashReaderWriter) RestoreTag(ctx context.Context, tagId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTag", ctx, tagId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTag indicates an expected call of RestoreTag.
+func (mr *MockTrashReaderWriterMockRecorder) RestoreTag(ctx, tagId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTag", reflect.TypeOf((*MockTrashReaderWriter)(nil).RestoreTag), ctx, tagId)
+}
+
+// MockStorageRemover is a mock of StorageRemover interface.
+type MockStorageRemover struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageRemoverMockRecorder
+	isgomock struct{}
+}
+
+// MockStorageRemoverMockRecorder is the mock recorder for MockStorageRemover.
+type MockStorageRemoverMockRecorder struct {
+	mock *MockStorageRemover
+}
+
+// NewMockStorageRemover creates a new mock instance.
+func NewMockStorageRemover(ctrl *gomock.Controller) *MockStorageRemover {
+	mock := &MockStorageRemover{ctrl: ctrl}
+	mock.recorder = &MockStorageRemoverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorageRemover) EXPECT() *MockStorageRemoverMockRecorder {
+	return m.recorder
+}
+
+// Remove mocks base method.
+func (m *MockStorageRemover) Remove(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockStorageRemoverMockRecorder) Remove(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockStorageRemover)(nil).Remove), name)
+}
+
+// MockItemMetadataImporter is a mock of ItemMetadataImporter interface.
+type MockItemMetadataImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemMetadataImporterMockRecorder
+	isgomock struct{}
+}
+
+// MockItemMetadataImporterMockRecorder is the mock recorder for MockItemMetadataImporter.
+type MockItemMetadataImporterMockRecorder struct {
+	mock *MockItemMetadataImporter
+}
+
+// NewMockItemMetadataImporter creates a new mock instance.
+func NewMockItemMetadataImporter(ctrl *gomock.Controller) *MockItemMetadataImporter {
+	mock := &MockItemMetadataImporter{ctrl: ctrl}
+	mock.recorder = &MockItemMetadataImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemMetadataImporter) EXPECT() *MockItemMetadataImporterMockRecorder {
+	return m.recorder
+}
+
+// ImportItemMetadata mocks base method.
+func (m *MockItemMetadataImporter) ImportItemMetadata(ctx context.Context, item *Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportItemMetadata", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportItemMetadata indicates an expected call of ImportItemMetadata.
+func (mr *MockItemMetadataImporterMockRecorder) ImportItemMetadata(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportItemMetadata", reflect.TypeOf((*MockItemMetadataImporter)(nil).ImportItemMetadata), ctx, item)
+}
+
+// MockSnapshotReader is a mock of SnapshotReader interface.
+type MockSnapshotReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockSnapshotReaderMockRecorder is the mock recorder for MockSnapshotReader.
+type MockSnapshotReaderMockRecorder struct {
+	mock *MockSnapshotReader
+}
+
+// NewMockSnapshotReader creates a new mock instance.
+func NewMockSnapshotReader(ctrl *gomock.Controller) *MockSnapshotReader {
+	mock := &MockSnapshotReader{ctrl: ctrl}
+	mock.recorder = &MockSnapshotReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotReader) EXPECT() *MockSnapshotReaderMockRecorder {
+	return m.recorder
+}
+
+// GetSnapshot mocks base method.
+func (m *MockSnapshotReader) GetSnapshot(ctx context.Context) (*Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshot", ctx)
+	ret0, _ := ret[0].(*Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshot indicates an expected call of GetSnapshot.
+func (mr *MockSnapshotReaderMockRecorder) GetSnapshot(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshot", reflect.TypeOf((*MockSnapshotReader)(nil).GetSnapshot), ctx)
+}
+
+// MockSnapshotRestorer is a mock of SnapshotRestorer interface.
+type MockSnapshotRestorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotRestorerMockRecorder
+	isgomock struct{}
+}
+
+// MockSnapshotRestorerMockRecorder is the mock recorder for MockSnapshotRestorer.
+type MockSnapshotRestorerMockRecorder struct {
+	mock *MockSnapshotRestorer
+}
+
+// NewMockSnapshotRestorer creates a new mock instance.
+func NewMockSnapshotRestorer(ctrl *gomock.Controller) *MockSnapshotRestorer {
+	mock := &MockSnapshotRestorer{ctrl: ctrl}
+	mock.recorder = &MockSnapshotRestorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotRestorer) EXPECT() *MockSnapshotRestorerMockRecorder {
+	return m.recorder
+}
+
+// RestoreSnapshot mocks base method.
+func (m *MockSnapshotRestorer) RestoreSnapshot(ctx context.Context, snapshot *Snapshot, policy ConflictPolicy, relocator StorageRelocator) (*RestoreResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSnapshot", ctx, snapshot, policy, relocator)
+	ret0, _ := ret[0].(*RestoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSnapshot indicates an expected call of RestoreSnapshot.
+func (mr *MockSnapshotRestorerMockRecorder) RestoreSnapshot(ctx, snapshot, policy, relocator any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSnapshot", reflect.TypeOf((*MockSnapshotRestorer)(nil).RestoreSnapshot), ctx, snapshot, policy, relocator)
+}
+
+// MockStorageRelocator is a mock of StorageRelocator interface.
+type MockStorageRelocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageRelocatorMockRecorder
+	isgomock struct{}
+}
+
+// MockStorageRelocatorMockRecorder is the mock recorder for MockStorageRelocator.
+type MockStorageRelocatorMockRecorder struct {
+	mock *MockStorageRelocator
+}
+
+// NewMockStorageRelocator creates a new mock instance.
+func NewMockStorageRelocator(ctrl *gomock.Controller) *MockStorageRelocator {
+	mock := &MockStorageRelocator{ctrl: ctrl}
+	mock.recorder = &MockStorageRelocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorageRelocator) EXPECT() *MockStorageRelocatorMockRecorder {
+	return m.recorder
+}
+
+// RelocateItemUrl mocks base method.
+func (m *MockStorageRelocator) RelocateItemUrl(url string, oldId, newId uint64) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelocateItemUrl", url, oldId, newId)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RelocateItemUrl indicates an expected call of RelocateItemUrl.
+func (mr *MockStorageRelocatorMockRecorder) RelocateItemUrl(url, oldId, newId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelocateItemUrl", reflect.TypeOf((*MockStorageRelocator)(nil).RelocateItemUrl), url, oldId, newId)
+}
+
+// RelocateTagImageTypeUrl mocks base method.
+func (m *MockStorageRelocator) RelocateTagImageTypeUrl(url string, oldId, newId uint64) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelocateTagImageTypeUrl", url, oldId, newId)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RelocateTagImageTypeUrl indicates an expected call of RelocateTagImageTypeUrl.
+func (mr *MockStorageRelocatorMockRecorder) RelocateTagImageTypeUrl(url, oldId, newId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelocateTagImageTypeUrl", reflect.TypeOf((*MockStorageRelocator)(nil).RelocateTagImageTypeUrl), url, oldId, newId)
+}
+
+// RelocateTagUrl mocks base method.
+func (m *MockStorageRelocator) RelocateTagUrl(url string, oldId, newId uint64) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelocateTagUrl", url, oldId, newId)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RelocateTagUrl indicates an expected call of RelocateTagUrl.
+func (mr *MockStorageRelocatorMockRecorder) RelocateTagUrl(url, oldId, newId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelocateTagUrl", reflect.TypeOf((*MockStorageRelocator)(nil).RelocateTagUrl), url, oldId, newId)
+}
+
+// MockProcessorStatus is a mock of ProcessorStatus interface.
+type MockProcessorStatus struct {
+	ctrl     *gomock.Controller
+	recorder *MockProcessorStatusMockRecorder
+	isgomock struct{}
+}
+
+// MockProcessorStatusMockRecorder is the mock recorder for MockProcessorStatus.
+type MockProcessorStatusMockRecorder struct {
+	mock *MockProcessorStatus
+}
+
+// NewMockProcessorStatus creates a new mock instance.
+func NewMockProcessorStatus(ctrl *gomock.Controller) *MockProcessorStatus {
+	mock := &MockProcessorStatus{ctrl: ctrl}
+	mock.recorder = &MockProcessorStatusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProcessorStatus) EXPECT() *MockProcessorStatusMockRecorder {
+	return m.recorder
+}
+
+// IsPaused mocks base method.
+func (m *MockProcessorStatus) IsPaused() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPaused")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsPaused indicates an expected call of IsPaused.
+func (mr *MockProcessorStatusMockRecorder) IsPaused() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPaused", reflect.TypeOf((*MockProcessorStatus)(nil).IsPaused))
+}
+
+// PoolsMetadata mocks base method.
+func (m *MockProcessorStatus) PoolsMetadata() []PoolMetadata {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PoolsMetadata")
+	ret0, _ := ret[0].([]PoolMetadata)
+	return ret0
+}
+
+// PoolsMetadata indicates an expected call of PoolsMetadata.
+func (mr *MockProcessorStatusMockRecorder) PoolsMetadata() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PoolsMetadata", reflect.TypeOf((*MockProcessorStatus)(nil).PoolsMetadata))
 }
 
 // MockDirectoryAutoTagsGetter is a mock of DirectoryAutoTagsGetter interface.
@@ -27,6 +27,7 @@ const (
 	SET_MAIN_COVER
 	CROP_FRAME
 	CHANGE_RESOLUTION
+	VIDEO_HASH_TASK
 )
 
 func (t TaskType) ToDescription(title string) string {
@@ -43,6 +44,8 @@ func (t TaskType) ToDescription(title string) string {
 		return fmt.Sprintf("Setting main cover for %s", title)
 	case CHANGE_RESOLUTION:
 		return fmt.Sprintf("Changing resolution %s", title)
+	case VIDEO_HASH_TASK:
+		return fmt.Sprintf("Hashing frames of %s", title)
 	default:
 		return "unknown"
 	}
@@ -62,11 +65,43 @@ func (t TaskType) String() string {
 		return "main-cover"
 	case CHANGE_RESOLUTION:
 		return "change-resolution"
+	case VIDEO_HASH_TASK:
+		return "video-hash"
 	default:
 		return "unknown"
 	}
 }
 
+// TaskPriority orders the queued tasks of a pool, normal is the zero value of tasks queued
+// before priorities existed
+type TaskPriority int
+
+const (
+	TASK_PRIORITY_LOW    TaskPriority = -1 // bulk and background jobs
+	TASK_PRIORITY_NORMAL TaskPriority = 0
+	TASK_PRIORITY_HIGH   TaskPriority = 1 // requested interactively for a single item
+)
+
+// TaskPriorities are ordered from the first to run
+var TaskPriorities = []TaskPriority{TASK_PRIORITY_HIGH, TASK_PRIORITY_NORMAL, TASK_PRIORITY_LOW}
+
+func (p TaskPriority) String() string {
+	switch p {
+	case TASK_PRIORITY_LOW:
+		return "low"
+	case TASK_PRIORITY_NORMAL:
+		return "normal"
+	case TASK_PRIORITY_HIGH:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+func (p TaskPriority) IsValid() bool {
+	return p >= TASK_PRIORITY_LOW && p <= TASK_PRIORITY_HIGH
+}
+
 type PushMessageType int
 
 const (
@@ -74,6 +109,7 @@ const (
 	PUSH_PING           = 1
 	PUSH_QUEUE_METADATA = 2
 	PUSH_FS_CHANGE      = 3
+	PUSH_FS_SYNC_HELD   = 4
 )
 
 type Rect struct {
@@ -96,6 +132,43 @@ func (t *Rect) Scan(value interface{}) error {
 	return json.Unmarshal(b, &t)
 }
 
+// ExternalIds maps a provider, EXTERNAL_ID_IMDB or EXTERNAL_ID_TMDB, to the id of the item there
+type ExternalIds map[string]string
+
+const (
+	EXTERNAL_ID_IMDB = "imdb"
+	EXTERNAL_ID_TMDB = "tmdb"
+)
+
+type ExternalId struct {
+	Provider string `json:"provider"`
+	Id       string `json:"id"`
+}
+
+func (t ExternalIds) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+
+	// stored as text, newer sqlite versions read json blobs as its binary jsonb format
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+func (t *ExternalIds) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return errors.Errorf("type assertion to []byte failed %v", value)
+	}
+}
+
 type RectFloat struct {
 	X float64 `json:"x"`
 	Y float64 `json:"y"`
@@ -106,3 +179,59 @@ type RectFloat struct {
 func (t RectFloat) String() string {
 	return fmt.Sprintf("%f:%f %f:%f", t.X, t.Y, t.W, t.H)
 }
+
+type ItemsSortKey string
+
+const (
+	SORT_BY_ID            ItemsSortKey = ""
+	SORT_BY_TITLE         ItemsSortKey = "title"
+	SORT_BY_DURATION      ItemsSortKey = "duration"
+	SORT_BY_FILE_SIZE     ItemsSortKey = "size"
+	SORT_BY_LAST_MODIFIED ItemsSortKey = "modified"
+	SORT_BY_RESOLUTION    ItemsSortKey = "resolution"
+	SORT_BY_RATING        ItemsSortKey = "rating"
+)
+
+type ItemKind string
+
+const (
+	ITEM_KIND_ANY       ItemKind = ""
+	ITEM_KIND_REGULAR   ItemKind = "regular"
+	ITEM_KIND_SUB_ITEM  ItemKind = "sub-item"
+	ITEM_KIND_HIGHLIGHT ItemKind = "highlight"
+)
+
+type SearchSource string
+
+const (
+	SEARCH_SOURCE_ITEM     SearchSource = "item"
+	SEARCH_SOURCE_SUBTITLE SearchSource = "subtitle"
+)
+
+type SuggestionReasonKind string
+
+const (
+	SUGGESTION_REASON_TAG        SuggestionReasonKind = "tag"
+	SUGGESTION_REASON_DIRECTORY  SuggestionReasonKind = "directory"
+	SUGGESTION_REASON_DURATION   SuggestionReasonKind = "duration"
+	SUGGESTION_REASON_RESOLUTION SuggestionReasonKind = "resolution"
+)
+
+type BulkOperationType string
+
+const (
+	BULK_ADD_TAGS    BulkOperationType = "add-tags"
+	BULK_REMOVE_TAGS BulkOperationType = "remove-tags"
+	BULK_DELETE      BulkOperationType = "delete"
+	BULK_COVERS      BulkOperationType = "covers"
+	BULK_PREVIEW     BulkOperationType = "preview"
+	BULK_METADATA    BulkOperationType = "metadata"
+	BULK_OPTIMIZE    BulkOperationType = "optimize"
+)
+
+type ConflictPolicy string
+
+const (
+	CONFLICT_SKIP      ConflictPolicy = "skip"      // keep the existing entity, only its missing associations are added
+	CONFLICT_OVERWRITE ConflictPolicy = "overwrite" // replace the existing entity fields, covers, images and commands
+)
@@ -0,0 +1,331 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"io"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Nfo is the Kodi sidecar format, also read and written by Jellyfin, Emby and tinyMediaManager
+// https://kodi.wiki/view/NFO_files/Movies
+type Nfo struct {
+	XMLName       xml.Name   // the root element, movie unless read from another kind
+	Title         string     `xml:"title,omitempty"`
+	OriginalTitle string     `xml:"originaltitle,omitempty"`
+	Year          int        `xml:"year,omitempty"`
+	Premiered     string     `xml:"premiered,omitempty"` // yyyy-mm-dd
+	Plot          string     `xml:"plot,omitempty"`
+	UserRating    float64    `xml:"userrating,omitempty"` // 0 to 10
+	Id            string     `xml:"id,omitempty"`         // older files keep the IMDb id here
+	UniqueIds     []UniqueId `xml:"uniqueid"`
+	Genres        []string   `xml:"genre"`
+	Tags          []string   `xml:"tag"`
+	Countries     []string   `xml:"country"`
+	Directors     []string   `xml:"director"`
+	Studios       []string   `xml:"studio"`
+	Actors        []Actor    `xml:"actor"`
+	Other         []Element  `xml:",any"` // elements we don't model, such as fileinfo, written back as they were
+}
+
+// Element is an nfo element kept verbatim
+type Element struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+type UniqueId struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+type Actor struct {
+	Name  string `xml:"name"`
+	Role  string `xml:"role,omitempty"`
+	Order *int   `xml:"order,omitempty"`
+}
+
+// fields that can become tags, see Values
+const (
+	FIELD_GENRE    = "genre"
+	FIELD_ACTOR    = "actor"
+	FIELD_DIRECTOR = "director"
+	FIELD_STUDIO   = "studio"
+	FIELD_COUNTRY  = "country"
+	FIELD_YEAR     = "year"
+	FIELD_TAG      = "tag"
+)
+
+var Fields = []string{FIELD_GENRE, FIELD_ACTOR, FIELD_DIRECTOR, FIELD_STUDIO, FIELD_COUNTRY, FIELD_YEAR, FIELD_TAG}
+
+// Parse reads an nfo of any root element (movie, tvshow, episodedetails, musicvideo), trailing
+// content such as the scraper url some managers append is ignored
+func Parse(r io.Reader) (*Nfo, error) {
+	result := &Nfo{}
+	if err := xml.NewDecoder(r).Decode(result); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	return result, nil
+}
+
+func ParseFile(path string) (*Nfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+func Write(w io.Writer, nfo *Nfo) error {
+	if nfo.XMLName.Local == "" {
+		nfo.XMLName.Local = "movie"
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(nfo); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+// SidecarPath is the nfo of a single video, named after it
+func SidecarPath(videoFile string) string {
+	return strings.TrimSuffix(videoFile, filepath.Ext(videoFile)) + ".nfo"
+}
+
+// Find returns the nfo describing the video, its own sidecar or the movie.nfo of its directory,
+// the movie.nfo only describes the video when it's the single video of the directory
+func Find(videoFile string) (string, bool) {
+	if isRegularFile(SidecarPath(videoFile)) {
+		return SidecarPath(videoFile), true
+	}
+
+	dir := filepath.Dir(videoFile)
+	movieNfo := filepath.Join(dir, "movie.nfo")
+	if !isRegularFile(movieNfo) || countVideos(dir) > 1 {
+		return "", false
+	}
+
+	return movieNfo, true
+}
+
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func countVideos(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && utils.IsVideo(true, entry.Name()) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (n *Nfo) ImdbId() string {
+	for _, id := range n.UniqueIds {
+		if strings.EqualFold(id.Type, "imdb") && id.Value != "" {
+			return strings.TrimSpace(id.Value)
+		}
+	}
+
+	if strings.HasPrefix(n.Id, "tt") {
+		return n.Id
+	}
+
+	return ""
+}
+
+func (n *Nfo) ReleaseYear() int {
+	if n.Year != 0 {
+		return n.Year
+	}
+
+	if len(n.Premiered) >= 4 {
+		if year, err := strconv.Atoi(n.Premiered[:4]); err == nil {
+			return year
+		}
+	}
+
+	return 0
+}
+
+// Values returns the trimmed, non empty values of a field, some managers join multiple values
+// with a slash in a single element
+func (n *Nfo) Values(field string) []string {
+	var raw []string
+	switch field {
+	case FIELD_GENRE:
+		raw = n.Genres
+	case FIELD_ACTOR:
+		for _, actor := range n.Actors {
+			raw = append(raw, actor.Name)
+		}
+	case FIELD_DIRECTOR:
+		raw = n.Directors
+	case FIELD_STUDIO:
+		raw = n.Studios
+	case FIELD_COUNTRY:
+		raw = n.Countries
+	case FIELD_YEAR:
+		if year := n.ReleaseYear(); year != 0 {
+			raw = []string{strconv.Itoa(year)}
+		}
+	case FIELD_TAG:
+		raw = n.Tags
+	}
+
+	result := make([]string, 0, len(raw))
+	seen := make(map[string]bool)
+	for _, value := range raw {
+		for _, part := range strings.Split(value, " / ") {
+			part = strings.TrimSpace(part)
+			if part != "" && !seen[part] {
+				seen[part] = true
+				result = append(result, part)
+			}
+		}
+	}
+
+	return result
+}
+
+// SetValues replaces the values of a field, actors keep the role and order they had
+func (n *Nfo) SetValues(field string, values []string) {
+	switch field {
+	case FIELD_GENRE:
+		n.Genres = values
+	case FIELD_ACTOR:
+		existing := make(map[string]Actor, len(n.Actors))
+		for _, actor := range n.Actors {
+			existing[actor.Name] = actor
+		}
+
+		n.Actors = make([]Actor, 0, len(values))
+		for _, name := range values {
+			actor, ok := existing[name]
+			if !ok {
+				actor = Actor{Name: name}
+			}
+			n.Actors = append(n.Actors, actor)
+		}
+	case FIELD_DIRECTOR:
+		n.Directors = values
+	case FIELD_STUDIO:
+		n.Studios = values
+	case FIELD_COUNTRY:
+		n.Countries = values
+	case FIELD_YEAR:
+		n.Year = 0
+		if len(values) > 0 {
+			n.Year, _ = strconv.Atoi(values[0])
+		}
+	case FIELD_TAG:
+		n.Tags = values
+	}
+}
+
+// Metadata returns the item metadata the nfo describes, unique ids of any provider become external ids
+func (n *Nfo) Metadata() *model.ItemMetadata {
+	result := &model.ItemMetadata{
+		ReleaseYear:   n.ReleaseYear(),
+		OriginalTitle: n.OriginalTitle,
+		Description:   n.Plot,
+	}
+
+	for _, id := range n.UniqueIds {
+		if id.Type != "" && strings.TrimSpace(id.Value) != "" {
+			if result.ExternalIds == nil {
+				result.ExternalIds = make(model.ExternalIds)
+			}
+			result.ExternalIds[strings.ToLower(id.Type)] = strings.TrimSpace(id.Value)
+		}
+	}
+
+	if imdbId := n.ImdbId(); imdbId != "" {
+		if result.ExternalIds == nil {
+			result.ExternalIds = make(model.ExternalIds)
+		}
+		result.ExternalIds[model.EXTERNAL_ID_IMDB] = imdbId
+	}
+
+	return result
+}
+
+// SetMetadata replaces the nfo fields with the non empty item metadata, the imdb id is the default unique id
+func (n *Nfo) SetMetadata(metadata *model.ItemMetadata) {
+	if metadata.ReleaseYear != 0 {
+		n.Year = metadata.ReleaseYear
+	}
+	if metadata.OriginalTitle != "" {
+		n.OriginalTitle = metadata.OriginalTitle
+	}
+	if metadata.Description != "" {
+		n.Plot = metadata.Description
+	}
+
+	providers := make([]string, 0, len(metadata.ExternalIds))
+	for provider := range metadata.ExternalIds {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	for _, provider := range providers {
+		n.setUniqueId(provider, metadata.ExternalIds[provider])
+	}
+}
+
+func (n *Nfo) setUniqueId(provider string, id string) {
+	for i := range n.UniqueIds {
+		if strings.EqualFold(n.UniqueIds[i].Type, provider) {
+			n.UniqueIds[i].Value = id
+			return
+		}
+	}
+
+	n.UniqueIds = append(n.UniqueIds, UniqueId{
+		Type:    provider,
+		Default: provider == model.EXTERNAL_ID_IMDB && len(n.UniqueIds) == 0,
+		Value:   id,
+	})
+}
+
+func IsField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,222 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/metadata"
+	"my-collection/server/pkg/bl/tags"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"my-collection/server/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("nfo")
+
+var DefaultTagParents = []string{"genre=Genres", "actor=Actors", "director=Directors", "studio=Studios", "year=Years"}
+
+type nfoDb interface {
+	model.TagReaderWriter
+	model.ItemReader
+}
+
+// ParseTagParents parses field=Parent Title pairs, the values of each field become tags under
+// a root tag of that title
+func ParseTagParents(pairs []string) (map[string]string, error) {
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		field, title, ok := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		title = strings.TrimSpace(title)
+		if !ok || title == "" || !IsField(field) {
+			return nil, errors.Errorf("invalid nfo tag parent %q, expected one of %s followed by =Title",
+				pair, strings.Join(Fields, ", "))
+		}
+
+		result[field] = title
+	}
+
+	return result, nil
+}
+
+// New creates the parent tags of the fields, a field without a parent isn't imported nor exported
+func New(ctx context.Context, db nfoDb, parents map[string]string) (*NfoTagger, error) {
+	parentTags := make(map[string]*model.Tag, len(parents))
+	for field, title := range parents {
+		tag, err := tags.GetOrCreateTag(ctx, db, &model.Tag{Title: title})
+		if err != nil {
+			return nil, err
+		}
+
+		parentTags[field] = tag
+	}
+
+	return &NfoTagger{
+		db:         db,
+		parentTags: parentTags,
+	}, nil
+}
+
+type NfoTagger struct {
+	db         nfoDb
+	parentTags map[string]*model.Tag
+}
+
+// ImportItemMetadata adds the tags, rating and metadata of the nfo next to a new item, before it's saved
+func (n *NfoTagger) ImportItemMetadata(ctx context.Context, item *model.Item) error {
+	file, ok := Find(relativasor.GetAbsoluteFile(item.Url))
+	if !ok {
+		return nil
+	}
+
+	parsed, err := ParseFile(file)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Importing %s into %s", file, item.Url)
+	return n.apply(ctx, item, parsed)
+}
+
+func (n *NfoTagger) apply(ctx context.Context, item *model.Item, parsed *Nfo) error {
+	for field, parent := range n.parentTags {
+		for _, value := range parsed.Values(field) {
+			tag, err := tags.GetOrCreateChildTag(ctx, n.db, parent.Id, value)
+			if err != nil {
+				return err
+			}
+
+			if !hasTag(item, tag.Id) {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+	}
+
+	if item.Rating == 0 && parsed.UserRating > 0 {
+		item.Rating = int(math.Max(1, math.Min(5, math.Round(parsed.UserRating/2))))
+	}
+
+	imported := parsed.Metadata()
+	metadata.Normalize(imported)
+	if err := metadata.Validate(imported); err != nil {
+		logger.Warningf("Ignoring invalid nfo metadata of %s - %s", item.Url, err)
+		return nil
+	}
+
+	metadata.Merge(&item.ItemMetadata, imported)
+	return nil
+}
+
+// Build returns the nfo of an item, the item tags under the parent tags become the nfo fields,
+// the rest of an existing nfo, including the elements we don't model, is kept
+func (n *NfoTagger) Build(ctx context.Context, itemId uint64) (*Nfo, error) {
+	item, err := n.db.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Nfo{}
+	if file, ok := Find(relativasor.GetAbsoluteFile(item.Url)); ok {
+		if result, err = ParseFile(file); err != nil {
+			logger.Warningf("Ignoring unreadable nfo %s - %s", file, err)
+			result = &Nfo{}
+		}
+	}
+
+	if result.Title == "" {
+		result.Title = strings.TrimSuffix(item.Title, filepath.Ext(item.Title))
+	}
+
+	for field, parent := range n.parentTags {
+		values := make([]string, 0)
+		for _, tag := range item.Tags {
+			if tag.ParentID != nil && *tag.ParentID == parent.Id {
+				values = append(values, tag.Title)
+			}
+		}
+
+		result.SetValues(field, values)
+	}
+
+	if item.Rating > 0 {
+		result.UserRating = float64(item.Rating * 2)
+	}
+
+	result.SetMetadata(&item.ItemMetadata)
+
+	return result, nil
+}
+
+// ExportItem writes the nfo of an item next to its file, named after it
+func (n *NfoTagger) ExportItem(ctx context.Context, itemId uint64) error {
+	result, err := n.Build(ctx, itemId)
+	if err != nil {
+		return err
+	}
+
+	item, err := n.db.GetItem(ctx, itemId)
+	if err != nil {
+		return err
+	}
+
+	file := SidecarPath(relativasor.GetAbsoluteFile(item.Url))
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer f.Close()
+
+	if err := Write(f, result); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
+// ExportAll writes the nfo of every item, highlights and sub items share the file of their
+// main item and are skipped
+func (n *NfoTagger) ExportAll(ctx context.Context) (int, error) {
+	allItems, err := n.db.GetAllItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	var lastError error
+	for _, item := range *allItems {
+		if items.IsHighlight(&item) || items.IsSubItem(&item) {
+			continue
+		}
+
+		if err := n.ExportItem(ctx, item.Id); err != nil {
+			utils.LogError(fmt.Sprintf("Error exporting nfo of item %d", item.Id), err)
+			lastError = err
+			continue
+		}
+		exported++
+	}
+
+	logger.Infof("Exported %d nfo files", exported)
+	return exported, lastError
+}
+
+func hasTag(item *model.Item, tagId uint64) bool {
+	for _, tag := range item.Tags {
+		if tag.Id == tagId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package nfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleNfo = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+  <title>The Movie</title>
+  <originaltitle>Le Film</originaltitle>
+  <premiered>1999-03-31</premiered>
+  <plot>Something happens.</plot>
+  <userrating>7</userrating>
+  <uniqueid type="imdb" default="true">tt0133093</uniqueid>
+  <uniqueid type="tmdb">603</uniqueid>
+  <genre>Action / Sci-Fi</genre>
+  <genre>Action</genre>
+  <director>Jane Doe</director>
+  <actor>
+    <name>John Roe</name>
+    <role>Hero</role>
+    <order>0</order>
+  </actor>
+  <fileinfo><streamdetails/></fileinfo>
+</movie>
+https://www.themoviedb.org/movie/603
+`
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse(strings.NewReader(sampleNfo))
+	assert.NoError(t, err)
+	assert.Equal(t, "movie", parsed.XMLName.Local)
+	assert.Equal(t, "The Movie", parsed.Title)
+	assert.Equal(t, "tt0133093", parsed.ImdbId())
+	assert.Equal(t, 1999, parsed.ReleaseYear())
+	assert.Equal(t, []string{"Action", "Sci-Fi"}, parsed.Values(FIELD_GENRE))
+	assert.Equal(t, []string{"John Roe"}, parsed.Values(FIELD_ACTOR))
+	assert.Equal(t, []string{"1999"}, parsed.Values(FIELD_YEAR))
+
+	legacy, err := Parse(strings.NewReader("<movie><id>tt0000001</id><year>2001</year></movie>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "tt0000001", legacy.ImdbId())
+	assert.Equal(t, 2001, legacy.ReleaseYear())
+
+	_, err = Parse(strings.NewReader("not xml"))
+	assert.Error(t, err)
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	parsed, err := Parse(strings.NewReader(sampleNfo))
+	assert.NoError(t, err)
+	parsed.SetValues(FIELD_ACTOR, []string{"John Roe", "Mary Major"})
+	parsed.SetValues(FIELD_GENRE, []string{"Drama"})
+
+	data := bytes.Buffer{}
+	assert.NoError(t, Write(&data, parsed))
+	assert.True(t, strings.HasPrefix(data.String(), "<?xml"))
+	assert.Contains(t, data.String(), "<fileinfo><streamdetails/></fileinfo>")
+
+	written, err := Parse(&data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Le Film", written.OriginalTitle)
+	assert.Equal(t, []string{"Drama"}, written.Genres)
+	assert.Equal(t, "Hero", written.Actors[0].Role)
+	assert.Equal(t, "Mary Major", written.Actors[1].Name)
+	assert.Equal(t, "tt0133093", written.ImdbId())
+	assert.Equal(t, parsed.Other, written.Other)
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	video := filepath.Join(dir, "video.mkv")
+	_, ok := Find(video)
+	assert.False(t, ok)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "movie.nfo"), []byte(sampleNfo), 0640))
+	file, ok := Find(video)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "movie.nfo"), file)
+
+	// the movie.nfo doesn't tell which of several videos it describes
+	other := filepath.Join(dir, "other.mp4")
+	assert.NoError(t, os.WriteFile(video, []byte{}, 0640))
+	assert.NoError(t, os.WriteFile(other, []byte{}, 0640))
+	_, ok = Find(video)
+	assert.False(t, ok)
+	_, ok = Find(other)
+	assert.False(t, ok)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "video.nfo"), []byte(sampleNfo), 0640))
+	file, ok = Find(video)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "video.nfo"), file)
+}
+
+func TestParseTagParents(t *testing.T) {
+	parents, err := ParseTagParents(DefaultTagParents)
+	assert.NoError(t, err)
+	assert.Equal(t, "Genres", parents[FIELD_GENRE])
+
+	_, err = ParseTagParents([]string{"rating=Ratings"})
+	assert.Error(t, err)
+	_, err = ParseTagParents([]string{"genre="})
+	assert.Error(t, err)
+}
+
+func TestImportAndExport(t *testing.T) {
+	db := setupNewDb(t, "nfo.sqlite")
+	ctx := context.Background()
+	dir := t.TempDir()
+	assert.NoError(t, relativasor.Init(dir))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "movie.nfo"), []byte(sampleNfo), 0640))
+
+	tagger, err := New(ctx, db, map[string]string{FIELD_GENRE: "Genres", FIELD_ACTOR: "Actors"})
+	assert.NoError(t, err)
+
+	item := &model.Item{Title: "movie.mkv", Origin: "origin", Url: "movie.mkv"}
+	assert.NoError(t, tagger.ImportItemMetadata(ctx, item))
+	assert.Equal(t, 4, item.Rating)
+	assert.Len(t, item.Tags, 3)
+	assert.Equal(t, 1999, item.ReleaseYear)
+	assert.Equal(t, "Something happens.", item.Description)
+	assert.Equal(t, model.ExternalIds{"imdb": "tt0133093", "tmdb": "603"}, item.ExternalIds)
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+
+	genres, err := db.GetTag(ctx, model.Tag{Title: "Genres"})
+	assert.NoError(t, err)
+	assert.Len(t, genres.Children, 2)
+
+	unrelated := &model.Item{Title: "other.mkv", Origin: "other", Url: "other/other.mkv"}
+	assert.NoError(t, tagger.ImportItemMetadata(ctx, unrelated))
+	assert.Empty(t, unrelated.Tags)
+
+	assert.NoError(t, db.SetItemMetadata(ctx, item.Id, &model.ItemMetadata{ReleaseYear: 2000, Language: "en",
+		ExternalIds: model.ExternalIds{"imdb": "tt0133093", "tmdb": "604"}}))
+	assert.NoError(t, tagger.ExportItem(ctx, item.Id))
+	exported, err := ParseFile(filepath.Join(dir, "movie.nfo"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Action", "Sci-Fi"}, exported.Genres)
+	assert.Equal(t, "Hero", exported.Actors[0].Role)
+	assert.Equal(t, float64(8), exported.UserRating)
+	assert.Equal(t, "Le Film", exported.OriginalTitle)
+	assert.Equal(t, 2000, exported.ReleaseYear())
+	assert.Equal(t, "tt0133093", exported.ImdbId())
+	assert.Equal(t, "604", exported.Metadata().ExternalIds["tmdb"])
+	assert.Len(t, exported.Other, 1)
+	assert.Equal(t, "fileinfo", exported.Other[0].XMLName.Local)
+}
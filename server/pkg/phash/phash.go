@@ -0,0 +1,134 @@
+package phash
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	hashSize = 8
+
+	// MaxDistance is the distance between two hashes that have nothing in common
+	MaxDistance = hashSize * hashSize
+)
+
+// DHash computes the difference hash of the image, the image is shrunk to 9x8 gray
+// pixels and every bit tells whether a pixel is brighter than its left neighbour.
+// Re-encodes and rescales of the same frame produce the same or a very close hash.
+func DHash(img image.Image) uint64 {
+	gray := shrinkToGray(img, hashSize+1, hashSize)
+
+	var hash uint64
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+func FileDHash(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, errors.Wrap(err, 0)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, errors.Wrap(err, 0)
+	}
+
+	return DHash(img), nil
+}
+
+// shrinkToGray averages the luma of the pixels of every cell of a width*height grid
+func shrinkToGray(img image.Image, width int, height int) [][]float64 {
+	bounds := img.Bounds()
+	sums := make([][]float64, height)
+	counts := make([][]int, height)
+	for y := range sums {
+		sums[y] = make([]float64, width)
+		counts[y] = make([]int, width)
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		cellY := (y - bounds.Min.Y) * height / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cellX := (x - bounds.Min.X) * width / bounds.Dx()
+			r, g, b, _ := img.At(x, y).RGBA()
+			sums[cellY][cellX] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			counts[cellY][cellX]++
+		}
+	}
+
+	for y := range sums {
+		for x := range sums[y] {
+			if counts[y][x] > 0 {
+				sums[y][x] /= float64(counts[y][x])
+			}
+		}
+	}
+
+	return sums
+}
+
+// Distance returns the number of different bits, 0 for identical hashes
+func Distance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FramesDistance returns the average distance of hashes of matching frames, videos
+// hashed with a different number of frames can't be compared and are MaxDistance apart.
+func FramesDistance(a []uint64, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return MaxDistance
+	}
+
+	total := 0
+	for i := range a {
+		total += Distance(a[i], b[i])
+	}
+
+	return float64(total) / float64(len(a))
+}
+
+func FormatHashes(hashes []uint64) string {
+	result := make([]string, len(hashes))
+	for i, hash := range hashes {
+		result[i] = fmt.Sprintf("%016x", hash)
+	}
+
+	return strings.Join(result, ",")
+}
+
+func ParseHashes(value string) ([]uint64, error) {
+	if value == "" {
+		return []uint64{}, nil
+	}
+
+	parts := strings.Split(value, ",")
+	result := make([]uint64, len(parts))
+	for i, part := range parts {
+		hash, err := strconv.ParseUint(part, 16, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+
+		result[i] = hash
+	}
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package phash
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gradient(width int, height int, reversed bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := uint8(x * 255 / width)
+			if reversed {
+				value = 255 - value
+			}
+			img.Set(x, y, color.RGBA{R: value, G: value, B: value, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	small := DHash(gradient(90, 80, false))
+	large := DHash(gradient(1920, 1080, false))
+	reversed := DHash(gradient(1920, 1080, true))
+
+	assert.Equal(t, uint64(0xffffffffffffffff), small)
+	assert.Equal(t, 0, Distance(small, large), "scaled images have the same hash")
+	assert.Equal(t, MaxDistance, Distance(large, reversed))
+}
+
+func TestFileDHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame.png")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	assert.NoError(t, png.Encode(file, gradient(320, 240, true)))
+	assert.NoError(t, file.Close())
+
+	hash, err := FileDHash(path)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), hash)
+
+	_, err = FileDHash(filepath.Join(t.TempDir(), "missing.png"))
+	assert.Error(t, err)
+}
+
+func TestFramesDistance(t *testing.T) {
+	assert.Equal(t, 0.0, FramesDistance([]uint64{1, 2}, []uint64{1, 2}))
+	assert.Equal(t, 1.0, FramesDistance([]uint64{0, 0}, []uint64{1, 2}))
+	assert.Equal(t, float64(MaxDistance), FramesDistance([]uint64{1}, []uint64{1, 2}))
+	assert.Equal(t, float64(MaxDistance), FramesDistance([]uint64{}, []uint64{}))
+}
+
+func TestFormatAndParseHashes(t *testing.T) {
+	hashes := []uint64{0, 1, 0xffffffffffffffff}
+	formatted := FormatHashes(hashes)
+	assert.Equal(t, "0000000000000000,0000000000000001,ffffffffffffffff", formatted)
+
+	parsed, err := ParseHashes(formatted)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes, parsed)
+
+	parsed, err = ParseHashes("")
+	assert.NoError(t, err)
+	assert.Empty(t, parsed)
+
+	_, err = ParseHashes("xyz")
+	assert.Error(t, err)
+}
@@ -3,12 +3,33 @@ package processor
 import (
 	"context"
 	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/utils"
 )
 
-func (p *Processor) EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) error {
+// enqueueSummary counts the results of enqueuing a batch, the errors of single items are
+// logged and don't stop the batch
+type enqueueSummary struct {
+	model.EnqueueSummary
+}
+
+func (s *enqueueSummary) add(coalesced bool, err error) {
+	if err != nil {
+		utils.LogWarning("Error enqueuing task", err)
+	} else if coalesced {
+		s.Coalesced++
+	} else {
+		s.Enqueued++
+	}
+}
+
+func (p *Processor) EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	summary := &enqueueSummary{}
+
 	allItems, err := p.db.GetAllItems(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, item := range *allItems {
@@ -19,16 +40,19 @@ func (p *Processor) EnqueueAllItemsVideoMetadata(ctx context.Context, force bool
 			}
 		}
 
-		p.EnqueueItemVideoMetadata(ctx, item.Id, item.Title)
+		summary.add(p.enqueueItemVideoMetadata(ctx, item.Id, item.Title))
 	}
 
-	return nil
+	return &summary.EnqueueSummary, nil
 }
 
-func (p *Processor) EnqueueAllItemsPreview(ctx context.Context, force bool) error {
+func (p *Processor) EnqueueAllItemsPreview(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	summary := &enqueueSummary{}
+
 	items, err := p.db.GetAllItems(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, item := range *items {
@@ -36,16 +60,19 @@ func (p *Processor) EnqueueAllItemsPreview(ctx context.Context, force bool) erro
 			continue
 		}
 
-		p.EnqueueItemPreview(ctx, item.Id, item.Title)
+		summary.add(p.enqueueItemPreview(ctx, item.Id, item.Title))
 	}
 
-	return nil
+	return &summary.EnqueueSummary, nil
 }
 
-func (p *Processor) EnqueueAllItemsCovers(ctx context.Context, force bool) error {
+func (p *Processor) EnqueueAllItemsCovers(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	summary := &enqueueSummary{}
+
 	items, err := p.db.GetAllItems(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, item := range *items {
@@ -53,21 +80,58 @@ func (p *Processor) EnqueueAllItemsCovers(ctx context.Context, force bool) error
 			continue
 		}
 
-		p.EnqueueItemCovers(ctx, item.Id, item.Title)
+		summary.add(p.enqueueItemCovers(ctx, item.Id, item.Title))
 	}
 
-	return nil
+	return &summary.EnqueueSummary, nil
 }
 
-func (p *Processor) EnqueueAllItemsFileMetadata(ctx context.Context) error {
+func (p *Processor) EnqueueAllItemsFileMetadata(ctx context.Context) (*model.EnqueueSummary, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	summary := &enqueueSummary{}
+
 	allItems, err := p.db.GetAllItems(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, item := range *allItems {
-		p.EnqueueItemFileMetadata(ctx, item.Id, item.Title)
+		summary.add(p.enqueueItemFileMetadata(ctx, item.Id, item.Title))
+	}
+
+	return &summary.EnqueueSummary, nil
+}
+
+func (p *Processor) EnqueueAllItemsVideoHash(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_LOW)
+	summary := &enqueueSummary{}
+
+	allItems, err := p.db.GetAllItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, err := p.db.GetVideoHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := make(map[uint64]bool)
+	for _, hash := range *hashes {
+		hashed[hash.ItemId] = true
+	}
+
+	for _, item := range *allItems {
+		if items.IsHighlight(&item) || items.IsSplittedItem(&item) || item.DurationSeconds == 0 {
+			continue
+		}
+
+		if !force && hashed[item.Id] {
+			continue
+		}
+
+		summary.add(p.enqueueItemVideoHash(ctx, item.Id, item.Title))
 	}
 
-	return nil
+	return &summary.EnqueueSummary, nil
 }
@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+)
+
+// running holds the cancel functions of the tasks being processed
+type running struct {
+	mutex   sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newRunning() *running {
+	return &running{cancels: make(map[string]context.CancelFunc)}
+}
+
+// start returns the context to process the task with, and a function to call when it's done
+func (r *running) start(ctx context.Context, taskId string) (context.Context, func()) {
+	taskCtx, cancel := context.WithCancel(ctx)
+
+	r.mutex.Lock()
+	r.cancels[taskId] = cancel
+	r.mutex.Unlock()
+
+	return taskCtx, func() {
+		r.mutex.Lock()
+		delete(r.cancels, taskId)
+		r.mutex.Unlock()
+		cancel()
+	}
+}
+
+// cancel returns whether the task was running
+func (r *running) cancel(taskId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cancel, ok := r.cancels[taskId]
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
+
+// CancelTask removes a task that didn't finish, a queued task is taken out of its queue, a
+// dequeued one is skipped, and a running one is interrupted and its processes are killed
+func (p *Processor) CancelTask(ctx context.Context, taskId string) error {
+	tasks, err := p.db.FindTasks(ctx, "id = ? and processing_end is null", taskId)
+	if err != nil {
+		return err
+	}
+
+	if len(*tasks) == 0 {
+		return errors.WrapPrefix(gorm.ErrRecordNotFound, "unfinished task "+taskId, 0)
+	}
+
+	task := (*tasks)[0]
+	logger.Infof("Cancelling task %s %s", task.Id, task.TaskType)
+	if _, err := p.pool(task.TaskType).remove(taskId); err != nil {
+		return err
+	}
+
+	if err := p.db.RemoveTasks(ctx, "id = ?", taskId); err != nil {
+		return err
+	}
+
+	if p.retries.cancel(taskId) {
+		logger.Infof("Task %s was waiting for a retry", taskId)
+	}
+
+	if p.running.cancel(taskId) {
+		logger.Infof("Interrupting running task %s", taskId)
+	}
+
+	return p.pushQueueMetadata(ctx)
+}
@@ -2,27 +2,72 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"my-collection/server/pkg/model"
 	general_tasks "my-collection/server/pkg/tasks/general"
 	video_tasks "my-collection/server/pkg/tasks/videos"
+	"my-collection/server/pkg/utils"
 	"time"
 
 	"github.com/google/uuid"
 	"k8s.io/utils/ptr"
 )
 
-func (p *Processor) enqueue(ctx context.Context, t *model.Task) error {
+// enqueue queues the task with the priority of the context, normal by default, unless an
+// equivalent task is pending, it returns whether the task was coalesced with the pending one
+func (p *Processor) enqueue(ctx context.Context, t *model.Task) (bool, error) {
+	coalesced, err := p.enqueueOrCoalesce(ctx, t)
+	if err != nil {
+		return false, err
+	}
+
+	return coalesced, p.pushQueueMetadata(ctx)
+}
+
+func (p *Processor) enqueueOrCoalesce(ctx context.Context, t *model.Task) (bool, error) {
+	p.enqueueMutex.Lock()
+	defer p.enqueueMutex.Unlock()
+
+	t.Priority = utils.GetTaskPriority(ctx)
+	t.IdempotencyKey = idempotencyKey(t)
+	pending, err := p.db.FindTasks(ctx, "idempotency_key = ? and processing_start is null and processing_end is null and retry_time is null", t.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+
+	// a task waiting for a retry isn't coalesced, as it may wait for minutes
+	pool := p.pool(t.TaskType)
+	for _, existing := range *pending {
+		if !pool.contains(existing.Id) {
+			continue
+		}
+
+		logger.Debugf("Task %s %s is already queued as %s", t.TaskType, t.Params, existing.Id)
+		if t.Priority > existing.Priority {
+			return true, p.moveTasks(ctx, []model.Task{existing}, t.Priority, -1)
+		}
+
+		return true, nil
+	}
+
 	t.Id = uuid.New().String()
 	t.EnequeueTime = ptr.To(time.Now().UnixMilli())
-	if err := p.dque.Enqueue(t); err != nil {
-		return err
+	if err := p.db.CreateTask(ctx, t); err != nil {
+		return false, err
 	}
 
-	if err := p.db.CreateTask(ctx, t); err != nil {
-		return err
+	if err := pool.enqueue(t); err != nil {
+		utils.LogWarning("Unable to remove unqueued task", p.db.RemoveTasks(ctx, "id = ?", t.Id))
+		return false, err
 	}
 
-	return p.pushQueueMetadata(ctx)
+	return false, nil
+}
+
+// idempotencyKey is equal for tasks of the same type and params, the params of all the task
+// types hold the item id
+func idempotencyKey(t *model.Task) string {
+	return fmt.Sprintf("%d:%s", t.TaskType, t.Params)
 }
 
 func createTask(taskType model.TaskType, params string, desc string) *model.Task {
@@ -30,9 +75,14 @@ func createTask(taskType model.TaskType, params string, desc string) *model.Task
 }
 
 func (p *Processor) EnqueueItemVideoMetadata(ctx context.Context, id uint64, title string) error {
+	_, err := p.enqueueItemVideoMetadata(ctx, id, title)
+	return err
+}
+
+func (p *Processor) enqueueItemVideoMetadata(ctx context.Context, id uint64, title string) (bool, error) {
 	params, err := video_tasks.MarshalVideoMetadataParams(id)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	desc := video_tasks.MetadataDesc(id, title)
@@ -40,9 +90,14 @@ func (p *Processor) EnqueueItemVideoMetadata(ctx context.Context, id uint64, tit
 }
 
 func (p *Processor) EnqueueItemPreview(ctx context.Context, id uint64, title string) error {
+	_, err := p.enqueueItemPreview(ctx, id, title)
+	return err
+}
+
+func (p *Processor) enqueueItemPreview(ctx context.Context, id uint64, title string) (bool, error) {
 	params, err := video_tasks.MarshalVideoPreviewParams(id, p.previewSceneCount, p.previewSceneDuration)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	desc := video_tasks.PreviewDesc(id, title, p.previewSceneCount, p.previewSceneDuration)
@@ -56,7 +111,8 @@ func (p *Processor) EnqueueMainCover(ctx context.Context, id uint64, second floa
 	}
 
 	desc := video_tasks.MainCoverDesc(id, title, second)
-	return p.enqueue(ctx, createTask(model.SET_MAIN_COVER, params, desc))
+	_, err = p.enqueue(ctx, createTask(model.SET_MAIN_COVER, params, desc))
+	return err
 }
 
 func (p *Processor) EnqueueCropFrame(ctx context.Context, id uint64, second float64, rect model.RectFloat, title string) error {
@@ -66,7 +122,8 @@ func (p *Processor) EnqueueCropFrame(ctx context.Context, id uint64, second floa
 	}
 
 	desc := video_tasks.CropDesc(id, title, second, rect)
-	return p.enqueue(ctx, createTask(model.CROP_FRAME, params, desc))
+	_, err = p.enqueue(ctx, createTask(model.CROP_FRAME, params, desc))
+	return err
 }
 
 func (p *Processor) EnqueueChangeResolution(ctx context.Context, id uint64, w int, h int, title string) error {
@@ -76,13 +133,19 @@ func (p *Processor) EnqueueChangeResolution(ctx context.Context, id uint64, w in
 	}
 
 	desc := video_tasks.ResolutionDesc(id, title, w, h)
-	return p.enqueue(ctx, createTask(model.CHANGE_RESOLUTION, params, desc))
+	_, err = p.enqueue(ctx, createTask(model.CHANGE_RESOLUTION, params, desc))
+	return err
 }
 
 func (p *Processor) EnqueueItemCovers(ctx context.Context, id uint64, title string) error {
+	_, err := p.enqueueItemCovers(ctx, id, title)
+	return err
+}
+
+func (p *Processor) enqueueItemCovers(ctx context.Context, id uint64, title string) (bool, error) {
 	params, err := video_tasks.MarshalVideoCoversParams(id, p.coversCount)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	desc := video_tasks.CoversDesc(id, title, p.coversCount)
@@ -90,11 +153,31 @@ func (p *Processor) EnqueueItemCovers(ctx context.Context, id uint64, title stri
 }
 
 func (p *Processor) EnqueueItemFileMetadata(ctx context.Context, id uint64, title string) error {
+	_, err := p.enqueueItemFileMetadata(ctx, id, title)
+	return err
+}
+
+func (p *Processor) enqueueItemFileMetadata(ctx context.Context, id uint64, title string) (bool, error) {
 	params, err := general_tasks.MarshalFileMetadataParams(id)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	desc := general_tasks.MetadataDesc(id, title)
 	return p.enqueue(ctx, createTask(model.REFRESH_FILE_TASK, params, desc))
 }
+
+func (p *Processor) EnqueueItemVideoHash(ctx context.Context, id uint64, title string) error {
+	_, err := p.enqueueItemVideoHash(ctx, id, title)
+	return err
+}
+
+func (p *Processor) enqueueItemVideoHash(ctx context.Context, id uint64, title string) (bool, error) {
+	params, err := video_tasks.MarshalVideoHashParams(id)
+	if err != nil {
+		return false, err
+	}
+
+	desc := video_tasks.VideoHashDesc(id, title)
+	return p.enqueue(ctx, createTask(model.VIDEO_HASH_TASK, params, desc))
+}
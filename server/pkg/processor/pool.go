@@ -0,0 +1,392 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"my-collection/server/pkg/model"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/joncrlsn/dque"
+)
+
+// Tasks are split into pools by their cost, so quick metadata tasks aren't queued behind
+// transcodes, each pool has its own persistent queue and workers
+const (
+	POOL_METADATA = "metadata"
+	POOL_FFMPEG   = "ffmpeg"
+)
+
+func taskPool(taskType model.TaskType) string {
+	switch taskType {
+	case model.REFRESH_METADATA_TASK, model.REFRESH_FILE_TASK:
+		return POOL_METADATA
+	default:
+		return POOL_FFMPEG
+	}
+}
+
+// newWorkerPool opens a queue per priority, the normal one keeps the name of the queue used
+// before priorities existed
+func newWorkerPool(name string, directory string, workers int) (*workerPool, error) {
+	queues := make(map[model.TaskPriority]*dque.DQue)
+	for _, priority := range model.TaskPriorities {
+		queueName := "tasks-" + name
+		if priority != model.TASK_PRIORITY_NORMAL {
+			queueName += "-" + priority.String()
+		}
+
+		queue, err := dque.NewOrOpen(queueName, directory, 100, taskBuilder)
+		if err != nil {
+			return nil, err
+		}
+
+		queues[priority] = queue
+	}
+
+	return &workerPool{
+		name:    name,
+		workers: max(workers, 1),
+		queues:  queues,
+		queued:  make(map[string]bool),
+		wakeup:  make(chan bool, 1),
+	}, nil
+}
+
+type workerPool struct {
+	name        string
+	workers     int
+	mutex       sync.Mutex // the queues are rewritten as a whole when tasks move
+	queues      map[model.TaskPriority]*dque.DQue
+	queued      map[string]bool // the ids of the tasks in the queues
+	wakeup      chan bool
+	busy        atomic.Int64
+	parked      atomic.Int64
+	processed   atomic.Int64
+	failed      atomic.Int64
+	totalMillis atomic.Int64
+}
+
+func (wp *workerPool) enqueue(t *model.Task) error {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	if err := wp.enqueueLocked(t); err != nil {
+		return err
+	}
+
+	wp.notify()
+	return nil
+}
+
+func (wp *workerPool) enqueueLocked(t *model.Task) error {
+	queue, ok := wp.queues[t.Priority]
+	if !ok {
+		queue = wp.queues[model.TASK_PRIORITY_NORMAL]
+	}
+
+	if err := queue.Enqueue(t); err != nil {
+		return err
+	}
+
+	wp.queued[t.Id] = true
+	return nil
+}
+
+// contains checks whether the task is in the queues, rather than dequeued by a worker
+func (wp *workerPool) contains(taskId string) bool {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	return wp.queued[taskId]
+}
+
+// unpark queues again a task that waited for its item
+func (wp *workerPool) unpark(t *model.Task) {
+	wp.parked.Add(-1)
+	if err := wp.enqueue(t); err != nil {
+		logger.Errorf("Error requeuing parked task %s %s", t.Id, err)
+	}
+}
+
+// drain empties the queues
+func (wp *workerPool) drain() ([]*model.Task, error) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	return wp.drainLocked()
+}
+
+func (wp *workerPool) drainLocked() ([]*model.Task, error) {
+	tasks := make([]*model.Task, 0)
+	for {
+		task, err := wp.dequeueLocked()
+		if err != nil || task == nil {
+			return tasks, err
+		}
+
+		tasks = append(tasks, task)
+	}
+}
+
+// move takes the queued tasks out of their queues and inserts them to the queue of the priority,
+// at the position or at its end when the position is negative, the update function is called
+// with each moved task before it's queued again, it returns the number of moved tasks, as tasks
+// that were dequeued meanwhile are skipped
+func (wp *workerPool) move(taskIds []string, priority model.TaskPriority, position int,
+	update func(t *model.Task) error) (int, error) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+	defer wp.notify()
+
+	moving := make(map[string]*model.Task)
+	for _, taskId := range taskIds {
+		moving[taskId] = nil
+	}
+
+	moved := make([]*model.Task, 0, len(taskIds))
+	err := wp.rewriteLocked(func(queued []*model.Task) ([]*model.Task, error) {
+		remaining := make([]*model.Task, 0, len(queued))
+		for _, task := range queued {
+			if _, ok := moving[task.Id]; ok {
+				moving[task.Id] = task
+			} else {
+				remaining = append(remaining, task)
+			}
+		}
+
+		for _, taskId := range taskIds {
+			task := moving[taskId]
+			if task == nil {
+				continue
+			}
+
+			task.Priority = priority
+			if err := update(task); err != nil {
+				return nil, err
+			}
+			moved = append(moved, task)
+		}
+
+		// the queued tasks are ordered by priority, the position is counted from the first task
+		// of the priority
+		at := 0
+		for at < len(remaining) && remaining[at].Priority > priority {
+			at++
+		}
+
+		end := at
+		for end < len(remaining) && remaining[end].Priority == priority {
+			end++
+		}
+
+		if position >= 0 {
+			end = min(at+position, end)
+		}
+
+		return append(remaining[:end], append(moved, remaining[end:]...)...), nil
+	})
+
+	return len(moved), err
+}
+
+// remove takes the task out of its queue, it returns whether it was queued
+func (wp *workerPool) remove(taskId string) (bool, error) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	removed := false
+	err := wp.rewriteLocked(func(queued []*model.Task) ([]*model.Task, error) {
+		remaining := make([]*model.Task, 0, len(queued))
+		for _, task := range queued {
+			if task.Id == taskId {
+				removed = true
+			} else {
+				remaining = append(remaining, task)
+			}
+		}
+		return remaining, nil
+	})
+
+	return removed, err
+}
+
+// rewriteLocked drains the queues and queues again the tasks returned by the rewrite function,
+// or the drained tasks when it fails
+func (wp *workerPool) rewriteLocked(rewrite func(queued []*model.Task) ([]*model.Task, error)) error {
+	for _, queue := range wp.queues {
+		if err := queue.TurboOn(); err != nil {
+			return err
+		}
+	}
+
+	defer func() {
+		for _, queue := range wp.queues {
+			if err := queue.TurboOff(); err != nil {
+				logger.Errorf("Error syncing the %s queue %s", wp.name, err)
+			}
+		}
+	}()
+
+	queued, err := wp.drainLocked()
+	if err != nil {
+		return err
+	}
+
+	rewritten, rewriteErr := rewrite(append([]*model.Task{}, queued...))
+	if rewriteErr != nil {
+		rewritten = queued
+	}
+
+	for _, task := range rewritten {
+		if err := wp.enqueueLocked(task); err != nil {
+			return err
+		}
+	}
+
+	return rewriteErr
+}
+
+func (wp *workerPool) notify() {
+	select {
+	case wp.wakeup <- true:
+	default:
+	}
+}
+
+// dequeue hands each task to a single worker, from the highest priority queue that isn't
+// empty, it returns nil when all the queues are empty
+func (wp *workerPool) dequeue() (*model.Task, error) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	task, err := wp.dequeueLocked()
+	if task != nil && wp.sizeLocked() > 0 {
+		// let another idle worker take the next one
+		wp.notify()
+	}
+
+	return task, err
+}
+
+func (wp *workerPool) dequeueLocked() (*model.Task, error) {
+	for _, priority := range model.TaskPriorities {
+		taskIfc, err := wp.queues[priority].Dequeue()
+		if err == dque.ErrEmpty {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		task, ok := taskIfc.(*model.Task)
+		if !ok {
+			return nil, errors.Errorf("unable to convert %v to task", taskIfc)
+		}
+
+		delete(wp.queued, task.Id)
+
+		return task, nil
+	}
+
+	return nil, nil
+}
+
+func (wp *workerPool) size() int {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+
+	return wp.sizeLocked()
+}
+
+func (wp *workerPool) sizeLocked() int {
+	size := 0
+	for _, queue := range wp.queues {
+		size += queue.Size()
+	}
+
+	return size
+}
+
+func (wp *workerPool) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-wp.wakeup:
+	case <-time.After(time.Second):
+	}
+}
+
+func (wp *workerPool) done(processingMillis int64, err error) {
+	wp.processed.Add(1)
+	wp.totalMillis.Add(processingMillis)
+	if err != nil {
+		wp.failed.Add(1)
+	}
+}
+
+func (wp *workerPool) metadata() model.PoolMetadata {
+	result := model.PoolMetadata{
+		Name:      wp.name,
+		Workers:   wp.workers,
+		Busy:      wp.busy.Load(),
+		Queued:    int64(wp.size()) + wp.parked.Load(),
+		Processed: wp.processed.Load(),
+		Failed:    wp.failed.Load(),
+	}
+
+	if result.Processed > 0 {
+		result.AverageMillis = wp.totalMillis.Load() / result.Processed
+	}
+
+	return result
+}
+
+// itemLocks serializes the tasks of an item across the pools, as they read and update the
+// whole item, the tasks of a locked item are parked until it's unlocked
+type itemLocks struct {
+	mutex  sync.Mutex
+	locked map[uint64][]func()
+}
+
+func newItemLocks() *itemLocks {
+	return &itemLocks{locked: make(map[uint64][]func())}
+}
+
+// tryLock returns the unlock function of the item, or parks the task when the item is locked,
+// the parked functions are called when it's unlocked
+func (l *itemLocks) tryLock(itemId uint64, park func()) (func(), bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if parked, ok := l.locked[itemId]; ok {
+		l.locked[itemId] = append(parked, park)
+		return nil, false
+	}
+
+	l.locked[itemId] = nil
+	return func() {
+		l.mutex.Lock()
+		parked := l.locked[itemId]
+		delete(l.locked, itemId)
+		l.mutex.Unlock()
+
+		for _, unpark := range parked {
+			unpark()
+		}
+	}, true
+}
+
+// taskItemId returns the item of the task, the params of all the task types hold it as id
+func taskItemId(t *model.Task) uint64 {
+	var params struct {
+		ItemId uint64 `json:"id"`
+	}
+
+	if err := json.Unmarshal([]byte(t.Params), &params); err != nil {
+		return 0
+	}
+
+	return params.ItemId
+}
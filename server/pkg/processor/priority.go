@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+)
+
+// ReprioritizeTasks moves queued tasks to a priority, in the given order, at the position
+// within the tasks already queued with that priority or after them when it's negative
+func (p *Processor) ReprioritizeTasks(ctx context.Context, taskIds []string, priority model.TaskPriority, position int) error {
+	if !priority.IsValid() {
+		return errors.Errorf("invalid priority %d", priority)
+	}
+
+	queued := make([]model.Task, 0, len(taskIds))
+	for _, taskId := range taskIds {
+		tasks, err := p.db.FindTasks(ctx, "id = ? and processing_start is null and processing_end is null and retry_time is null", taskId)
+		if err != nil {
+			return err
+		}
+
+		if len(*tasks) == 0 {
+			return errors.WrapPrefix(gorm.ErrRecordNotFound, "queued task "+taskId, 0)
+		}
+
+		queued = append(queued, (*tasks)[0])
+	}
+
+	if err := p.moveTasks(ctx, queued, priority, position); err != nil {
+		return err
+	}
+
+	return p.pushQueueMetadata(ctx)
+}
+
+// moveTasks moves the tasks within the queues of their pools, and saves their new priority
+func (p *Processor) moveTasks(ctx context.Context, tasks []model.Task, priority model.TaskPriority, position int) error {
+	byPool := make(map[*workerPool][]string)
+	saved := make(map[string]*model.Task)
+	for i, task := range tasks {
+		pool := p.pool(task.TaskType)
+		byPool[pool] = append(byPool[pool], task.Id)
+		saved[task.Id] = &tasks[i]
+	}
+
+	for pool, taskIds := range byPool {
+		moved, err := pool.move(taskIds, priority, position, func(t *model.Task) error {
+			logger.Infof("Moving task %s %s to priority %s", t.Id, t.TaskType, priority)
+			task := saved[t.Id]
+			task.Priority = priority
+			return p.db.UpdateTask(ctx, task)
+		})
+		if err != nil {
+			return err
+		}
+
+		if moved < len(taskIds) {
+			logger.Infof("%d of the tasks were dequeued before they were moved", len(taskIds)-moved)
+		}
+	}
+
+	return nil
+}
+
+// isCurrent checks the dequeued task wasn't removed or finished since it was queued
+func (p *Processor) isCurrent(ctx context.Context, task *model.Task) bool {
+	tasks, err := p.db.FindTasks(ctx, "id = ?", task.Id)
+	if err != nil {
+		logger.Warningf("Unable to get task %s %s", task.Id, err)
+		return true
+	}
+
+	if len(*tasks) == 0 {
+		return false
+	}
+
+	current := (*tasks)[0]
+	return current.ProcessingEnd == nil && !current.DeadLetter
+}
@@ -11,6 +11,9 @@ import (
 	video_tasks "my-collection/server/pkg/tasks/videos"
 	"my-collection/server/pkg/utils"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-errors/errors"
@@ -29,16 +32,23 @@ type Processor struct {
 	utils.PushSender
 	db                   db.Database
 	storage              *storage.Storage
-	dque                 *dque.DQue
+	pools                []*workerPool
+	itemLocks            *itemLocks
+	retries              *retries
+	running              *running
+	enqueueMutex         sync.Mutex
+	handleTask           func(ctx context.Context, t *model.Task) error
+	tasksDirectory       string
 	pauseChannel         chan bool
-	paused               bool
+	paused               atomic.Bool
 	coversCount          int
 	previewSceneCount    int
 	previewSceneDuration int
 	automaticProcessing  bool
 }
 
-func New(db db.Database, storage *storage.Storage, paused bool, coversCount int, previewSceneCount int, previewSceneDuration int) (*Processor, error) {
+func New(db db.Database, storage *storage.Storage, paused bool, coversCount int, previewSceneCount int, previewSceneDuration int,
+	metadataWorkers int, ffmpegWorkers int) (*Processor, error) {
 	logger.Infof("Item processor initialized")
 
 	tasksDirectory := storage.GetStorageDirectory("tasks")
@@ -47,23 +57,49 @@ func New(db db.Database, storage *storage.Storage, paused bool, coversCount int,
 		return nil, err
 	}
 
-	dque, err := dque.NewOrOpen("tasks", tasksDirectory, 100, taskBuilder)
-	if err != nil {
-		logger.Errorf("Error creating tasks queue %s", err)
-		return nil, err
-	}
-
-	return &Processor{
+	p := &Processor{
 		db:                   db,
 		storage:              storage,
-		dque:                 dque,
+		itemLocks:            newItemLocks(),
+		retries:              newRetries(),
+		running:              newRunning(),
+		tasksDirectory:       tasksDirectory,
 		coversCount:          coversCount,
 		previewSceneCount:    previewSceneCount,
 		previewSceneDuration: previewSceneDuration,
-		paused:               paused,
 		automaticProcessing:  false,
 		pauseChannel:         make(chan bool, 10),
-	}, nil
+	}
+	p.handleTask = p.processTask
+	p.paused.Store(paused)
+
+	pools := []struct {
+		name    string
+		workers int
+	}{{POOL_METADATA, metadataWorkers}, {POOL_FFMPEG, ffmpegWorkers}}
+
+	for _, config := range pools {
+		pool, err := newWorkerPool(config.name, tasksDirectory, config.workers)
+		if err != nil {
+			logger.Errorf("Error creating %s tasks queue %s", config.name, err)
+			return nil, err
+		}
+
+		p.pools = append(p.pools, pool)
+	}
+
+	return p, nil
+}
+
+func (p *Processor) pool(taskType model.TaskType) *workerPool {
+	name := taskPool(taskType)
+	for _, pool := range p.pools {
+		if pool.name == name {
+			return pool
+		}
+	}
+
+	return p.pools[0]
 }
 
 func (p *Processor) pushQueueMetadata(ctx context.Context) error {
@@ -77,7 +113,7 @@ func (p *Processor) pushQueueMetadata(ctx context.Context) error {
 }
 
 func (p *Processor) ClearFinishedTasks(ctx context.Context) error {
-	if err := p.db.RemoveTasks(ctx, "processing_end is not null"); err != nil {
+	if err := p.db.RemoveTasks(ctx, "processing_end is not null and dead_letter = ?", false); err != nil {
 		logger.Errorf("Unable to clear finished tasks %s", err)
 		return err
 	}
@@ -87,7 +123,16 @@ func (p *Processor) ClearFinishedTasks(ctx context.Context) error {
 }
 
 func (p *Processor) IsPaused() bool {
-	return p.paused
+	return p.paused.Load()
+}
+
+func (p *Processor) PoolsMetadata() []model.PoolMetadata {
+	result := make([]model.PoolMetadata, 0, len(p.pools))
+	for _, pool := range p.pools {
+		result = append(result, pool.metadata())
+	}
+
+	return result
 }
 func (p *Processor) IsAutomaticProcessing() bool {
 	return p.automaticProcessing
@@ -112,67 +157,258 @@ func (p *Processor) GetFileMetadata(path string) (int64, int64, error) {
 
 func (p *Processor) Run(ctx context.Context) error {
 	ctx = utils.ContextWithSubject(ctx, "processor")
+	if err := p.resumeTasks(ctx); err != nil {
+		utils.LogError("Error resuming interrupted tasks", err)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, pool := range p.pools {
+		logger.Infof("Starting %d workers of the %s pool", pool.workers, pool.name)
+		for range pool.workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.runWorker(ctx, pool)
+			}()
+		}
+	}
+
 	for {
 		select {
 		case paused := <-p.pauseChannel:
-			logger.Infof("Queue paused changed from %t to %t", p.paused, paused)
-			p.paused = paused
+			logger.Infof("Queue paused changed from %t to %t", p.paused.Load(), paused)
+			p.paused.Store(paused)
 			p.pushQueueMetadata(ctx)
 		case <-ctx.Done():
+			p.retries.stopAll()
+			wg.Wait()
 			return nil
-		default:
-			if !p.paused {
-				p.process(ctx)
-			} else {
-				time.Sleep(time.Second)
-			}
 		}
 	}
 }
 
-func (p *Processor) process(ctx context.Context) {
-	taskIfc, err := p.dque.Peek()
-	if err != nil {
-		if err != dque.ErrEmpty {
-			logger.Errorf("Error peeking tasks queue %s", err)
+func (p *Processor) runWorker(ctx context.Context, pool *workerPool) {
+	for ctx.Err() == nil {
+		if p.paused.Load() {
+			pool.wait(ctx)
+			continue
 		}
 
-		time.Sleep(time.Second)
-		return
+		task, err := pool.dequeue()
+		if err != nil {
+			logger.Errorf("Error dequeuing %s tasks %s", pool.name, err)
+		}
+		if task == nil {
+			pool.wait(ctx)
+			continue
+		}
+
+		p.process(ctx, pool, task)
 	}
+}
 
-	task, ok := taskIfc.(*model.Task)
-	if !ok {
-		logger.Errorf("Unable to convert interface to task %s", task)
+func (p *Processor) process(ctx context.Context, pool *workerPool, task *model.Task) {
+	taskCtx, done := p.running.start(ctx, task.Id)
+	defer done()
+
+	// checked once the task is running, so a concurrent cancel either removed it before or
+	// interrupts it
+	if !p.isCurrent(ctx, task) {
+		logger.Debugf("Skipping stale copy of task %s", task.Id)
 		return
 	}
 
+	// a task of a locked item waits outside the queue, so the worker can take the next task
+	if itemId := taskItemId(task); itemId != 0 {
+		pool.parked.Add(1)
+		unlock, locked := p.itemLocks.tryLock(itemId, func() { pool.unpark(task) })
+		if !locked {
+			logger.Debugf("Item %d is locked, parking task %s", itemId, task.Id)
+			return
+		}
+		pool.parked.Add(-1)
+		defer unlock()
+	}
+
+	pool.busy.Add(1)
+	defer pool.busy.Add(-1)
+
 	startMillis := time.Now().UnixMilli()
-	task.ProcessingStart = ptr.To(time.Now().UnixMilli())
+	task.ProcessingStart = ptr.To(startMillis)
+	task.Attempts++
 	if err := p.db.UpdateTask(ctx, task); err != nil {
 		logger.Warningf("Unable to update task processing start time %s %s", task.Id, err)
 	}
 
-	logger.Infof("Start processing task %+v", task)
-	if err := p.processTask(ctx, task); err != nil {
-		logger.Errorf("Error processing task %+v for params: %s - %s", task.TaskType.String(), task.Params, err)
+	logger.Infof("Start processing task in the %s pool %+v", pool.name, task)
+	err := p.handleTask(taskCtx, task)
+	if ctx.Err() != nil {
+		logger.Infof("Task %s interrupted, it will resume on the next start", task.Id)
+		return
 	}
 
-	task.ProcessingEnd = ptr.To(time.Now().UnixMilli())
-	if err := p.db.UpdateTask(ctx, task); err != nil {
-		logger.Warningf("Unable to update task processing end time %s %s", task.Id, err)
+	if taskCtx.Err() != nil {
+		logger.Infof("Task %s cancelled after %dms", task.Id, time.Now().UnixMilli()-startMillis)
+		return
 	}
 
-	if _, err = p.dque.DequeueBlock(); err != nil {
-		logger.Errorf("Error dequeuing task %s - %+v", err, task)
+	processingMillis := time.Now().UnixMilli() - startMillis
+	pool.done(processingMillis, err)
+
+	if err != nil {
+		logger.Errorf("Error processing task %+v for params: %s - %s", task.TaskType.String(), task.Params, err)
+		p.taskFailed(ctx, task, err)
+	} else {
+		task.ProcessingEnd = ptr.To(time.Now().UnixMilli())
+		if err := p.db.UpdateTask(ctx, task); err != nil {
+			logger.Warningf("Unable to update task processing end time %s %s", task.Id, err)
+		}
 	}
 
 	p.pushQueueMetadata(ctx)
-
-	processingMillis := time.Now().UnixMilli() - startMillis
 	logger.Infof("Done processing task in %dms %+v", processingMillis, task)
 }
 
+// resumeTasks rebuilds the queues from the unfinished tasks of the db, so the tasks that were
+// dequeued but didn't finish when the server stopped are resumed, and schedules the pending
+// retries, the queued tasks keep their order
+func (p *Processor) resumeTasks(ctx context.Context) error {
+	// tasks enqueued meanwhile would be drained without being in the db results
+	p.enqueueMutex.Lock()
+	defer p.enqueueMutex.Unlock()
+
+	if err := p.migrateLegacyQueue(ctx); err != nil {
+		return err
+	}
+
+	if err := p.backfillIdempotencyKeys(ctx); err != nil {
+		return err
+	}
+
+	unfinished, err := p.db.FindTasks(ctx, "processing_end is null and retry_time is null")
+	if err != nil {
+		return err
+	}
+
+	tasks := make(map[string]*model.Task)
+	for i := range *unfinished {
+		tasks[(*unfinished)[i].Id] = &(*unfinished)[i]
+	}
+
+	for _, pool := range p.pools {
+		queued, err := pool.drain()
+		if err != nil {
+			return err
+		}
+
+		for _, queuedTask := range queued {
+			task, ok := tasks[queuedTask.Id]
+			if !ok || task.ProcessingStart != nil {
+				continue
+			}
+
+			delete(tasks, queuedTask.Id)
+			if err := pool.enqueue(task); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, task := range *unfinished {
+		if _, ok := tasks[task.Id]; !ok {
+			continue
+		}
+
+		logger.Infof("Resuming interrupted task %+v", task)
+		task.ProcessingStart = nil
+		if err := p.db.UpdateTask(ctx, &task); err != nil {
+			return err
+		}
+
+		if err := p.pool(task.TaskType).enqueue(&task); err != nil {
+			return err
+		}
+	}
+
+	waiting, err := p.db.FindTasks(ctx, "retry_time is not null and processing_start is null")
+	if err != nil {
+		return err
+	}
+
+	for _, task := range *waiting {
+		delay := time.Until(time.UnixMilli(*task.RetryTime))
+		p.scheduleRetry(&task, max(delay, 0))
+	}
+
+	return nil
+}
+
+// backfillIdempotencyKeys sets the keys of the unfinished tasks queued before they existed
+func (p *Processor) backfillIdempotencyKeys(ctx context.Context) error {
+	tasks, err := p.db.FindTasks(ctx, "processing_end is null and idempotency_key = ?", "")
+	if err != nil {
+		return err
+	}
+
+	for _, task := range *tasks {
+		task.IdempotencyKey = idempotencyKey(&task)
+		if err := p.db.UpdateTask(ctx, &task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateLegacyQueue removes the single queue used before the pools, its tasks are queued again
+// from the db, the ones that weren't saved are saved first
+func (p *Processor) migrateLegacyQueue(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(p.tasksDirectory, "tasks")); os.IsNotExist(err) {
+		return nil
+	}
+
+	legacy, err := dque.Open("tasks", p.tasksDirectory, 100, taskBuilder)
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	for {
+		taskIfc, err := legacy.Dequeue()
+		if err == dque.ErrEmpty {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		task, ok := taskIfc.(*model.Task)
+		if !ok {
+			continue
+		}
+
+		saved, err := p.db.FindTasks(ctx, "id = ?", task.Id)
+		if err != nil {
+			return err
+		}
+
+		if len(*saved) == 0 {
+			if err := p.db.CreateTask(ctx, task); err != nil {
+				return err
+			}
+		}
+
+		migrated++
+	}
+
+	logger.Infof("Moved %d tasks of the legacy queue to the pools", migrated)
+	if err := legacy.Close(); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(p.tasksDirectory, "tasks"))
+}
+
 func (p *Processor) processTask(ctx context.Context, t *model.Task) error {
 	switch t.TaskType {
 	case model.REFRESH_COVER_TASK:
@@ -189,6 +425,8 @@ func (p *Processor) processTask(ctx context.Context, t *model.Task) error {
 		return general_tasks.UpdateFileMetadata(ctx, p.db, t.Params)
 	case model.CHANGE_RESOLUTION:
 		return video_tasks.ChangeVideoResolution(ctx, p.db, p.storage, t.Params)
+	case model.VIDEO_HASH_TASK:
+		return video_tasks.RefreshVideoHash(ctx, p.db, p.db, t.Params)
 	default:
 		return fmt.Errorf("unknown task %+v", t)
 	}
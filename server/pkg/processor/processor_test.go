@@ -0,0 +1,505 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	general_tasks "my-collection/server/pkg/tasks/general"
+	video_tasks "my-collection/server/pkg/tasks/videos"
+	"my-collection/server/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func setupProcessor(t *testing.T, filename string, metadataWorkers int, ffmpegWorkers int) (*Processor, db.Database) {
+	db := setupNewDb(t, filename)
+	s, err := storage.New(t.TempDir())
+	assert.NoError(t, err)
+	p, err := New(db, s, false, 3, 0, 0, metadataWorkers, ffmpegWorkers)
+	assert.NoError(t, err)
+	return p, db
+}
+
+func poolMetadata(p *Processor, name string) model.PoolMetadata {
+	for _, pool := range p.PoolsMetadata() {
+		if pool.Name == name {
+			return pool
+		}
+	}
+
+	return model.PoolMetadata{}
+}
+
+func TestTaskPool(t *testing.T) {
+	assert.Equal(t, POOL_METADATA, taskPool(model.REFRESH_FILE_TASK))
+	assert.Equal(t, POOL_METADATA, taskPool(model.REFRESH_METADATA_TASK))
+	assert.Equal(t, POOL_FFMPEG, taskPool(model.CHANGE_RESOLUTION))
+	assert.Equal(t, POOL_FFMPEG, taskPool(model.REFRESH_COVER_TASK))
+}
+
+func TestTaskItemId(t *testing.T) {
+	params, err := video_tasks.MarshalVideoCropParams(12, 1.5, model.RectFloat{W: 10, H: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), taskItemId(&model.Task{Params: params}))
+	assert.Equal(t, uint64(0), taskItemId(&model.Task{Params: "invalid"}))
+}
+
+func TestPoolsRunIndependently(t *testing.T) {
+	p, _ := setupProcessor(t, "processor-pools.sqlite", 2, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan bool)
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		if task.TaskType == model.CHANGE_RESOLUTION {
+			<-release
+		}
+		return nil
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	assert.NoError(t, p.EnqueueChangeResolution(ctx, 1, 640, 480, "transcode"))
+	for id := uint64(2); id < 12; id++ {
+		assert.NoError(t, p.EnqueueItemFileMetadata(ctx, id, "file"))
+	}
+
+	// the metadata tasks aren't blocked by the transcode
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_METADATA).Processed == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ffmpeg := poolMetadata(p, POOL_FFMPEG)
+	assert.Equal(t, int64(1), ffmpeg.Busy)
+	assert.Equal(t, int64(0), ffmpeg.Processed)
+	assert.Equal(t, 1, ffmpeg.Workers)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_FFMPEG).Processed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestItemTasksAreSerialized(t *testing.T) {
+	p, _ := setupProcessor(t, "processor-items.sqlite", 4, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var running atomic.Int32
+	var overlapped atomic.Bool
+	var processed sync.WaitGroup
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		defer processed.Done()
+		if running.Add(1) > 1 {
+			overlapped.Store(true)
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+
+	processed.Add(8)
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueItemVideoMetadata(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueItemCovers(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueItemVideoHash(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueItemPreview(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueMainCover(ctx, 1, 2.5, "item"))
+	assert.NoError(t, p.EnqueueCropFrame(ctx, 1, 2.5, model.RectFloat{W: 10, H: 10}, "item"))
+	assert.NoError(t, p.EnqueueChangeResolution(ctx, 1, 640, 480, "item"))
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	processed.Wait()
+	assert.False(t, overlapped.Load())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestResumeInterruptedTasks(t *testing.T) {
+	p, db := setupProcessor(t, "processor-resume.sqlite", 1, 1)
+	ctx := context.Background()
+
+	interrupted := &model.Task{Id: "interrupted", TaskType: model.REFRESH_COVER_TASK, Params: `{"id":1}`,
+		EnequeueTime: ptr.To(int64(1)), ProcessingStart: ptr.To(int64(2))}
+	finished := &model.Task{Id: "finished", TaskType: model.REFRESH_FILE_TASK, Params: `{"id":2}`,
+		EnequeueTime: ptr.To(int64(1)), ProcessingStart: ptr.To(int64(2)), ProcessingEnd: ptr.To(int64(3))}
+	// dequeued by a worker that stopped before saving its processing start
+	dequeued := &model.Task{Id: "dequeued", TaskType: model.REFRESH_METADATA_TASK, Params: `{"id":3}`,
+		EnequeueTime: ptr.To(int64(1))}
+	assert.NoError(t, db.CreateTask(ctx, interrupted))
+	assert.NoError(t, db.CreateTask(ctx, finished))
+	assert.NoError(t, db.CreateTask(ctx, dequeued))
+
+	// the queue copy of a task that was removed is dropped
+	assert.NoError(t, p.pool(model.REFRESH_FILE_TASK).enqueue(&model.Task{Id: "removed", TaskType: model.REFRESH_FILE_TASK}))
+
+	assert.NoError(t, p.resumeTasks(ctx))
+	assert.Equal(t, int64(1), poolMetadata(p, POOL_FFMPEG).Queued)
+	assert.Equal(t, int64(1), poolMetadata(p, POOL_METADATA).Queued)
+
+	unfinished, err := db.FindTasks(ctx, "processing_start is null")
+	assert.NoError(t, err)
+	assert.Len(t, *unfinished, 2)
+
+	task, err := p.pool(model.REFRESH_METADATA_TASK).dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, "dequeued", task.Id)
+}
+
+func TestLockedItemDoesNotBlockWorker(t *testing.T) {
+	p, _ := setupProcessor(t, "processor-parked.sqlite", 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan bool)
+	var mutex sync.Mutex
+	processed := make([]uint64, 0)
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		if task.TaskType == model.CHANGE_RESOLUTION {
+			<-release
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		processed = append(processed, taskItemId(task))
+		return nil
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	assert.NoError(t, p.EnqueueChangeResolution(ctx, 1, 640, 480, "transcode"))
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_FFMPEG).Busy == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the task of the transcoded item is parked and the metadata worker moves on
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 1, "transcoded"))
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 2, "other"))
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_METADATA).Processed == 1 && poolMetadata(p, POOL_METADATA).Queued == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_METADATA).Processed == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	assert.Equal(t, []uint64{2, 1, 1}, processed)
+	mutex.Unlock()
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5, initialBackoff: 10 * time.Second, maxBackoff: time.Minute}
+	assert.Equal(t, 10*time.Second, policy.backoff(1))
+	assert.Equal(t, 20*time.Second, policy.backoff(2))
+	assert.Equal(t, 40*time.Second, policy.backoff(3))
+	assert.Equal(t, time.Minute, policy.backoff(4))
+	assert.Equal(t, time.Minute, policy.backoff(10))
+}
+
+func TestTaskRetriesAndDeadLetter(t *testing.T) {
+	p, db := setupProcessor(t, "processor-retries.sqlite", 1, 1)
+	ctx := context.Background()
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		return fmt.Errorf("Error running process, exit code: 1, err: disk full")
+	}
+
+	pool := p.pool(model.REFRESH_FILE_TASK)
+	policy := taskRetryPolicy(model.REFRESH_FILE_TASK)
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 1, "item"))
+	task, err := pool.dequeue()
+	assert.NoError(t, err)
+
+	for attempt := 1; attempt < policy.maxAttempts; attempt++ {
+		p.process(ctx, pool, task)
+		assert.True(t, p.retries.cancel(task.Id), "retry %d is scheduled", attempt)
+
+		tasks, err := db.FindTasks(ctx, "id = ?", task.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, attempt, (*tasks)[0].Attempts)
+		assert.NotNil(t, (*tasks)[0].RetryTime)
+		assert.Nil(t, (*tasks)[0].ProcessingStart)
+		assert.False(t, (*tasks)[0].DeadLetter)
+	}
+
+	p.process(ctx, pool, task)
+	assert.False(t, p.retries.cancel(task.Id))
+	assert.Equal(t, int64(policy.maxAttempts), poolMetadata(p, POOL_METADATA).Failed)
+
+	failed, err := db.FindTasks(ctx, "dead_letter = ?", true)
+	assert.NoError(t, err)
+	assert.Len(t, *failed, 1)
+	assert.Equal(t, "Error running process, exit code: 1, err: disk full", (*failed)[0].Error)
+	assert.NotNil(t, (*failed)[0].ProcessingEnd)
+
+	// finished tasks are cleared, the dead letters stay until retried or discarded
+	assert.NoError(t, p.ClearFinishedTasks(ctx))
+	count, err := db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, p.RetryTask(ctx, task.Id))
+	assert.Equal(t, int64(1), poolMetadata(p, POOL_METADATA).Queued)
+	retried, err := db.FindTasks(ctx, "id = ?", task.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, (*retried)[0].Attempts)
+	assert.Empty(t, (*retried)[0].Error)
+	assert.False(t, (*retried)[0].DeadLetter)
+	assert.Nil(t, (*retried)[0].ProcessingEnd)
+
+	// only dead letters can be retried or discarded
+	assert.ErrorIs(t, p.RetryTask(ctx, task.Id), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, p.DiscardTask(ctx, task.Id), gorm.ErrRecordNotFound)
+
+	task, err = pool.dequeue()
+	assert.NoError(t, err)
+	for range policy.maxAttempts {
+		p.process(ctx, pool, task)
+		p.retries.cancel(task.Id)
+	}
+
+	assert.NoError(t, p.DiscardTask(ctx, task.Id))
+	count, err = db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestTruncateError(t *testing.T) {
+	assert.Equal(t, "short", truncateError("short"))
+	long := truncateError(strings.Repeat("banner ", maxErrorLength) + "the reason")
+	assert.Len(t, long, maxErrorLength+3)
+	assert.True(t, strings.HasSuffix(long, "the reason"))
+}
+
+func TestPriorityQueues(t *testing.T) {
+	p, db := setupProcessor(t, "processor-priorities.sqlite", 1, 1)
+	ctx := context.Background()
+
+	item := &model.Item{Title: "item", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	_, err := p.EnqueueAllItemsFileMetadata(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, p.EnqueueItemVideoMetadata(ctx, 2, "normal"))
+	assert.NoError(t, p.EnqueueItemFileMetadata(utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH), 3, "high"))
+
+	pool := p.pool(model.REFRESH_FILE_TASK)
+	for _, expected := range model.TaskPriorities {
+		task, err := pool.dequeue()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task.Priority)
+	}
+
+	task, err := pool.dequeue()
+	assert.NoError(t, err)
+	assert.Nil(t, task)
+}
+
+func TestReprioritizeTasks(t *testing.T) {
+	p, _ := setupProcessor(t, "processor-reprioritize.sqlite", 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mutex sync.Mutex
+	processed := make([]uint64, 0)
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		processed = append(processed, taskItemId(task))
+		return nil
+	}
+
+	for id := uint64(1); id <= 5; id++ {
+		assert.NoError(t, p.EnqueueItemFileMetadata(ctx, id, "item"))
+	}
+
+	tasks, err := p.db.FindTasks(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, p.ReprioritizeTasks(ctx, []string{(*tasks)[3].Id, (*tasks)[2].Id}, model.TASK_PRIORITY_HIGH, -1))
+	assert.NoError(t, p.ReprioritizeTasks(ctx, []string{(*tasks)[0].Id}, model.TASK_PRIORITY_LOW, -1))
+	assert.NoError(t, p.ReprioritizeTasks(ctx, []string{(*tasks)[4].Id}, model.TASK_PRIORITY_NORMAL, 0))
+	assert.ErrorIs(t, p.ReprioritizeTasks(ctx, []string{"missing"}, model.TASK_PRIORITY_LOW, -1), gorm.ErrRecordNotFound)
+
+	// the tasks are moved rather than copied
+	assert.Equal(t, int64(5), poolMetadata(p, POOL_METADATA).Queued)
+	moved, err := p.db.FindTasks(ctx, "id = ?", (*tasks)[3].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TASK_PRIORITY_HIGH, (*moved)[0].Priority)
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(processed) == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	assert.Equal(t, []uint64{4, 3, 5, 2, 1}, processed)
+	mutex.Unlock()
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestCancelTask(t *testing.T) {
+	p, db := setupProcessor(t, "processor-cancel.sqlite", 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan bool)
+	var processed atomic.Int32
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		processed.Add(1)
+		if task.TaskType == model.CHANGE_RESOLUTION {
+			started <- true
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	assert.NoError(t, p.EnqueueChangeResolution(ctx, 1, 640, 480, "running"))
+	assert.NoError(t, p.EnqueueItemCovers(ctx, 2, "queued"))
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	<-started
+	tasks, err := db.FindTasks(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, p.CancelTask(ctx, (*tasks)[1].Id))
+	assert.NoError(t, p.CancelTask(ctx, (*tasks)[0].Id))
+	assert.ErrorIs(t, p.CancelTask(ctx, (*tasks)[0].Id), gorm.ErrRecordNotFound)
+
+	// the running task is interrupted without a retry, and the queued one is skipped
+	assert.Eventually(t, func() bool {
+		return poolMetadata(p, POOL_FFMPEG).Queued == 0 && poolMetadata(p, POOL_FFMPEG).Busy == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), processed.Load())
+	assert.False(t, p.retries.cancel((*tasks)[0].Id))
+
+	count, err := db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestCoalesceTasks(t *testing.T) {
+	p, db := setupProcessor(t, "processor-coalesce.sqlite", 1, 1)
+	ctx := context.Background()
+	p.handleTask = func(ctx context.Context, task *model.Task) error {
+		return nil
+	}
+
+	for _, title := range []string{"first", "second"} {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, &model.Item{Title: title, Origin: "origin"}))
+	}
+
+	summary, err := p.EnqueueAllItemsFileMetadata(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, model.EnqueueSummary{Enqueued: 2}, *summary)
+
+	summary, err = p.EnqueueAllItemsFileMetadata(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, model.EnqueueSummary{Coalesced: 2}, *summary)
+
+	// a different task of the same item isn't coalesced
+	assert.NoError(t, p.EnqueueItemVideoMetadata(ctx, 1, "first"))
+	count, err := db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// an interactive request raises the priority of the pending task
+	assert.NoError(t, p.EnqueueItemFileMetadata(utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH), 2, "second"))
+	pool := p.pool(model.REFRESH_FILE_TASK)
+	task, err := pool.dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, model.TASK_PRIORITY_HIGH, task.Priority)
+	assert.Equal(t, uint64(2), taskItemId(task))
+
+	// a processed task isn't pending anymore
+	p.process(ctx, pool, task)
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 2, "second"))
+	count, err = db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestCoalesceOnlyQueuedTasks(t *testing.T) {
+	p, db := setupProcessor(t, "processor-coalesce-queued.sqlite", 1, 1)
+	ctx := context.Background()
+
+	// a row that isn't in the queues, and a task waiting for its retry
+	params, err := video_tasks.MarshalVideoMetadataParams(1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.CreateTask(ctx, &model.Task{Id: "unqueued", TaskType: model.REFRESH_METADATA_TASK, Params: params,
+		EnequeueTime: ptr.To(int64(1)), IdempotencyKey: idempotencyKey(&model.Task{TaskType: model.REFRESH_METADATA_TASK, Params: params})}))
+	assert.NoError(t, p.EnqueueItemCovers(ctx, 1, "item"))
+	covers, err := db.FindTasks(ctx, "task_type = ?", model.REFRESH_COVER_TASK)
+	assert.NoError(t, err)
+	(*covers)[0].RetryTime = ptr.To(time.Now().Add(time.Minute).UnixMilli())
+	assert.NoError(t, db.UpdateTask(ctx, &(*covers)[0]))
+
+	assert.NoError(t, p.EnqueueItemVideoMetadata(ctx, 1, "item"))
+	assert.NoError(t, p.EnqueueItemCovers(ctx, 1, "item"))
+	count, err := db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestBackfillIdempotencyKeys(t *testing.T) {
+	p, db := setupProcessor(t, "processor-backfill.sqlite", 1, 1)
+	ctx := context.Background()
+
+	params, err := general_tasks.MarshalFileMetadataParams(1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.CreateTask(ctx, &model.Task{Id: "old", TaskType: model.REFRESH_FILE_TASK, Params: params,
+		EnequeueTime: ptr.To(int64(1))}))
+	assert.NoError(t, p.resumeTasks(ctx))
+
+	assert.NoError(t, p.EnqueueItemFileMetadata(ctx, 1, "item"))
+	count, err := db.TasksCount(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
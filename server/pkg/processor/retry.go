@@ -0,0 +1,199 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/utils"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+// the tail of the error is kept, as ffmpeg prints the reason after its banner
+const maxErrorLength = 16 * 1024
+
+// retryPolicy retries a failed task after an exponential backoff, until it was attempted
+// maxAttempts times and moves to the dead letters
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func taskRetryPolicy(taskType model.TaskType) retryPolicy {
+	switch taskType {
+	case model.REFRESH_METADATA_TASK, model.REFRESH_FILE_TASK:
+		return retryPolicy{maxAttempts: 3, initialBackoff: 10 * time.Second, maxBackoff: time.Minute}
+	case model.CHANGE_RESOLUTION:
+		// a failed transcode is rarely transient and is expensive to repeat
+		return retryPolicy{maxAttempts: 2, initialBackoff: 5 * time.Minute, maxBackoff: 5 * time.Minute}
+	default:
+		return retryPolicy{maxAttempts: 3, initialBackoff: 30 * time.Second, maxBackoff: 10 * time.Minute}
+	}
+}
+
+// backoff is the delay after the given failed attempt, starting from 1
+func (r retryPolicy) backoff(attempt int) time.Duration {
+	backoff := r.initialBackoff
+	for i := 1; i < attempt && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, r.maxBackoff)
+}
+
+// retries holds the timers of the tasks waiting for their next attempt
+type retries struct {
+	mutex  sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newRetries() *retries {
+	return &retries{timers: make(map[string]*time.Timer)}
+}
+
+func (r *retries) schedule(taskId string, delay time.Duration, f func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if timer, ok := r.timers[taskId]; ok {
+		timer.Stop()
+	}
+
+	r.timers[taskId] = time.AfterFunc(delay, func() {
+		r.mutex.Lock()
+		delete(r.timers, taskId)
+		r.mutex.Unlock()
+		f()
+	})
+}
+
+// cancel returns whether the task was waiting for a retry
+func (r *retries) cancel(taskId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	timer, ok := r.timers[taskId]
+	if ok {
+		timer.Stop()
+		delete(r.timers, taskId)
+	}
+
+	return ok
+}
+
+func (r *retries) stopAll() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for taskId, timer := range r.timers {
+		timer.Stop()
+		delete(r.timers, taskId)
+	}
+}
+
+// taskFailed records the error of the task, and schedules its next attempt or moves it to the
+// dead letters
+func (p *Processor) taskFailed(ctx context.Context, task *model.Task, err error) {
+	policy := taskRetryPolicy(task.TaskType)
+	task.Error = truncateError(err.Error())
+
+	if task.Attempts >= policy.maxAttempts {
+		logger.Warningf("Task %s %s failed %d attempts, moving it to the dead letters", task.Id, task.TaskType, task.Attempts)
+		task.DeadLetter = true
+		task.ProcessingEnd = ptr.To(time.Now().UnixMilli())
+		if err := p.db.UpdateTask(ctx, task); err != nil {
+			logger.Warningf("Unable to update failed task %s %s", task.Id, err)
+		}
+		return
+	}
+
+	delay := policy.backoff(task.Attempts)
+	logger.Infof("Retrying task %s %s in %s, attempt %d of %d", task.Id, task.TaskType, delay, task.Attempts+1, policy.maxAttempts)
+	task.ProcessingStart = nil
+	task.RetryTime = ptr.To(time.Now().Add(delay).UnixMilli())
+	if err := p.db.UpdateTask(ctx, task); err != nil {
+		logger.Warningf("Unable to update failed task %s %s", task.Id, err)
+	}
+
+	p.scheduleRetry(task, delay)
+}
+
+func (p *Processor) scheduleRetry(task *model.Task, delay time.Duration) {
+	p.retries.schedule(task.Id, delay, func() {
+		ctx := utils.ContextWithSubject(context.Background(), "processor-retry")
+		task.RetryTime = nil
+		if err := p.db.UpdateTask(ctx, task); err != nil {
+			logger.Warningf("Unable to update retried task %s %s", task.Id, err)
+		}
+
+		if err := p.pool(task.TaskType).enqueue(task); err != nil {
+			utils.LogError(fmt.Sprintf("Error requeuing task %s", task.Id), err)
+		}
+
+		p.pushQueueMetadata(ctx)
+	})
+}
+
+// RetryTask requeues a dead letter task for a new set of attempts
+func (p *Processor) RetryTask(ctx context.Context, taskId string) error {
+	task, err := p.getDeadLetter(ctx, taskId)
+	if err != nil {
+		return err
+	}
+
+	task.Attempts = 0
+	task.Error = ""
+	task.DeadLetter = false
+	task.ProcessingStart = nil
+	task.ProcessingEnd = nil
+	task.RetryTime = nil
+	task.EnequeueTime = ptr.To(time.Now().UnixMilli())
+	if err := p.db.UpdateTask(ctx, task); err != nil {
+		return err
+	}
+
+	if err := p.pool(task.TaskType).enqueue(task); err != nil {
+		return err
+	}
+
+	return p.pushQueueMetadata(ctx)
+}
+
+// DiscardTask removes a dead letter task
+func (p *Processor) DiscardTask(ctx context.Context, taskId string) error {
+	if _, err := p.getDeadLetter(ctx, taskId); err != nil {
+		return err
+	}
+
+	if err := p.db.RemoveTasks(ctx, "id = ?", taskId); err != nil {
+		return err
+	}
+
+	return p.pushQueueMetadata(ctx)
+}
+
+func (p *Processor) getDeadLetter(ctx context.Context, taskId string) (*model.Task, error) {
+	tasks, err := p.db.FindTasks(ctx, "id = ? and dead_letter = ?", taskId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*tasks) == 0 {
+		return nil, errors.Wrap(gorm.ErrRecordNotFound, 0)
+	}
+
+	return &(*tasks)[0], nil
+}
+
+func truncateError(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+
+	return "..." + message[len(message)-maxErrorLength:]
+}
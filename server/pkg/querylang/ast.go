@@ -0,0 +1,126 @@
+package querylang
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Field string
+
+const (
+	FIELD_TAG      Field = "tag"
+	FIELD_DIR      Field = "dir"
+	FIELD_TITLE    Field = "title"
+	FIELD_DURATION Field = "duration"
+	FIELD_WIDTH    Field = "width"
+	FIELD_HEIGHT   Field = "height"
+	FIELD_SIZE     Field = "size"
+	FIELD_CODEC    Field = "codec"
+	FIELD_AUDIO    Field = "audio"
+	FIELD_KIND     Field = "kind"
+	FIELD_RATING   Field = "rating"
+	FIELD_YEAR     Field = "year"
+	FIELD_LANGUAGE Field = "language"
+	FIELD_IMDB     Field = "imdb"
+)
+
+type Operator string
+
+const (
+	OP_MATCH         Operator = ":"
+	OP_EQUAL         Operator = "="
+	OP_NOT_EQUAL     Operator = "!="
+	OP_LESS          Operator = "<"
+	OP_LESS_EQUAL    Operator = "<="
+	OP_GREATER       Operator = ">"
+	OP_GREATER_EQUAL Operator = ">="
+)
+
+type valueKind int
+
+const (
+	TEXT_VALUE valueKind = iota
+	NUMBER_VALUE
+)
+
+var fieldKinds = map[Field]valueKind{
+	FIELD_TAG:      TEXT_VALUE,
+	FIELD_DIR:      TEXT_VALUE,
+	FIELD_TITLE:    TEXT_VALUE,
+	FIELD_CODEC:    TEXT_VALUE,
+	FIELD_AUDIO:    TEXT_VALUE,
+	FIELD_KIND:     TEXT_VALUE,
+	FIELD_LANGUAGE: TEXT_VALUE,
+	FIELD_IMDB:     TEXT_VALUE,
+	FIELD_DURATION: NUMBER_VALUE,
+	FIELD_WIDTH:    NUMBER_VALUE,
+	FIELD_HEIGHT:   NUMBER_VALUE,
+	FIELD_SIZE:     NUMBER_VALUE,
+	FIELD_RATING:   NUMBER_VALUE,
+	FIELD_YEAR:     NUMBER_VALUE,
+}
+
+// the values of the kind field, the item kinds of the model
+var kindValues = []string{"regular", "sub-item", "highlight"}
+
+// Node is a parsed query expression, one of *And, *Or, *Not or *Condition
+type Node interface {
+	String() string
+}
+
+type And struct {
+	Left  Node
+	Right Node
+}
+
+type Or struct {
+	Left  Node
+	Right Node
+}
+
+type Not struct {
+	Operand Node
+}
+
+// Condition compares a single item field, number values are normalized to
+// seconds for durations and bytes for sizes
+type Condition struct {
+	Field    Field
+	Operator Operator
+	Text     string
+	Number   float64
+}
+
+func (n *And) String() string {
+	return fmt.Sprintf("(%s AND %s)", n.Left, n.Right)
+}
+
+func (n *Or) String() string {
+	return fmt.Sprintf("(%s OR %s)", n.Left, n.Right)
+}
+
+func (n *Not) String() string {
+	return fmt.Sprintf("NOT %s", n.Operand)
+}
+
+func (n *Condition) String() string {
+	if n.IsNumeric() {
+		return fmt.Sprintf("%s%s%g", n.Field, n.Operator, n.Number)
+	}
+
+	return fmt.Sprintf("%s%s%q", n.Field, n.Operator, n.Text)
+}
+
+func (n *Condition) IsNumeric() bool {
+	return fieldKinds[n.Field] == NUMBER_VALUE
+}
+
+func knownFields() string {
+	fields := []string{
+		string(FIELD_TAG), string(FIELD_DIR), string(FIELD_TITLE), string(FIELD_DURATION), string(FIELD_WIDTH),
+		string(FIELD_HEIGHT), string(FIELD_SIZE), string(FIELD_CODEC), string(FIELD_AUDIO), string(FIELD_KIND),
+		string(FIELD_RATING), string(FIELD_YEAR), string(FIELD_LANGUAGE), string(FIELD_IMDB),
+	}
+
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,152 @@
+package querylang
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	TOKEN_EOF tokenType = iota
+	TOKEN_WORD
+	TOKEN_STRING
+	TOKEN_OPERATOR
+	TOKEN_LPAREN
+	TOKEN_RPAREN
+	TOKEN_AND
+	TOKEN_OR
+	TOKEN_NOT
+)
+
+type token struct {
+	tokenType tokenType
+	text      string
+	position  int
+}
+
+func (t token) String() string {
+	if t.tokenType == TOKEN_EOF {
+		return "end of query"
+	}
+
+	return t.text
+}
+
+type lexer struct {
+	input    []rune
+	position int
+}
+
+func tokenize(input string) ([]token, error) {
+	l := &lexer{input: []rune(input)}
+	tokens := make([]token, 0)
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+		if t.tokenType == TOKEN_EOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.position < len(l.input) && unicode.IsSpace(l.input[l.position]) {
+		l.position++
+	}
+
+	start := l.position
+	if l.position >= len(l.input) {
+		return token{tokenType: TOKEN_EOF, position: start}, nil
+	}
+
+	c := l.input[l.position]
+	switch {
+	case c == '(':
+		l.position++
+		return token{tokenType: TOKEN_LPAREN, text: "(", position: start}, nil
+	case c == ')':
+		l.position++
+		return token{tokenType: TOKEN_RPAREN, text: ")", position: start}, nil
+	case c == '"':
+		return l.readString()
+	case isOperatorRune(c):
+		return l.readOperator()
+	default:
+		return l.readWord(), nil
+	}
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.position
+	l.position++
+
+	var text strings.Builder
+	for l.position < len(l.input) {
+		c := l.input[l.position]
+		l.position++
+
+		switch c {
+		case '\\':
+			if l.position < len(l.input) {
+				text.WriteRune(l.input[l.position])
+				l.position++
+			}
+		case '"':
+			return token{tokenType: TOKEN_STRING, text: text.String(), position: start}, nil
+		default:
+			text.WriteRune(c)
+		}
+	}
+
+	return token{}, newParseError(start, "unterminated string")
+}
+
+func (l *lexer) readOperator() (token, error) {
+	start := l.position
+	c := l.input[l.position]
+	l.position++
+
+	if l.position < len(l.input) && l.input[l.position] == '=' && c != ':' && c != '=' {
+		l.position++
+	}
+
+	text := string(l.input[start:l.position])
+	if text == "!" {
+		return token{}, newParseError(start, "unexpected '!', did you mean '!='?")
+	}
+
+	return token{tokenType: TOKEN_OPERATOR, text: text, position: start}, nil
+}
+
+func (l *lexer) readWord() token {
+	start := l.position
+	for l.position < len(l.input) {
+		c := l.input[l.position]
+		if unicode.IsSpace(c) || c == '(' || c == ')' || c == '"' || isOperatorRune(c) {
+			break
+		}
+
+		l.position++
+	}
+
+	text := string(l.input[start:l.position])
+	switch strings.ToUpper(text) {
+	case "AND":
+		return token{tokenType: TOKEN_AND, text: text, position: start}
+	case "OR":
+		return token{tokenType: TOKEN_OR, text: text, position: start}
+	case "NOT":
+		return token{tokenType: TOKEN_NOT, text: text, position: start}
+	default:
+		return token{tokenType: TOKEN_WORD, text: text, position: start}
+	}
+}
+
+func isOperatorRune(c rune) bool {
+	return c == ':' || c == '=' || c == '!' || c == '<' || c == '>'
+}
@@ -0,0 +1,255 @@
+package querylang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError describes why a query could not be parsed, Position is the
+// offset (in characters) of the offending token
+type ParseError struct {
+	Message  string `json:"message"`
+	Position int    `json:"position"`
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+func newParseError(position int, format string, args ...any) *ParseError {
+	return &ParseError{
+		Message:  fmt.Sprintf(format, args...),
+		Position: position,
+	}
+}
+
+// Parse builds an expression tree from a query such as
+//
+//	tag:"Action" AND NOT tag:"Watched" AND duration>20m AND dir:"movies/"
+//
+// Adjacent terms are joined with AND, a term without a field matches titles
+func Parse(query string) (Node, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().tokenType == TOKEN_EOF {
+		return nil, newParseError(0, "empty query")
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.tokenType != TOKEN_EOF {
+		return nil, newParseError(t.position, "unexpected %s", t)
+	}
+
+	return node, nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.position]
+	if t.tokenType != TOKEN_EOF {
+		p.position++
+	}
+
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().tokenType == TOKEN_OR {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().tokenType {
+		case TOKEN_AND:
+			p.advance()
+		case TOKEN_WORD, TOKEN_STRING, TOKEN_NOT, TOKEN_LPAREN:
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	t := p.advance()
+	switch t.tokenType {
+	case TOKEN_NOT:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Not{Operand: operand}, nil
+	case TOKEN_LPAREN:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.advance(); closing.tokenType != TOKEN_RPAREN {
+			return nil, newParseError(closing.position, "expected ')' but found %s", closing)
+		}
+
+		return node, nil
+	case TOKEN_STRING:
+		return &Condition{Field: FIELD_TITLE, Operator: OP_MATCH, Text: t.text}, nil
+	case TOKEN_WORD:
+		if p.peek().tokenType != TOKEN_OPERATOR {
+			return &Condition{Field: FIELD_TITLE, Operator: OP_MATCH, Text: t.text}, nil
+		}
+
+		return p.parseCondition(t)
+	default:
+		return nil, newParseError(t.position, "unexpected %s", t)
+	}
+}
+
+func (p *parser) parseCondition(fieldToken token) (Node, error) {
+	field := Field(strings.ToLower(fieldToken.text))
+	kind, ok := fieldKinds[field]
+	if !ok {
+		return nil, newParseError(fieldToken.position, "unknown field %s, expected one of %s", fieldToken.text, knownFields())
+	}
+
+	operatorToken := p.advance()
+	operator := Operator(operatorToken.text)
+	if !isValidOperator(kind, operator) {
+		return nil, newParseError(operatorToken.position, "operator %s is not supported for field %s", operator, field)
+	}
+
+	valueToken := p.advance()
+	if valueToken.tokenType != TOKEN_WORD && valueToken.tokenType != TOKEN_STRING {
+		return nil, newParseError(valueToken.position, "expected a value for %s but found %s", field, valueToken)
+	}
+
+	condition := &Condition{Field: field, Operator: operator, Text: valueToken.text}
+	if field == FIELD_KIND && !isKindValue(valueToken.text) {
+		return nil, newParseError(valueToken.position, "invalid kind value %s, expected one of %s",
+			valueToken.text, strings.Join(kindValues, ", "))
+	}
+
+	if kind == TEXT_VALUE {
+		return condition, nil
+	}
+
+	number, err := parseNumber(field, valueToken.text)
+	if err != nil {
+		return nil, newParseError(valueToken.position, "invalid %s value %s", field, valueToken.text)
+	}
+
+	condition.Number = number
+	return condition, nil
+}
+
+func isKindValue(value string) bool {
+	for _, kindValue := range kindValues {
+		if strings.EqualFold(kindValue, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isValidOperator(kind valueKind, operator Operator) bool {
+	switch operator {
+	case OP_MATCH, OP_EQUAL, OP_NOT_EQUAL:
+		return true
+	case OP_LESS, OP_LESS_EQUAL, OP_GREATER, OP_GREATER_EQUAL:
+		return kind == NUMBER_VALUE
+	default:
+		return false
+	}
+}
+
+func parseNumber(field Field, value string) (float64, error) {
+	switch field {
+	case FIELD_DURATION:
+		return parseDurationSeconds(value)
+	case FIELD_SIZE:
+		return parseBytes(value)
+	default:
+		return strconv.ParseFloat(value, 64)
+	}
+}
+
+// Plain numbers are seconds, otherwise Go duration syntax (20m, 1h30m)
+func parseDurationSeconds(value string) (float64, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return seconds, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return duration.Seconds(), nil
+}
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"kb", 1 << 10},
+	{"mb", 1 << 20},
+	{"gb", 1 << 30},
+	{"tb", 1 << 40},
+	{"b", 1},
+}
+
+func parseBytes(value string) (float64, error) {
+	lower := strings.ToLower(value)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(lower, unit.suffix) {
+			number, err := strconv.ParseFloat(strings.TrimSuffix(lower, unit.suffix), 64)
+			return number * unit.multiplier, err
+		}
+	}
+
+	return strconv.ParseFloat(lower, 64)
+}
@@ -0,0 +1,71 @@
+package querylang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`tag:"Action"`, `tag:"Action"`},
+		{`tag:"Action" AND NOT tag:"Watched" AND duration>20m AND dir:"movies/"`,
+			`(((tag:"Action" AND NOT tag:"Watched") AND duration>1200) AND dir:"movies/")`},
+		{`tag:A tag:B`, `(tag:"A" AND tag:"B")`},
+		{`tag:A OR tag:B AND tag:C`, `(tag:"A" OR (tag:"B" AND tag:"C"))`},
+		{`(tag:A OR tag:B) and not (tag:C)`, `((tag:"A" OR tag:"B") AND NOT tag:"C")`},
+		{`TAG:a or height>=1080`, `(tag:"a" OR height>=1080)`},
+		{`duration<=1h30m duration>90`, `(duration<=5400 AND duration>90)`},
+		{`size>1.5gb size!=100`, `(size>1.610612736e+09 AND size!=100)`},
+		{`matrix`, `title:"matrix"`},
+		{`"the matrix" codec=h264`, `(title:"the matrix" AND codec="h264")`},
+		{`title:"say \"hi\""`, `title:"say \"hi\""`},
+		{`kind:regular width<1920`, `(kind:"regular" AND width<1920)`},
+		{`rating>=4`, `rating>=4`},
+		{`year>=1990 year<2000 language:en`, `((year>=1990 AND year<2000) AND language:"en")`},
+		{`imdb=tt0133093`, `imdb="tt0133093"`},
+	}
+
+	for _, test := range tests {
+		node, err := Parse(test.query)
+		assert.NoError(t, err, test.query)
+		if err == nil {
+			assert.Equal(t, test.expected, node.String(), test.query)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+	}{
+		{``, 0},
+		{`   `, 0},
+		{`tag:"Action`, 4},
+		{`color:red`, 0},
+		{`tag>5`, 3},
+		{`duration>long`, 9},
+		{`size>1xb`, 5},
+		{`(tag:A`, 6},
+		{`tag:A)`, 5},
+		{`tag:A AND`, 9},
+		{`tag:`, 4},
+		{`tag!A`, 3},
+		{`NOT`, 3},
+		{`kind:movie`, 5},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.query)
+		if assert.Error(t, err, test.query) {
+			parseError, ok := err.(*ParseError)
+			assert.True(t, ok, test.query)
+			assert.Equal(t, test.position, parseError.Position, test.query)
+			assert.NotEmpty(t, parseError.Message, test.query)
+		}
+	}
+}
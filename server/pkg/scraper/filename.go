@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"my-collection/server/pkg/model"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilenameInfo is what the name of a video tells about its title
+type FilenameInfo struct {
+	Title       string
+	ReleaseYear int
+	ExternalIds model.ExternalIds // from the [imdbid-tt...] and [tmdbid-...] naming of Jellyfin and Radarr
+}
+
+var (
+	externalIdPattern = regexp.MustCompile(`(?i)[\[{](imdb|tmdb)(?:id)?[-=]([a-z0-9]+)[\]}]`)
+	bracketsPattern   = regexp.MustCompile(`\[[^\]]*\]|\{[^}]*\}`)
+	yearPattern       = regexp.MustCompile(`(?:^|[\s(\[])((?:19|20)\d{2})(?:$|[\s)\]])`)
+	spacesPattern     = regexp.MustCompile(`\s+`)
+
+	// release details that follow the title when the name has no year
+	releaseTokens = map[string]bool{
+		"480p": true, "576p": true, "720p": true, "1080p": true, "1080i": true, "2160p": true, "4k": true, "uhd": true,
+		"bluray": true, "bdrip": true, "brrip": true, "remux": true, "web-dl": true, "webdl": true, "webrip": true,
+		"web": true, "hdtv": true, "dvdrip": true, "dvd": true, "hdrip": true, "x264": true, "x265": true,
+		"h264": true, "h265": true, "hevc": true, "xvid": true, "hdr": true, "proper": true, "repack": true,
+		"extended": true, "unrated": true, "remastered": true, "directors": true, "limited": true,
+	}
+
+	// names that don't describe the content, the directory name is used instead
+	genericNames = map[string]bool{"movie": true, "video": true, "film": true, "main": true, "feature": true}
+)
+
+// ParseFilename guesses the title and year of the item, from its file name or from its directory
+// when the file name is generic such as movie.mkv
+func ParseFilename(item *model.Item) FilenameInfo {
+	name := strings.TrimSuffix(item.Title, filepath.Ext(item.Title))
+	info := parseName(name)
+	if (info.Title == "" || genericNames[strings.ToLower(info.Title)]) && item.Origin != "" {
+		dirInfo := parseName(filepath.Base(item.Origin))
+		for provider, id := range info.ExternalIds {
+			if dirInfo.ExternalIds == nil {
+				dirInfo.ExternalIds = make(model.ExternalIds)
+			}
+			dirInfo.ExternalIds[provider] = id
+		}
+		if dirInfo.ReleaseYear == 0 {
+			dirInfo.ReleaseYear = info.ReleaseYear
+		}
+		info = dirInfo
+	}
+
+	return info
+}
+
+func parseName(name string) FilenameInfo {
+	info := FilenameInfo{}
+	for _, match := range externalIdPattern.FindAllStringSubmatch(name, -1) {
+		if info.ExternalIds == nil {
+			info.ExternalIds = make(model.ExternalIds)
+		}
+		info.ExternalIds[strings.ToLower(match[1])] = match[2]
+	}
+
+	name = bracketsPattern.ReplaceAllString(name, " ")
+	if !strings.Contains(name, " ") || strings.Count(name, ".") > 2 {
+		name = strings.NewReplacer(".", " ", "_", " ").Replace(name)
+	}
+	name = strings.TrimSpace(spacesPattern.ReplaceAllString(name, " "))
+
+	// the last year is the release year, an earlier one is part of the title as in 2001 A Space Odyssey (1968)
+	matches := yearPattern.FindAllStringSubmatchIndex(name, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][2], matches[i][3]
+		if start == 0 {
+			continue
+		}
+
+		info.ReleaseYear, _ = strconv.Atoi(name[start:end])
+		name = name[:start]
+		break
+	}
+
+	if info.ReleaseYear == 0 {
+		name = cutReleaseTokens(name)
+	}
+
+	info.Title = strings.Trim(name, " -([.")
+	return info
+}
+
+func cutReleaseTokens(name string) string {
+	words := strings.Split(name, " ")
+	for i, word := range words {
+		if i > 0 && releaseTokens[strings.ToLower(word)] {
+			return strings.Join(words[:i], " ")
+		}
+	}
+
+	return name
+}
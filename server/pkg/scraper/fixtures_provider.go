@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// FixturesProvider is an offline metadata provider, each json file of its directory is a
+// model.ScrapedMetadata and poster urls are files relative to the directory
+type FixturesProvider struct {
+	name    string
+	dir     string
+	entries []model.ScrapedMetadata
+}
+
+// NewFixturesProvider loads the fixtures of dir, they act as the provider name so ids match
+// those of the real provider
+func NewFixturesProvider(name string, dir string) (*FixturesProvider, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	entries := make([]model.ScrapedMetadata, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+
+		entry := model.ScrapedMetadata{}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, errors.Errorf("invalid fixture %s - %s", file, err)
+		}
+
+		entry.Provider = name
+		entries = append(entries, entry)
+	}
+
+	return &FixturesProvider{name: name, dir: dir, entries: entries}, nil
+}
+
+func (p *FixturesProvider) Name() string {
+	return p.name
+}
+
+func (p *FixturesProvider) Search(ctx context.Context, title string, year int) ([]model.MetadataCandidate, error) {
+	query := strings.ToLower(strings.TrimSpace(title))
+	result := make([]model.MetadataCandidate, 0)
+	for _, entry := range p.entries {
+		if !strings.Contains(strings.ToLower(entry.Title), query) &&
+			!strings.Contains(strings.ToLower(entry.Metadata.OriginalTitle), query) {
+			continue
+		}
+
+		if year != 0 && entry.Metadata.ReleaseYear != 0 && entry.Metadata.ReleaseYear != year {
+			continue
+		}
+
+		result = append(result, model.MetadataCandidate{
+			Provider:      p.name,
+			Id:            entry.Id,
+			Title:         entry.Title,
+			OriginalTitle: entry.Metadata.OriginalTitle,
+			ReleaseYear:   entry.Metadata.ReleaseYear,
+		})
+	}
+
+	return result, nil
+}
+
+func (p *FixturesProvider) Fetch(ctx context.Context, id model.ExternalId) (*model.ScrapedMetadata, error) {
+	if id.Id == "" {
+		return nil, ErrNoMatch
+	}
+
+	for _, entry := range p.entries {
+		if (id.Provider == p.name && entry.Id == id.Id) || entry.Metadata.ExternalIds[id.Provider] == id.Id {
+			result := entry
+			return &result, nil
+		}
+	}
+
+	return nil, ErrNoMatch
+}
+
+func (p *FixturesProvider) FetchImage(ctx context.Context, url string) ([]byte, error) {
+	name := filepath.Clean(filepath.FromSlash(url))
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return nil, errors.Errorf("image %s is outside of the fixtures", url)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	return data, nil
+}
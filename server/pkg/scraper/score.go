@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"my-collection/server/pkg/model"
+	"strings"
+	"unicode"
+)
+
+// minScore accepts an exact title without a known year, or a partial title of the same year
+const minScore = 3
+
+// Score rates how well a candidate matches a title and year guessed from a file name
+func Score(title string, year int, candidate *model.MetadataCandidate) int {
+	score := 0
+	normalized := normalizeTitle(title)
+	titles := []string{normalizeTitle(candidate.Title), normalizeTitle(candidate.OriginalTitle)}
+	for _, candidateTitle := range titles {
+		if candidateTitle == "" {
+			continue
+		}
+
+		if candidateTitle == normalized {
+			score = max(score, 3)
+		} else if strings.Contains(candidateTitle, normalized) || strings.Contains(normalized, candidateTitle) {
+			score = max(score, 1)
+		}
+	}
+
+	if year == 0 || candidate.ReleaseYear == 0 {
+		return score
+	}
+
+	switch diff := year - candidate.ReleaseYear; {
+	case diff == 0:
+		score += 2
+	case diff == 1 || diff == -1:
+		// festival and regional releases are often a year apart
+		score += 1
+	default:
+		score -= 3
+	}
+
+	return score
+}
+
+// normalizeTitle ignores case, punctuation and a leading article
+func normalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,385 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/metadata"
+	"my-collection/server/pkg/bl/tags"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/nfo"
+	"my-collection/server/pkg/utils"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("scraper")
+
+// castLimit is how many of the billed actors become tags
+const castLimit = 10
+
+var ErrNoMatch = fmt.Errorf("no confident match")
+
+// MetadataProvider is a source of movie metadata, see the tmdb package and FixturesProvider
+type MetadataProvider interface {
+	Name() string
+	// Search returns the candidates of a title, most relevant first, year is 0 when unknown
+	Search(ctx context.Context, title string, year int) ([]model.MetadataCandidate, error)
+	// Fetch returns the metadata of an id of the provider, or of another provider it can
+	// look up such as imdb, ErrNoMatch if it's unknown
+	Fetch(ctx context.Context, id model.ExternalId) (*model.ScrapedMetadata, error)
+	FetchImage(ctx context.Context, url string) ([]byte, error)
+}
+
+type scraperDb interface {
+	model.ItemReaderWriter
+	model.ItemMetadataWriter
+	model.TagReaderWriter
+}
+
+// New creates the parent tags of the scraped fields, keyed by the nfo fields genre, actor and
+// director, a field without a parent isn't scraped into tags
+func New(ctx context.Context, db scraperDb, uploader model.StorageUploader, provider MetadataProvider,
+	parents map[string]string) (*Scraper, error) {
+	parentTags := make(map[string]*model.Tag)
+	for _, field := range []string{nfo.FIELD_GENRE, nfo.FIELD_ACTOR, nfo.FIELD_DIRECTOR} {
+		title, ok := parents[field]
+		if !ok {
+			continue
+		}
+
+		tag, err := tags.GetOrCreateTag(ctx, db, &model.Tag{Title: title})
+		if err != nil {
+			return nil, err
+		}
+
+		parentTags[field] = tag
+	}
+
+	return &Scraper{
+		db:             db,
+		uploader:       uploader,
+		provider:       provider,
+		parentTags:     parentTags,
+		triggerChannel: make(chan bool, 1),
+	}, nil
+}
+
+type Scraper struct {
+	db             scraperDb
+	uploader       model.StorageUploader
+	provider       MetadataProvider
+	parentTags     map[string]*model.Tag
+	triggerChannel chan bool
+	mutex          sync.Mutex
+}
+
+// EnqueueScrapeMissing scrapes the items without an id of the provider in the background
+func (s *Scraper) EnqueueScrapeMissing() {
+	select {
+	case s.triggerChannel <- true:
+	default:
+		logger.Infof("Scraping missing items already pending")
+	}
+}
+
+func (s *Scraper) Run(ctx context.Context) error {
+	ctx = utils.ContextWithSubject(ctx, "scraper")
+	for {
+		select {
+		case <-s.triggerChannel:
+			if _, err := s.ScrapeMissing(ctx); err != nil {
+				utils.LogError("Error scraping missing items", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// ScrapeMissing scrapes the items that were never matched, highlights and sub items share the
+// metadata of their main item and are skipped
+func (s *Scraper) ScrapeMissing(ctx context.Context) (int, error) {
+	allItems, err := s.db.GetAllItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	matched := 0
+	for _, item := range *allItems {
+		if ctx.Err() != nil {
+			return matched, nil
+		}
+
+		if items.IsHighlight(&item) || items.IsSubItem(&item) || item.ExternalIds[s.provider.Name()] != "" {
+			continue
+		}
+
+		result, err := s.ScrapeItem(ctx, item.Id, nil)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error scraping item %d", item.Id), err)
+			continue
+		}
+
+		if result.Matched {
+			matched++
+		}
+	}
+
+	logger.Infof("Scraped %d of %d items", matched, len(*allItems))
+	return matched, nil
+}
+
+// Candidates searches the provider for the title of the item, or for the given title and year
+func (s *Scraper) Candidates(ctx context.Context, itemId uint64, title string, year int) ([]model.MetadataCandidate, error) {
+	item, err := s.db.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	if title == "" {
+		info := ParseFilename(item)
+		title = info.Title
+		if year == 0 {
+			year = info.ReleaseYear
+		}
+	}
+
+	if year == 0 {
+		year = item.ReleaseYear
+	}
+
+	candidates, err := s.provider.Search(ctx, title, year)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		candidates[i].Score = Score(title, year, &candidates[i])
+	}
+
+	return candidates, nil
+}
+
+// ScrapeItem matches the item with the provider and applies the metadata, id overrides the
+// matching with a choice of the user, an unmatched item is reported and isn't an error
+func (s *Scraper) ScrapeItem(ctx context.Context, itemId uint64, id *model.ExternalId) (*model.ScrapeResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, err := s.db.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ScrapeResult{ItemId: itemId}
+	scraped, err := s.match(ctx, item, id)
+	if errors.Is(err, ErrNoMatch) {
+		logger.Infof("No match for item %d %s", item.Id, item.Title)
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Matched = true
+	result.Provider = scraped.Provider
+	result.Id = scraped.Id
+	result.Title = scraped.Title
+	logger.Infof("Matched item %d %s with %s %s %s", item.Id, item.Title, scraped.Provider, scraped.Id, scraped.Title)
+
+	if result.Poster, err = s.applyPoster(ctx, item, scraped); err != nil {
+		utils.LogError(fmt.Sprintf("Error saving poster of item %d", item.Id), err)
+	}
+
+	if err := s.applyMetadata(ctx, item, scraped, id != nil); err != nil {
+		return nil, err
+	}
+
+	if result.Tags, err = s.applyTags(ctx, item, scraped); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// match prefers the ids the item already has, from the user, the nfo or the file name
+func (s *Scraper) match(ctx context.Context, item *model.Item, id *model.ExternalId) (*model.ScrapedMetadata, error) {
+	if id != nil {
+		return s.provider.Fetch(ctx, *id)
+	}
+
+	info := ParseFilename(item)
+	ids := make(model.ExternalIds)
+	for provider, value := range info.ExternalIds {
+		ids[provider] = value
+	}
+	for provider, value := range item.ExternalIds {
+		ids[provider] = value
+	}
+
+	for _, provider := range []string{s.provider.Name(), model.EXTERNAL_ID_IMDB} {
+		if value, ok := ids[provider]; ok {
+			scraped, err := s.provider.Fetch(ctx, model.ExternalId{Provider: provider, Id: value})
+			if err == nil || !errors.Is(err, ErrNoMatch) {
+				return scraped, err
+			}
+		}
+	}
+
+	if info.Title == "" {
+		return nil, ErrNoMatch
+	}
+
+	year := info.ReleaseYear
+	if year == 0 {
+		year = item.ReleaseYear
+	}
+
+	candidate, err := s.bestCandidate(ctx, info.Title, year)
+	if errors.Is(err, ErrNoMatch) && info.ReleaseYear != 0 {
+		// the year may be part of the title, as in Blade Runner 2049
+		candidate, err = s.bestCandidate(ctx, fmt.Sprintf("%s %d", info.Title, info.ReleaseYear), 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.provider.Fetch(ctx, model.ExternalId{Provider: candidate.Provider, Id: candidate.Id})
+}
+
+func (s *Scraper) bestCandidate(ctx context.Context, title string, year int) (*model.MetadataCandidate, error) {
+	candidates, err := s.provider.Search(ctx, title, year)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *model.MetadataCandidate
+	for i := range candidates {
+		candidates[i].Score = Score(title, year, &candidates[i])
+		if candidates[i].Score >= minScore && (best == nil || candidates[i].Score > best.Score) {
+			best = &candidates[i]
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoMatch
+	}
+
+	return best, nil
+}
+
+func (s *Scraper) applyMetadata(ctx context.Context, item *model.Item, scraped *model.ScrapedMetadata, chosen bool) error {
+	imported := scraped.Metadata
+	metadata.Normalize(&imported)
+	if err := metadata.Validate(&imported); err != nil {
+		logger.Warningf("Ignoring invalid metadata of %s %s - %s", scraped.Provider, scraped.Id, err)
+		imported = model.ItemMetadata{}
+	}
+
+	// a match chosen by the user replaces a previous one, which may have been wrong, so the
+	// fields that come from the provider are overwritten rather than merged
+	if chosen {
+		item.ReleaseYear = imported.ReleaseYear
+		item.OriginalTitle = imported.OriginalTitle
+		item.Description = imported.Description
+		item.Language = imported.Language
+		delete(item.ExternalIds, scraped.Provider)
+		delete(item.ExternalIds, model.EXTERNAL_ID_IMDB)
+	}
+
+	metadata.Merge(&item.ItemMetadata, &imported)
+	metadata.Merge(&item.ItemMetadata, &model.ItemMetadata{ExternalIds: model.ExternalIds{scraped.Provider: scraped.Id}})
+	return s.db.SetItemMetadata(ctx, item.Id, &item.ItemMetadata)
+}
+
+func (s *Scraper) applyTags(ctx context.Context, item *model.Item, scraped *model.ScrapedMetadata) (int, error) {
+	cast := scraped.Cast
+	if len(cast) > castLimit {
+		cast = cast[:castLimit]
+	}
+
+	fields := map[string][]string{
+		nfo.FIELD_GENRE:    scraped.Genres,
+		nfo.FIELD_ACTOR:    cast,
+		nfo.FIELD_DIRECTOR: scraped.Directors,
+	}
+
+	scrapedTags := make([]*model.Tag, 0)
+	for field, parent := range s.parentTags {
+		for _, value := range fields[field] {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			tag, err := tags.GetOrCreateChildTag(ctx, s.db, parent.Id, value)
+			if err != nil {
+				return 0, err
+			}
+
+			scrapedTags = append(scrapedTags, tag)
+		}
+	}
+
+	if _, err := items.EnsureItemHaveTags(ctx, s.db, item, scrapedTags); err != nil {
+		return 0, err
+	}
+
+	return len(scrapedTags), nil
+}
+
+// applyPoster adds the poster as a cover, and as the main cover unless one was chosen
+func (s *Scraper) applyPoster(ctx context.Context, item *model.Item, scraped *model.ScrapedMetadata) (bool, error) {
+	if scraped.PosterUrl == "" {
+		return false, nil
+	}
+
+	data, err := s.provider.FetchImage(ctx, scraped.PosterUrl)
+	if err != nil {
+		return false, err
+	}
+
+	ext := path.Ext(scraped.PosterUrl)
+	if ext == "" || len(ext) > 5 {
+		ext = ".jpg"
+	}
+
+	relativeFile := fmt.Sprintf("covers/%d/poster%s", item.Id, ext)
+	storageFile, err := s.uploader.GetFileForWriting(relativeFile)
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.WriteFile(storageFile, data, 0640); err != nil {
+		return false, errors.Wrap(err, 0)
+	}
+
+	url := s.uploader.GetStorageUrl(relativeFile)
+	if !hasCover(item, url) {
+		item.Covers = append(item.Covers, model.Cover{Url: url})
+	}
+
+	if item.MainCoverUrl == nil {
+		item.MainCoverUrl = &url
+	}
+	item.MainCoverNonce = time.Now().UnixMilli()
+
+	return true, s.db.UpdateItem(ctx, item)
+}
+
+func hasCover(item *model.Item, url string) bool {
+	for _, cover := range item.Covers {
+		if cover.Url == url {
+			return true
+		}
+	}
+
+	return false
+}
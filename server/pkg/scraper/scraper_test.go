@@ -0,0 +1,183 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/nfo"
+	"my-collection/server/pkg/storage"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func setupScraper(t *testing.T, filename string) (*Scraper, db.Database, *storage.Storage) {
+	db := setupNewDb(t, filename)
+	s, err := storage.New(t.TempDir())
+	assert.NoError(t, err)
+	provider, err := NewFixturesProvider(model.EXTERNAL_ID_TMDB, filepath.Join("testdata", "fixtures"))
+	assert.NoError(t, err)
+	parents, err := nfo.ParseTagParents(nfo.DefaultTagParents)
+	assert.NoError(t, err)
+	scraper, err := New(context.Background(), db, s, provider, parents)
+	assert.NoError(t, err)
+	return scraper, db, s
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		title    string
+		origin   string
+		expected FilenameInfo
+	}{
+		{"The.Matrix.1999.1080p.BluRay.x264.mkv", "movies", FilenameInfo{Title: "The Matrix", ReleaseYear: 1999}},
+		{"The Matrix (1999).mkv", "movies", FilenameInfo{Title: "The Matrix", ReleaseYear: 1999}},
+		{"1917 (2019) [1080p].mp4", "movies", FilenameInfo{Title: "1917", ReleaseYear: 2019}},
+		{"Heat.720p.WEB-DL.mkv", "movies", FilenameInfo{Title: "Heat"}},
+		{"Blade_Runner_2049.mkv", "movies", FilenameInfo{Title: "Blade Runner", ReleaseYear: 2049}},
+		{"movie.mkv", "movies/Inside Llewyn Davis (2013) [imdbid-tt2042568]", FilenameInfo{Title: "Inside Llewyn Davis",
+			ReleaseYear: 2013, ExternalIds: model.ExternalIds{"imdb": "tt2042568"}}},
+		{"Heat (1995) {tmdb-949}.mkv", "movies", FilenameInfo{Title: "Heat", ReleaseYear: 1995,
+			ExternalIds: model.ExternalIds{"tmdb": "949"}}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ParseFilename(&model.Item{Title: test.title, Origin: test.origin}), test.title)
+	}
+}
+
+func TestScore(t *testing.T) {
+	matrix := &model.MetadataCandidate{Title: "The Matrix", ReleaseYear: 1999}
+	assert.Equal(t, 5, Score("the matrix", 1999, matrix))
+	assert.Equal(t, 3, Score("Matrix", 0, matrix))
+	assert.Equal(t, 4, Score("The Matrix", 2000, matrix))
+	assert.Equal(t, 0, Score("The Matrix", 2021, matrix))
+	assert.Equal(t, 3, Score("The Matrix Reloaded", 1999, matrix))
+	assert.Less(t, Score("Heat", 0, matrix), minScore)
+}
+
+func TestScrapeItem(t *testing.T) {
+	scraper, db, s := setupScraper(t, "scraper.sqlite")
+	ctx := context.Background()
+
+	item := &model.Item{Title: "The.Matrix.1999.1080p.BluRay.x264.mkv", Origin: "movies",
+		ItemMetadata: model.ItemMetadata{Description: "my own description"}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+
+	result, err := scraper.ScrapeItem(ctx, item.Id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &model.ScrapeResult{ItemId: item.Id, Matched: true, Provider: "tmdb", Id: "603",
+		Title: "The Matrix", Tags: 7, Poster: true}, result)
+
+	item, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1999, item.ReleaseYear)
+	assert.Equal(t, "my own description", item.Description)
+	assert.Equal(t, model.ExternalIds{"tmdb": "603", "imdb": "tt0133093"}, item.ExternalIds)
+	assert.Len(t, item.Tags, 7)
+
+	posterUrl := s.GetStorageUrl(fmt.Sprintf("covers/%d/poster.jpg", item.Id))
+	assert.Equal(t, posterUrl, *item.MainCoverUrl)
+	assert.Equal(t, posterUrl, item.Covers[0].Url)
+	data, err := os.ReadFile(s.GetFile(fmt.Sprintf("covers/%d/poster.jpg", item.Id)))
+	assert.NoError(t, err)
+	assert.Equal(t, "poster", string(data))
+
+	genres, err := db.GetTag(ctx, model.Tag{Title: "Genres"})
+	assert.NoError(t, err)
+	assert.Len(t, genres.Children, 2)
+
+	// scraping again doesn't duplicate the tags nor the poster
+	_, err = scraper.ScrapeItem(ctx, item.Id, nil)
+	assert.NoError(t, err)
+	item, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Len(t, item.Tags, 7)
+	assert.Len(t, item.Covers, 1)
+
+	// a match chosen by the user replaces the metadata of the previous one
+	_, err = scraper.ScrapeItem(ctx, item.Id, &model.ExternalId{Provider: "tmdb", Id: "604"})
+	assert.NoError(t, err)
+	item, err = db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 2003, item.ReleaseYear)
+	assert.Equal(t, "The Matrix Reloaded", item.OriginalTitle)
+	assert.Empty(t, item.Description)
+	assert.Equal(t, model.ExternalIds{"tmdb": "604", "imdb": "tt0234215"}, item.ExternalIds)
+}
+
+func TestScrapeItemMatching(t *testing.T) {
+	scraper, db, _ := setupScraper(t, "scraper-matching.sqlite")
+	ctx := context.Background()
+
+	tests := []struct {
+		item     *model.Item
+		expected string
+	}{
+		{&model.Item{Title: "Blade.Runner.2049.mkv", Origin: "movies"}, "335984"},
+		{&model.Item{Title: "movie.mkv", Origin: "movies/Inside Llewyn Davis (2013) [imdbid-tt2042568]"}, "86829"},
+		{&model.Item{Title: "Reloaded.mkv", Origin: "movies", ItemMetadata: model.ItemMetadata{
+			ExternalIds: model.ExternalIds{"imdb": "tt0234215"}}}, "604"},
+		{&model.Item{Title: "The Matrix (2021).mkv", Origin: "movies"}, ""},
+		{&model.Item{Title: "Home Video.mkv", Origin: "movies"}, ""},
+	}
+
+	for _, test := range tests {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, test.item))
+		result, err := scraper.ScrapeItem(ctx, test.item.Id, nil)
+		assert.NoError(t, err, test.item.Title)
+		assert.Equal(t, test.expected != "", result.Matched, test.item.Title)
+		assert.Equal(t, test.expected, result.Id, test.item.Title)
+	}
+
+	// the user picks the match of an unmatched item
+	unmatched := tests[3].item
+	result, err := scraper.ScrapeItem(ctx, unmatched.Id, &model.ExternalId{Provider: "tmdb", Id: "603"})
+	assert.NoError(t, err)
+	assert.True(t, result.Matched)
+
+	// the year of the matched item narrows the search
+	candidates, err := scraper.Candidates(ctx, unmatched.Id, "matrix", 0)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 1)
+	candidates, err = scraper.Candidates(ctx, tests[4].item.Id, "matrix", 0)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 2)
+}
+
+func TestScrapeMissing(t *testing.T) {
+	scraper, db, _ := setupScraper(t, "scraper-missing.sqlite")
+	ctx := context.Background()
+
+	items := []*model.Item{
+		{Title: "The Matrix (1999).mkv", Origin: "movies"},
+		{Title: "Home Video.mkv", Origin: "movies"},
+		{Title: "Blade Runner 2049 (2017).mkv", Origin: "movies",
+			ItemMetadata: model.ItemMetadata{ExternalIds: model.ExternalIds{"tmdb": "335984"}}},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	matched, err := scraper.ScrapeMissing(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, matched)
+
+	item, err := db.GetItem(ctx, items[2].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item.ReleaseYear, "already matched items are skipped")
+}
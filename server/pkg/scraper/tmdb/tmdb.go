@@ -0,0 +1,268 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/scraper"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// The v3 API of themoviedb.org, other services implementing the same endpoints can be used
+// by changing the urls
+// https://developer.themoviedb.org/reference/search-movie
+const (
+	DefaultApiUrl   = "https://api.themoviedb.org/3"
+	DefaultImageUrl = "https://image.tmdb.org/t/p/w780"
+
+	requestTimeout = 30 * time.Second
+)
+
+func New(apiUrl string, imageUrl string, apiKey string, language string) *Tmdb {
+	return &Tmdb{
+		apiUrl:   strings.TrimSuffix(apiUrl, "/"),
+		imageUrl: strings.TrimSuffix(imageUrl, "/"),
+		apiKey:   apiKey,
+		language: language,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type Tmdb struct {
+	apiUrl   string
+	imageUrl string
+	apiKey   string // a v3 api key, or a v4 read access token
+	language string // of the titles and overviews, such as en-US, empty for the service default
+	client   *http.Client
+}
+
+type searchResponse struct {
+	Results []movie `json:"results"`
+}
+
+type findResponse struct {
+	MovieResults []movie `json:"movie_results"`
+}
+
+type movie struct {
+	Id               int64  `json:"id"`
+	Title            string `json:"title"`
+	OriginalTitle    string `json:"original_title"`
+	OriginalLanguage string `json:"original_language"`
+	Overview         string `json:"overview"`
+	ReleaseDate      string `json:"release_date"` // yyyy-mm-dd
+	PosterPath       string `json:"poster_path"`
+	ImdbId           string `json:"imdb_id"`
+	Genres           []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Credits struct {
+		Cast []struct {
+			Name  string `json:"name"`
+			Order int    `json:"order"`
+		} `json:"cast"`
+		Crew []struct {
+			Name string `json:"name"`
+			Job  string `json:"job"`
+		} `json:"crew"`
+	} `json:"credits"`
+}
+
+func (t *Tmdb) Name() string {
+	return model.EXTERNAL_ID_TMDB
+}
+
+func (t *Tmdb) Search(ctx context.Context, title string, year int) ([]model.MetadataCandidate, error) {
+	query := url.Values{}
+	query.Set("query", title)
+	query.Set("include_adult", "false")
+	if year != 0 {
+		query.Set("year", strconv.Itoa(year))
+	}
+
+	response := searchResponse{}
+	if err := t.get(ctx, "/search/movie", query, &response); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.MetadataCandidate, 0, len(response.Results))
+	for _, m := range response.Results {
+		result = append(result, model.MetadataCandidate{
+			Provider:      t.Name(),
+			Id:            strconv.FormatInt(m.Id, 10),
+			Title:         m.Title,
+			OriginalTitle: m.OriginalTitle,
+			ReleaseYear:   releaseYear(m.ReleaseDate),
+		})
+	}
+
+	return result, nil
+}
+
+// Fetch accepts tmdb ids, and imdb ids which are looked up first
+func (t *Tmdb) Fetch(ctx context.Context, id model.ExternalId) (*model.ScrapedMetadata, error) {
+	switch id.Provider {
+	case model.EXTERNAL_ID_TMDB:
+		return t.fetchMovie(ctx, id.Id)
+	case model.EXTERNAL_ID_IMDB:
+		query := url.Values{}
+		query.Set("external_source", "imdb_id")
+		response := findResponse{}
+		if err := t.get(ctx, "/find/"+url.PathEscape(id.Id), query, &response); err != nil {
+			return nil, err
+		}
+
+		if len(response.MovieResults) == 0 {
+			return nil, scraper.ErrNoMatch
+		}
+
+		return t.fetchMovie(ctx, strconv.FormatInt(response.MovieResults[0].Id, 10))
+	default:
+		return nil, scraper.ErrNoMatch
+	}
+}
+
+func (t *Tmdb) fetchMovie(ctx context.Context, id string) (*model.ScrapedMetadata, error) {
+	query := url.Values{}
+	query.Set("append_to_response", "credits")
+	m := movie{}
+	if err := t.get(ctx, "/movie/"+url.PathEscape(id), query, &m); err != nil {
+		return nil, err
+	}
+
+	result := &model.ScrapedMetadata{
+		Provider: t.Name(),
+		Id:       strconv.FormatInt(m.Id, 10),
+		Title:    m.Title,
+		Metadata: model.ItemMetadata{
+			ReleaseYear:   releaseYear(m.ReleaseDate),
+			OriginalTitle: m.OriginalTitle,
+			Description:   m.Overview,
+			Language:      m.OriginalLanguage,
+			ExternalIds:   model.ExternalIds{model.EXTERNAL_ID_TMDB: strconv.FormatInt(m.Id, 10)},
+		},
+	}
+
+	if m.ImdbId != "" {
+		result.Metadata.ExternalIds[model.EXTERNAL_ID_IMDB] = m.ImdbId
+	}
+
+	if m.PosterPath != "" {
+		result.PosterUrl = t.imageUrl + m.PosterPath
+	}
+
+	for _, genre := range m.Genres {
+		result.Genres = append(result.Genres, genre.Name)
+	}
+
+	// the cast is ordered by billing already
+	for _, cast := range m.Credits.Cast {
+		result.Cast = append(result.Cast, cast.Name)
+	}
+
+	for _, crew := range m.Credits.Crew {
+		if crew.Job == "Director" {
+			result.Directors = append(result.Directors, crew.Name)
+		}
+	}
+
+	return result, nil
+}
+
+func (t *Tmdb) FetchImage(ctx context.Context, imageUrl string) ([]byte, error) {
+	if !strings.HasPrefix(imageUrl, t.imageUrl+"/") {
+		return nil, errors.Errorf("image %s isn't a tmdb image", imageUrl)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageUrl, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	return t.do(req)
+}
+
+func (t *Tmdb) get(ctx context.Context, path string, query url.Values, v any) error {
+	if t.language != "" {
+		query.Set("language", t.language)
+	}
+
+	// v4 read access tokens are JWTs, v3 keys are sent as a parameter
+	bearer := strings.Count(t.apiKey, ".") == 2
+	if !bearer {
+		query.Set("api_key", t.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.apiUrl+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if bearer {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	body, err := t.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.Errorf("invalid response of %s - %s", path, err)
+	}
+
+	return nil
+}
+
+func (t *Tmdb) do(req *http.Request) ([]byte, error) {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// the url of the error holds the api key
+		var urlError *url.Error
+		if errors.As(err, &urlError) {
+			err = urlError.Err
+		}
+		return nil, errors.Errorf("tmdb %s request failed - %s", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, scraper.ErrNoMatch
+	default:
+		return nil, errors.Errorf("tmdb %s returned status %d: %s", req.URL.Path, resp.StatusCode, truncate(string(body), 200))
+	}
+}
+
+func releaseYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+
+	return fmt.Sprintf("%s...", s[:length])
+}
@@ -0,0 +1,106 @@
+package tmdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/scraper"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestServer serves the responses in testdata, the requests are recorded
+func setupTestServer(t *testing.T, requests *[]*http.Request) *httptest.Server {
+	routes := map[string]string{
+		"/3/search/movie":      "search_movie.json",
+		"/3/movie/603":         "movie_603.json",
+		"/3/find/tt0133093":    "find_tt0133093.json",
+		"/t/p/w780/poster.jpg": "",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		file, ok := routes[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"status_message":"The resource you requested could not be found."}`, http.StatusNotFound)
+			return
+		}
+
+		if file == "" {
+			w.Write([]byte("poster"))
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", file))
+		assert.NoError(t, err)
+		w.Write(data)
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSearch(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	server := setupTestServer(t, &requests)
+	tmdb := New(server.URL+"/3", server.URL+"/t/p/w780", "key", "en-US")
+
+	candidates, err := tmdb.Search(context.Background(), "the matrix", 1999)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, model.MetadataCandidate{Provider: "tmdb", Id: "603", Title: "The Matrix",
+		OriginalTitle: "The Matrix", ReleaseYear: 1999}, candidates[0])
+
+	query := requests[0].URL.Query()
+	assert.Equal(t, "the matrix", query.Get("query"))
+	assert.Equal(t, "1999", query.Get("year"))
+	assert.Equal(t, "key", query.Get("api_key"))
+	assert.Equal(t, "en-US", query.Get("language"))
+}
+
+func TestFetch(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	server := setupTestServer(t, &requests)
+	tmdb := New(server.URL+"/3", server.URL+"/t/p/w780", "header.payload.signature", "")
+	ctx := context.Background()
+
+	scraped, err := tmdb.Fetch(ctx, model.ExternalId{Provider: model.EXTERNAL_ID_TMDB, Id: "603"})
+	assert.NoError(t, err)
+	assert.Equal(t, "The Matrix", scraped.Title)
+	assert.Equal(t, 1999, scraped.Metadata.ReleaseYear)
+	assert.Equal(t, "en", scraped.Metadata.Language)
+	assert.Equal(t, model.ExternalIds{"tmdb": "603", "imdb": "tt0133093"}, scraped.Metadata.ExternalIds)
+	assert.Equal(t, []string{"Action", "Science Fiction"}, scraped.Genres)
+	assert.Equal(t, []string{"Keanu Reeves", "Laurence Fishburne"}, scraped.Cast)
+	assert.Equal(t, []string{"Lana Wachowski", "Lilly Wachowski"}, scraped.Directors)
+	assert.Equal(t, server.URL+"/t/p/w780/f89U3ADr1oiB1s9GkdPOEpXUk5H.jpg", scraped.PosterUrl)
+	assert.Equal(t, "Bearer header.payload.signature", requests[0].Header.Get("Authorization"))
+	assert.Empty(t, requests[0].URL.Query().Get("api_key"))
+
+	scraped, err = tmdb.Fetch(ctx, model.ExternalId{Provider: model.EXTERNAL_ID_IMDB, Id: "tt0133093"})
+	assert.NoError(t, err)
+	assert.Equal(t, "603", scraped.Id)
+
+	_, err = tmdb.Fetch(ctx, model.ExternalId{Provider: model.EXTERNAL_ID_TMDB, Id: "1"})
+	assert.ErrorIs(t, err, scraper.ErrNoMatch)
+	_, err = tmdb.Fetch(ctx, model.ExternalId{Provider: "other", Id: "1"})
+	assert.ErrorIs(t, err, scraper.ErrNoMatch)
+}
+
+func TestFetchImage(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	server := setupTestServer(t, &requests)
+	tmdb := New(server.URL+"/3", server.URL+"/t/p/w780", "key", "")
+
+	data, err := tmdb.FetchImage(context.Background(), server.URL+"/t/p/w780/poster.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, "poster", string(data))
+
+	_, err = tmdb.FetchImage(context.Background(), "http://elsewhere/poster.jpg")
+	assert.Error(t, err)
+}
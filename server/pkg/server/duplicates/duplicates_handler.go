@@ -0,0 +1,129 @@
+package duplicates
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"my-collection/server/pkg/bl/duplicates"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+)
+
+type duplicatesHandlerDb interface {
+	duplicates.MergeDb
+	model.VideoHashReader
+}
+
+type duplicatesHandlerProcessor interface {
+	EnqueueAllItemsVideoHash(ctx context.Context, force bool) (*model.EnqueueSummary, error)
+}
+
+type mergeRequest struct {
+	KeeperId        uint64   `json:"keeperId"`
+	DuplicateIds    []uint64 `json:"duplicates"`
+	DeleteRealFiles bool     `json:"deleteRealFiles"`
+}
+
+func NewHandler(db duplicatesHandlerDb, processor duplicatesHandlerProcessor) *duplicatesHandler {
+	return &duplicatesHandler{
+		db:        db,
+		processor: processor,
+	}
+}
+
+type duplicatesHandler struct {
+	db        duplicatesHandlerDb
+	processor duplicatesHandlerProcessor
+}
+
+func (s *duplicatesHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/duplicates", s.getDuplicates)
+	rg.POST("/duplicates/merge", s.mergeDuplicates)
+	rg.POST("/duplicates/hash", s.hashAllItems)
+}
+
+func (s *duplicatesHandler) getDuplicates(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	maxDistance, err := parseNonNegativeFloat(c.Query("maxDistance"), duplicates.DefaultMaxDistance)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	durationTolerance, err := parseNonNegativeFloat(c.Query("durationTolerance"), duplicates.DefaultDurationTolerance)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	groups, err := duplicates.FindDuplicates(ctx, s.db, s.db, maxDistance, durationTolerance)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+func (s *duplicatesHandler) mergeDuplicates(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	var request mergeRequest
+	if server.HandleBadRequest(c, readJson(c, &request), nil) {
+		return
+	}
+
+	if request.KeeperId == 0 || len(request.DuplicateIds) == 0 {
+		server.HandleBadRequest(c, errors.Errorf("expected a keeper and duplicates"), nil)
+		return
+	}
+
+	if server.HandleError(c, duplicates.MergeDuplicates(ctx, s.db, request.KeeperId,
+		request.DuplicateIds, request.DeleteRealFiles)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *duplicatesHandler) hashAllItems(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	force, err := strconv.ParseBool(c.Query("force"))
+	if err != nil {
+		force = false
+	}
+
+	summary, err := s.processor.EnqueueAllItemsVideoHash(ctx, force)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func parseNonNegativeFloat(value string, defaultValue float64) (float64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, 0)
+	}
+
+	if result < 0 {
+		return 0, errors.Errorf("expected a non negative value, got %s", value)
+	}
+
+	return result, nil
+}
+
+func readJson(c *gin.Context, v any) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
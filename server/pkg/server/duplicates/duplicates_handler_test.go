@@ -0,0 +1,231 @@
+package duplicates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDuplicatesHandlerDb is a mock implementation of duplicatesHandlerDb interface
+type MockDuplicatesHandlerDb struct {
+	mock.Mock
+}
+
+func (m *MockDuplicatesHandlerDb) GetItem(ctx context.Context, conds ...interface{}) (*model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Item), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) GetItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) GetAllItems(ctx context.Context) (*[]model.Item, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) CreateOrUpdateItem(ctx context.Context, item *model.Item) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) UpdateItem(ctx context.Context, item *model.Item) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) RemoveItem(ctx context.Context, itemId uint64) error {
+	args := m.Called(ctx, itemId)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) RemoveTagFromItem(ctx context.Context, itemId uint64, tagId uint64) error {
+	args := m.Called(ctx, itemId, tagId)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) GetTag(ctx context.Context, conds ...interface{}) (*model.Tag, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) GetTags(ctx context.Context, conds ...interface{}) (*[]model.Tag, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Tag), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) GetAllTags(ctx context.Context) (*[]model.Tag, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Tag), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) GetTagsWithoutChildren(ctx context.Context, conds ...interface{}) (*[]model.Tag, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Tag), args.Error(1)
+}
+
+func (m *MockDuplicatesHandlerDb) UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error {
+	args := m.Called(ctx, itemIds, addedTagIds, removedTagIds)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) SetItemRating(ctx context.Context, itemId uint64, rating int) error {
+	args := m.Called(ctx, itemId, rating)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error {
+	args := m.Called(ctx, itemId, favorite)
+	return args.Error(0)
+}
+
+func (m *MockDuplicatesHandlerDb) GetVideoHashes(ctx context.Context) (*[]model.VideoHash, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.VideoHash), args.Error(1)
+}
+
+// MockDuplicatesHandlerProcessor is a mock implementation of duplicatesHandlerProcessor interface
+type MockDuplicatesHandlerProcessor struct {
+	mock.Mock
+}
+
+func (m *MockDuplicatesHandlerProcessor) EnqueueAllItemsVideoHash(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
+	args := m.Called(ctx, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EnqueueSummary), args.Error(1)
+}
+
+func setupTestRouter(mockDb *MockDuplicatesHandlerDb, mockProcessor *MockDuplicatesHandlerProcessor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb, mockProcessor).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestGetDuplicates(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockDuplicatesHandlerDb)
+		router := setupTestRouter(mockDb, new(MockDuplicatesHandlerProcessor))
+
+		mockDb.On("GetVideoHashes", mock.Anything).Return(&[]model.VideoHash{
+			{ItemId: 1, Hashes: "000000000000000f"},
+			{ItemId: 2, Hashes: "000000000000001f"},
+		}, nil)
+		mockDb.On("GetAllItems", mock.Anything).Return(&[]model.Item{
+			{Id: 1, DurationSeconds: 60, Width: 640, Height: 480},
+			{Id: 2, DurationSeconds: 60, Width: 1280, Height: 720},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/duplicates?maxDistance=2", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var groups []model.DuplicateGroup
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &groups))
+		assert.Len(t, groups, 1)
+		assert.Equal(t, uint64(2), groups[0].KeeperId)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Max Distance", func(t *testing.T) {
+		mockDb := new(MockDuplicatesHandlerDb)
+		router := setupTestRouter(mockDb, new(MockDuplicatesHandlerProcessor))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/duplicates?maxDistance=-1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDb.AssertNotCalled(t, "GetVideoHashes", mock.Anything)
+	})
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockDuplicatesHandlerDb)
+		router := setupTestRouter(mockDb, new(MockDuplicatesHandlerProcessor))
+
+		mockDb.On("GetItem", mock.Anything, uint64(1)).Return(&model.Item{Id: 1}, nil)
+		mockDb.On("GetItems", mock.Anything, []uint64{2}).Return(&[]model.Item{{Id: 2, Rating: 3}}, nil)
+		mockDb.On("SetItemRating", mock.Anything, uint64(1), 3).Return(nil)
+		mockDb.On("RemoveItem", mock.Anything, uint64(2)).Return(nil)
+
+		body, _ := json.Marshal(mergeRequest{KeeperId: 1, DuplicateIds: []uint64{2}})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/duplicates/merge", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Missing Duplicates", func(t *testing.T) {
+		mockDb := new(MockDuplicatesHandlerDb)
+		router := setupTestRouter(mockDb, new(MockDuplicatesHandlerProcessor))
+
+		body, _ := json.Marshal(mergeRequest{KeeperId: 1})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/duplicates/merge", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDb.AssertNotCalled(t, "RemoveItem", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHashAllItems(t *testing.T) {
+	mockProcessor := new(MockDuplicatesHandlerProcessor)
+	router := setupTestRouter(new(MockDuplicatesHandlerDb), mockProcessor)
+
+	summary := &model.EnqueueSummary{Enqueued: 3, Coalesced: 2}
+	mockProcessor.On("EnqueueAllItemsVideoHash", mock.Anything, true).Return(summary, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/duplicates/hash?force=true", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.EnqueueSummary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, *summary, response)
+	mockProcessor.AssertExpectations(t)
+}
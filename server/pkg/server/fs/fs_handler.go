@@ -1,16 +1,20 @@
 package fs
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/bl/fs"
+	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/fssync"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/server"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
 	"github.com/op/go-logging"
 )
 
@@ -22,6 +26,11 @@ type fsDb interface {
 
 type fsDirectoryChangedListener interface {
 	DirectoryChanged()
+	SyncDirectories(ctx context.Context, paths []string) error
+	PlanSync(ctx context.Context, paths []string) (*directorytree.Plan, error)
+	HeldPlan() *directorytree.Plan
+	ApplyPlan(ctx context.Context, id string) error
+	DiscardPlan(id string) error
 }
 
 func NewHandler(db fsDb, changeListener fsDirectoryChangedListener) *fsHandler {
@@ -42,6 +51,10 @@ func (s *fsHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/fs", s.getFsDir)
 	rg.POST("/fs/include", s.includeDir)
 	rg.POST("/fs/exclude", s.excludeDir)
+	rg.GET("/fs/sync/plan", s.planSync)
+	rg.GET("/fs/sync/held", s.getHeldPlan)
+	rg.POST("/fs/sync/held/:id/apply", s.applyHeldPlan)
+	rg.DELETE("/fs/sync/held/:id", s.discardHeldPlan)
 }
 
 func (s *fsHandler) getFsDir(c *gin.Context) {
@@ -122,6 +135,74 @@ func (s *fsHandler) SetDirectoryTags(c *gin.Context) {
 }
 
 func (s *fsHandler) runDirectoriesScan(c *gin.Context) {
-	logger.Infof("Triggering directory scan")
-	s.changeListener.DirectoryChanged()
+	path := c.Query("path")
+	if path == "" {
+		logger.Infof("Triggering directory scan")
+		s.changeListener.DirectoryChanged()
+		return
+	}
+
+	ctx := server.ContextWithSubject(c)
+	scope, err := fs.ScanScope(path)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	logger.Infof("Scanning directory %s", scope)
+	if s.handleSyncError(c, s.changeListener.SyncDirectories(ctx, []string{scope})) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *fsHandler) planSync(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	scopes := make([]string, 0)
+	for _, path := range c.QueryArray("path") {
+		scope, err := fs.ScanScope(path)
+		if server.HandleBadRequest(c, err, nil) {
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+
+	plan, err := s.changeListener.PlanSync(ctx, scopes)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (s *fsHandler) getHeldPlan(c *gin.Context) {
+	c.JSON(http.StatusOK, s.changeListener.HeldPlan())
+}
+
+func (s *fsHandler) applyHeldPlan(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if s.handleSyncError(c, s.changeListener.ApplyPlan(ctx, c.Param("id"))) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *fsHandler) discardHeldPlan(c *gin.Context) {
+	if server.HandleError(c, s.changeListener.DiscardPlan(c.Param("id"))) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// handleSyncError responds with the held plan when a sync was held for confirmation
+func (s *fsHandler) handleSyncError(c *gin.Context, err error) bool {
+	if errors.Is(err, fssync.ErrSyncHeld) {
+		logger.Warningf("Sync held, removes too many items")
+		c.AbortWithStatusJSON(http.StatusConflict, s.changeListener.HeldPlan())
+		return true
+	}
+
+	return server.HandleError(c, err)
 }
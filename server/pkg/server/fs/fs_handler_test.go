@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,7 +12,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"my-collection/server/pkg/directorytree"
+	"my-collection/server/pkg/fssync"
 	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/relativasor"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -78,6 +82,37 @@ func (m *MockFsDirectoryChangedListener) DirectoryChanged() {
 	m.Called()
 }
 
+func (m *MockFsDirectoryChangedListener) SyncDirectories(ctx context.Context, paths []string) error {
+	args := m.Called(ctx, paths)
+	return args.Error(0)
+}
+
+func (m *MockFsDirectoryChangedListener) PlanSync(ctx context.Context, paths []string) (*directorytree.Plan, error) {
+	args := m.Called(ctx, paths)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*directorytree.Plan), args.Error(1)
+}
+
+func (m *MockFsDirectoryChangedListener) HeldPlan() *directorytree.Plan {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*directorytree.Plan)
+}
+
+func (m *MockFsDirectoryChangedListener) ApplyPlan(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockFsDirectoryChangedListener) DiscardPlan(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 // Test setup functions
 func setupFsTestHandler() (*fsHandler, *MockFsDb, *MockFsDirectoryChangedListener) {
 	mockDb := &MockFsDb{}
@@ -454,6 +489,174 @@ func TestFsRunDirectoriesScan(t *testing.T) {
 
 		mockListener.AssertExpectations(t)
 	})
+
+	t.Run("Scoped Scan", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+		relativasor.Init("/root/dir")
+
+		mockListener.On("SyncDirectories", mock.Anything, []string{"movies/new"}).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/directories/scan?path=/root/dir/movies/new/", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockListener.AssertExpectations(t)
+		mockListener.AssertNotCalled(t, "DirectoryChanged")
+	})
+
+	t.Run("Scoped Scan Outside Root", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+		relativasor.Init("/root/dir")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/directories/scan?path=../other", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockListener.AssertNotCalled(t, "SyncDirectories", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Scoped Scan Error", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("SyncDirectories", mock.Anything, []string{"movies"}).Return(errors.New("sync failed"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/directories/scan?path=movies", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Scoped Scan Held", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		plan := &directorytree.Plan{Id: "plan-1", Held: true}
+		mockListener.On("SyncDirectories", mock.Anything, []string{"movies"}).Return(fssync.ErrSyncHeld)
+		mockListener.On("HeldPlan").Return(plan)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/directories/scan?path=movies", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response directorytree.Plan
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "plan-1", response.Id)
+	})
+}
+
+func TestFsSyncPlan(t *testing.T) {
+	t.Run("Plan", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+		relativasor.Init("/root/dir")
+
+		diff := &directorytree.Diff{RemovedFiles: []directorytree.Change{{Path1: "movies/a.mp4", ChangeType: directorytree.FILE_REMOVED}}}
+		plan := directorytree.NewPlan([]string{"movies", "shows"}, diff, &directorytree.Stale{}, 4)
+		mockListener.On("PlanSync", mock.Anything, []string{"movies", "shows"}).Return(plan, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/fs/sync/plan?path=movies&path=/root/dir/shows", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response directorytree.Plan
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Counts.RemovedItems)
+		assert.Equal(t, 0.25, response.RemovalFraction)
+		assert.Equal(t, "movies/a.mp4", response.Diff.RemovedFiles[0].Path1)
+	})
+
+	t.Run("Plan Outside Root", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+		relativasor.Init("/root/dir")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/fs/sync/plan?path=../other", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockListener.AssertNotCalled(t, "PlanSync", mock.Anything, mock.Anything)
+	})
+
+	t.Run("No Held Plan", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("HeldPlan").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/fs/sync/held", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "null", w.Body.String())
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("ApplyPlan", mock.Anything, "plan-1").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/fs/sync/held/plan-1/apply", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockListener.AssertExpectations(t)
+	})
+
+	t.Run("Apply Unknown Plan", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("ApplyPlan", mock.Anything, "plan-2").Return(fssync.ErrPlanNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/fs/sync/held/plan-2/apply", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Apply Changed Plan", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("ApplyPlan", mock.Anything, "plan-1").Return(fssync.ErrSyncHeld)
+		mockListener.On("HeldPlan").Return(&directorytree.Plan{Id: "plan-2", Held: true})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/fs/sync/held/plan-1/apply", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response directorytree.Plan
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "plan-2", response.Id)
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		handler, _, mockListener := setupFsTestHandler()
+		router := setupFsTestRouter(handler)
+
+		mockListener.On("DiscardPlan", "plan-1").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/fs/sync/held/plan-1", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockListener.AssertExpectations(t)
+	})
 }
 
 // Tests for route registration
@@ -481,6 +684,10 @@ func TestFsRegisterRoutes(t *testing.T) {
 		{"GET", "/api/fs"},
 		{"POST", "/api/fs/include"},
 		{"POST", "/api/fs/exclude"},
+		{"GET", "/api/fs/sync/plan"},
+		{"GET", "/api/fs/sync/held"},
+		{"POST", "/api/fs/sync/held/:id/apply"},
+		{"DELETE", "/api/fs/sync/held/:id"},
 	}
 
 	for _, expectedRoute := range expectedRoutes {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"my-collection/server/pkg/bl/items"
 	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
 	"my-collection/server/pkg/relativasor"
 	"my-collection/server/pkg/server"
 	"my-collection/server/pkg/suggestions"
@@ -20,9 +21,16 @@ import (
 
 var logger = logging.MustGetLogger("items-handler")
 
+const (
+	defaultSuggestionsCount = 8
+	maxSuggestionsCount     = 100
+)
+
 type itemsHandlerDb interface {
 	model.ItemReaderWriter
+	model.ItemsQuerier
 	model.TagReader
+	model.ItemsTagsUpdater
 }
 
 type itemsHandlerProcessor interface {
@@ -55,7 +63,9 @@ type itemsHandler struct {
 func (s *itemsHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg = rg.Group("items")
 	rg.GET("", s.getItems)
+	rg.GET("/search", s.searchItems)
 	rg.POST("", s.createItem)
+	rg.POST("/bulk", s.bulk)
 	rg.POST("/:item", s.updateItem)
 	rg.GET("/:item", s.getItem)
 	rg.DELETE("/:item", s.deleteItem)
@@ -121,6 +131,36 @@ func (s *itemsHandler) updateItem(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (s *itemsHandler) bulk(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	body, err := io.ReadAll(c.Request.Body)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var request model.BulkRequest
+	if server.HandleError(c, json.Unmarshal(body, &request)) {
+		return
+	}
+
+	result, err := items.ExecuteBulk(ctx, s.db, s.processor, s.optimizer, &request)
+	var invalidRequest *items.InvalidBulkRequestError
+	var parseError *querylang.ParseError
+	if errors.As(err, &invalidRequest) {
+		server.HandleBadRequest(c, err, nil)
+		return
+	}
+	if errors.As(err, &parseError) {
+		server.HandleBadRequest(c, err, parseError)
+		return
+	}
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (s *itemsHandler) getItem(c *gin.Context) {
 	ctx := server.ContextWithSubject(c)
 	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
@@ -183,13 +223,45 @@ func (s *itemsHandler) getItemLocation(c *gin.Context) {
 
 func (s *itemsHandler) getItems(c *gin.Context) {
 	ctx := server.ContextWithSubject(c)
-	items, err := s.db.GetAllItems(ctx)
+	query, err := parseItemsQuery(c)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	page, err := s.db.QueryItems(ctx, query)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Get items return %d/%d items", len(page.Items), page.Total)
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *itemsHandler) searchItems(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	query, err := parseItemsQuery(c)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	expression, err := querylang.Parse(c.Query("q"))
+	var parseError *querylang.ParseError
+	if errors.As(err, &parseError) {
+		server.HandleBadRequest(c, err, parseError)
+		return
+	}
+	if server.HandleError(c, err) {
+		return
+	}
+
+	query.Expression = expression
+	page, err := s.db.QueryItems(ctx, query)
 	if server.HandleError(c, err) {
 		return
 	}
 
-	logger.Infof("Get items return %d items", len(*items))
-	c.JSON(http.StatusOK, items)
+	logger.Infof("Search items %s return %d/%d items", expression, len(page.Items), page.Total)
+	c.JSON(http.StatusOK, page)
 }
 
 func (s *itemsHandler) removeTagFromItem(c *gin.Context) {
@@ -228,6 +300,7 @@ func (s *itemsHandler) setMainCover(c *gin.Context) {
 	}
 
 	logger.Infof("Setting main cover for item %d at %d", itemId, second)
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH)
 	utils.LogWarning("EnqueueMainCover", s.processor.EnqueueMainCover(ctx, itemId, second, item.Title))
 	c.Status(http.StatusOK)
 }
@@ -250,6 +323,7 @@ func (s *itemsHandler) splitItem(c *gin.Context) {
 		return
 	}
 
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH)
 	for _, item := range changedItems {
 		utils.LogWarning("EnqueueItemVideoMetadata", s.processor.EnqueueItemVideoMetadata(ctx, item.Id, item.Title))
 		utils.LogWarning("EnqueueItemCovers", s.processor.EnqueueItemCovers(ctx, item.Id, item.Title))
@@ -288,6 +362,7 @@ func (s *itemsHandler) makeHighlight(c *gin.Context) {
 		return
 	}
 
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH)
 	utils.LogWarning("EnqueueItemVideoMetadata", s.processor.EnqueueItemVideoMetadata(ctx, highlightItem.Id, highlightItem.Title))
 	utils.LogWarning("EnqueueItemCovers", s.processor.EnqueueItemCovers(ctx, highlightItem.Id, highlightItem.Title))
 	utils.LogWarning("EnqueueItemPreview", s.processor.EnqueueItemPreview(ctx, highlightItem.Id, highlightItem.Title))
@@ -339,17 +414,28 @@ func (s *itemsHandler) cropFrame(c *gin.Context) {
 	}
 
 	logger.Infof("Cropping frame for item %d at %f %s", itemId, second, rect)
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH)
 	utils.LogWarning("EnqueueCropFrame", s.processor.EnqueueCropFrame(ctx, itemId, second, rect, item.Title))
 }
 
 func (s *itemsHandler) getSuggestionsForItem(c *gin.Context) {
 	ctx := server.ContextWithSubject(c)
 	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
-	if server.HandleError(c, err) {
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(defaultSuggestionsCount)))
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	if count <= 0 || count > maxSuggestionsCount {
+		server.HandleBadRequest(c, errors.Errorf("invalid suggestions count %d", count), nil)
 		return
 	}
 
-	result, err := suggestions.GetSuggestionsForItem(ctx, s.db, s.db, itemId, 8)
+	result, err := suggestions.GetSuggestionsForItem(ctx, s.db, s.db, itemId, count)
 	if server.HandleError(c, err) {
 		return
 	}
@@ -368,6 +454,7 @@ func (s *itemsHandler) refreshItem(c *gin.Context) {
 		return
 	}
 
+	ctx = utils.ContextWithTaskPriority(ctx, model.TASK_PRIORITY_HIGH)
 	utils.LogWarning("EnqueueItemVideoMetadata", s.processor.EnqueueItemVideoMetadata(ctx, item.Id, item.Title))
 	utils.LogWarning("EnqueueItemCovers", s.processor.EnqueueItemCovers(ctx, item.Id, item.Title))
 	utils.LogWarning("EnqueueItemPreview", s.processor.EnqueueItemPreview(ctx, item.Id, item.Title))
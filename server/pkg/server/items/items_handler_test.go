@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"my-collection/server/pkg/model"
@@ -14,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"k8s.io/utils/ptr"
 )
 
 // MockItemsHandlerDb is a mock implementation of itemsHandlerDb interface
@@ -45,6 +48,14 @@ func (m *MockItemsHandlerDb) GetAllItems(ctx context.Context) (*[]model.Item, er
 	return args.Get(0).(*[]model.Item), args.Error(1)
 }
 
+func (m *MockItemsHandlerDb) QueryItems(ctx context.Context, query *model.ItemsQuery) (*model.ItemsPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ItemsPage), args.Error(1)
+}
+
 func (m *MockItemsHandlerDb) CreateOrUpdateItem(ctx context.Context, item *model.Item) error {
 	args := m.Called(ctx, item)
 	return args.Error(0)
@@ -97,6 +108,11 @@ func (m *MockItemsHandlerDb) GetTagsWithoutChildren(ctx context.Context, conds .
 	return args.Get(0).(*[]model.Tag), args.Error(1)
 }
 
+func (m *MockItemsHandlerDb) UpdateItemsTags(ctx context.Context, itemIds []uint64, addedTagIds []uint64, removedTagIds []uint64) error {
+	args := m.Called(ctx, itemIds, addedTagIds, removedTagIds)
+	return args.Error(0)
+}
+
 // MockItemsHandlerProcessor is a mock implementation of itemsHandlerProcessor interface
 type MockItemsHandlerProcessor struct {
 	mock.Mock
@@ -169,12 +185,16 @@ func TestGetItems(t *testing.T) {
 	router := setupTestRouter(handler)
 
 	t.Run("Success", func(t *testing.T) {
-		expectedItems := &[]model.Item{
-			{Id: 1, Title: "Item 1", Origin: "/path/to/item1"},
-			{Id: 2, Title: "Item 2", Origin: "/path/to/item2"},
+		expectedPage := &model.ItemsPage{
+			Items: []model.Item{
+				{Id: 1, Title: "Item 1", Origin: "/path/to/item1"},
+				{Id: 2, Title: "Item 2", Origin: "/path/to/item2"},
+			},
+			Total: 2,
 		}
 
-		mockDb.On("GetAllItems", mock.Anything).Return(expectedItems, nil)
+		mockDb.On("QueryItems", mock.Anything, &model.ItemsQuery{AllTags: []uint64{}, AnyTags: []uint64{}, ExcludedTags: []uint64{}}).
+			Return(expectedPage, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/items", nil)
@@ -182,20 +202,82 @@ func TestGetItems(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
-		var responseItems []model.Item
-		err := json.Unmarshal(w.Body.Bytes(), &responseItems)
+		var responsePage model.ItemsPage
+		err := json.Unmarshal(w.Body.Bytes(), &responsePage)
 		assert.NoError(t, err)
-		assert.Equal(t, *expectedItems, responseItems)
+		assert.Equal(t, *expectedPage, responsePage)
 
 		mockDb.AssertExpectations(t)
 	})
 
+	t.Run("Paging Sorting And Filters", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		expectedQuery := &model.ItemsQuery{
+			Offset:        20,
+			Limit:         10,
+			SortBy:        model.SORT_BY_DURATION,
+			Descending:    true,
+			AllTags:       []uint64{1, 2},
+			AnyTags:       []uint64{3, 4},
+			ExcludedTags:  []uint64{5},
+			MinDuration:   ptr.To(60.0),
+			MaxDuration:   ptr.To(600.5),
+			MinResolution: ptr.To(720),
+			MaxResolution: ptr.To(1080),
+			Codec:         "h264",
+			OriginPrefix:  "movies/",
+			Kind:          model.ITEM_KIND_REGULAR,
+			MinRating:     ptr.To(3),
+			MaxRating:     ptr.To(5),
+			Favorite:      ptr.To(true),
+			MinYear:       ptr.To(1990),
+			MaxYear:       ptr.To(1999),
+			Language:      "en",
+			ExternalId:    &model.ExternalId{Provider: model.EXTERNAL_ID_IMDB, Id: "tt0133093"},
+		}
+
+		mockDb.On("QueryItems", mock.Anything, expectedQuery).Return(&model.ItemsPage{Total: 35, Offset: 20, Limit: 10}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items?offset=20&limit=10&sortBy=duration&sortOrder=desc"+
+			"&allTags=1,2&anyTags=3&anyTags=4&excludedTags=5&minDuration=60&maxDuration=600.5"+
+			"&minResolution=720&maxResolution=1080&codec=h264&origin=movies/&kind=regular"+
+			"&minRating=3&maxRating=5&favorite=true&minYear=1990&maxYear=1999&language=en&externalId=imdb:tt0133093", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responsePage model.ItemsPage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responsePage))
+		assert.Equal(t, int64(35), responsePage.Total)
+
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		for _, query := range []string{"limit=abc", "offset=-1", "sortOrder=up", "allTags=1,x", "minDuration=long", "favorite=maybe",
+			"minYear=old", "externalId=tt0133093", "externalId=imdb:133093", "sortBy=color", "kind=movie"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/items?"+query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+
+		mockDb.AssertNotCalled(t, "QueryItems", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Database Error", func(t *testing.T) {
 		// Create new handler for this test to avoid mock conflicts
 		handler, mockDb, _, _ := setupTestHandler()
 		router := setupTestRouter(handler)
 
-		mockDb.On("GetAllItems", mock.Anything).Return(nil, fmt.Errorf("database error"))
+		mockDb.On("QueryItems", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("database error"))
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/items", nil)
@@ -207,6 +289,72 @@ func TestGetItems(t *testing.T) {
 	})
 }
 
+func TestSearchItems(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		expectedPage := &model.ItemsPage{
+			Items: []model.Item{{Id: 1, Title: "Item 1"}},
+			Total: 1,
+			Limit: 10,
+		}
+
+		mockDb.On("QueryItems", mock.Anything, mock.MatchedBy(func(query *model.ItemsQuery) bool {
+			return query.Limit == 10 && query.Expression != nil &&
+				query.Expression.String() == `(tag:"Action" AND NOT tag:"Watched")`
+		})).Return(expectedPage, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/search?limit=10&q="+url.QueryEscape(`tag:"Action" AND NOT tag:"Watched"`), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responsePage model.ItemsPage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responsePage))
+		assert.Equal(t, *expectedPage, responsePage)
+
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Parse Error", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/search?q="+url.QueryEscape(`tag:"Action" AND color:red`), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response struct {
+			Error   string `json:"error"`
+			Details struct {
+				Message  string `json:"message"`
+				Position int    `json:"position"`
+			} `json:"details"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Error)
+		assert.Contains(t, response.Details.Message, "unknown field color")
+		assert.Equal(t, 17, response.Details.Position)
+
+		mockDb.AssertNotCalled(t, "QueryItems", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing Query", func(t *testing.T) {
+		handler, _, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/search", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestCreateItem(t *testing.T) {
 	handler, mockDb, _, _ := setupTestHandler()
 	router := setupTestRouter(handler)
@@ -629,6 +777,128 @@ func TestRefreshItem(t *testing.T) {
 	})
 }
 
+func TestBulk(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		handler, mockDb, mockProcessor, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		items := &[]model.Item{{Id: 1, Title: "a.mp4"}, {Id: 2, Title: "b.mp4"}}
+		mockDb.On("GetTagsWithoutChildren", mock.Anything, []uint64{10, 11}).
+			Return(&[]model.Tag{{Id: 10}, {Id: 11}}, nil)
+		mockDb.On("GetItems", mock.Anything, []uint64{1, 2, 3}).Return(items, nil)
+		mockDb.On("UpdateItemsTags", mock.Anything, []uint64{1, 2}, []uint64{10}, []uint64{11}).Return(nil)
+		mockProcessor.On("EnqueueItemCovers", mock.Anything, uint64(1), "a.mp4").Return(nil)
+		mockProcessor.On("EnqueueItemCovers", mock.Anything, uint64(2), "b.mp4").Return(nil)
+
+		body, _ := json.Marshal(model.BulkRequest{
+			ItemIds: []uint64{1, 2, 3},
+			Operations: []model.BulkOperation{
+				{Type: model.BULK_ADD_TAGS, TagIds: []uint64{10}},
+				{Type: model.BULK_REMOVE_TAGS, TagIds: []uint64{11}},
+				{Type: model.BULK_COVERS},
+			},
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/bulk", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result model.BulkResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 2, result.Succeeded)
+		assert.Equal(t, 1, result.Failed)
+		assert.Len(t, result.Items, 3)
+		assert.Equal(t, uint64(3), result.Items[0].ItemId)
+		assert.False(t, result.Items[0].Success)
+
+		mockDb.AssertExpectations(t)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("By Query With Delete", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		mockDb.On("QueryItems", mock.Anything, mock.MatchedBy(func(query *model.ItemsQuery) bool {
+			return query.Expression != nil && query.Expression.String() == `tag:"old"`
+		})).Return(&model.ItemsPage{Items: []model.Item{{Id: 1, Title: "a.mp4"}}, Total: 1}, nil)
+		mockDb.On("UpdateItemsTags", mock.Anything, []uint64{1}, []uint64{}, []uint64{}).Return(nil)
+		mockDb.On("RemoveItem", mock.Anything, uint64(1)).Return(nil)
+
+		body, _ := json.Marshal(model.BulkRequest{
+			Query:      "tag:old",
+			Operations: []model.BulkOperation{{Type: model.BULK_DELETE}},
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/bulk", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result model.BulkResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 1, result.Succeeded)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Failed Tags Transaction", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		mockDb.On("GetTagsWithoutChildren", mock.Anything, []uint64{10}).Return(&[]model.Tag{{Id: 10}}, nil)
+		mockDb.On("GetItems", mock.Anything, []uint64{1}).Return(&[]model.Item{{Id: 1}}, nil)
+		mockDb.On("UpdateItemsTags", mock.Anything, []uint64{1}, []uint64{10}, []uint64{}).
+			Return(errors.New("database is locked"))
+
+		body, _ := json.Marshal(model.BulkRequest{
+			ItemIds: []uint64{1},
+			Operations: []model.BulkOperation{
+				{Type: model.BULK_ADD_TAGS, TagIds: []uint64{10}},
+				{Type: model.BULK_DELETE},
+			},
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/bulk", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result model.BulkResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 1, result.Failed)
+		mockDb.AssertNotCalled(t, "RemoveItem", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Requests", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		mockDb.On("GetTagsWithoutChildren", mock.Anything, []uint64{99}).Return(&[]model.Tag{}, nil)
+
+		requests := []model.BulkRequest{
+			{ItemIds: []uint64{1}},
+			{ItemIds: []uint64{1}, Operations: []model.BulkOperation{{Type: "rename"}}},
+			{ItemIds: []uint64{1}, Operations: []model.BulkOperation{{Type: model.BULK_ADD_TAGS}}},
+			{ItemIds: []uint64{1}, Operations: []model.BulkOperation{{Type: model.BULK_ADD_TAGS, TagIds: []uint64{99}}}},
+			{Operations: []model.BulkOperation{{Type: model.BULK_COVERS}}},
+			{ItemIds: []uint64{1}, Query: "tag:a", Operations: []model.BulkOperation{{Type: model.BULK_COVERS}}},
+			{Query: "tag:", Operations: []model.BulkOperation{{Type: model.BULK_COVERS}}},
+		}
+
+		for _, request := range requests {
+			body, _ := json.Marshal(request)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/items/bulk", bytes.NewBuffer(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, string(body))
+		}
+
+		mockDb.AssertNotCalled(t, "UpdateItemsTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestOptimizeItem(t *testing.T) {
 	handler, mockDb, _, mockOptimizer := setupTestHandler()
 	router := setupTestRouter(handler)
@@ -914,9 +1184,59 @@ func TestGetSuggestionsForItem(t *testing.T) {
 		// The suggestions should succeed with proper mocks
 		assert.Equal(t, http.StatusOK, w.Code)
 
+		var result []model.Suggestion
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		assert.NoError(t, err)
+		assert.Len(t, result, 8)
+
 		mockDb.AssertExpectations(t)
 	})
 
+	t.Run("Custom Count", func(t *testing.T) {
+		handler, mockDb, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		itemId := uint64(123)
+		testItem := &model.Item{Id: itemId, Title: "test.mp4"}
+		allItems := &[]model.Item{
+			*testItem,
+			{Id: 2, Title: "item2.mp4"},
+			{Id: 3, Title: "item3.mp4"},
+			{Id: 4, Title: "item4.mp4"},
+		}
+
+		mockDb.On("GetItem", mock.Anything, itemId).Return(testItem, nil)
+		mockDb.On("GetAllItems", mock.Anything).Return(allItems, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/items/%d/suggestions?count=2", itemId), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result []model.Suggestion
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("Invalid Count", func(t *testing.T) {
+		handler, _, _, _ := setupTestHandler()
+		router := setupTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/123/suggestions?count=0", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/items/123/suggestions?count=many", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("Invalid Item ID", func(t *testing.T) {
 		handler, _, _, _ := setupTestHandler()
 		router := setupTestRouter(handler)
@@ -925,7 +1245,7 @@ func TestGetSuggestionsForItem(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/api/items/invalid/suggestions", nil)
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
 
@@ -935,7 +1255,7 @@ func TestErrorHandling(t *testing.T) {
 	router := setupTestRouter(handler)
 
 	t.Run("Database Connection Error", func(t *testing.T) {
-		mockDb.On("GetAllItems", mock.Anything).Return(nil, fmt.Errorf("connection refused"))
+		mockDb.On("QueryItems", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("connection refused"))
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/items", nil)
@@ -1055,12 +1375,15 @@ func TestConcurrentAccess(t *testing.T) {
 			handler, mockDb, _, _ := setupTestHandler()
 			router := setupTestRouter(handler)
 
-			expectedItems := &[]model.Item{
-				{Id: 1, Title: "Item 1"},
-				{Id: 2, Title: "Item 2"},
+			expectedPage := &model.ItemsPage{
+				Items: []model.Item{
+					{Id: 1, Title: "Item 1"},
+					{Id: 2, Title: "Item 2"},
+				},
+				Total: 2,
 			}
 
-			mockDb.On("GetAllItems", mock.Anything).Return(expectedItems, nil)
+			mockDb.On("QueryItems", mock.Anything, mock.Anything).Return(expectedPage, nil)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("GET", "/api/items", nil)
@@ -1077,12 +1400,15 @@ func BenchmarkGetItems(b *testing.B) {
 	handler, mockDb, _, _ := setupTestHandler()
 	router := setupTestRouter(handler)
 
-	expectedItems := &[]model.Item{
-		{Id: 1, Title: "Item 1"},
-		{Id: 2, Title: "Item 2"},
+	expectedPage := &model.ItemsPage{
+		Items: []model.Item{
+			{Id: 1, Title: "Item 1"},
+			{Id: 2, Title: "Item 2"},
+		},
+		Total: 2,
 	}
 
-	mockDb.On("GetAllItems", mock.Anything).Return(expectedItems, nil)
+	mockDb.On("QueryItems", mock.Anything, mock.Anything).Return(expectedPage, nil)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
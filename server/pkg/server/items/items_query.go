@@ -0,0 +1,187 @@
+package items
+
+import (
+	"my-collection/server/pkg/bl/metadata"
+	"my-collection/server/pkg/model"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+)
+
+func parseItemsQuery(c *gin.Context) (*model.ItemsQuery, error) {
+	query := &model.ItemsQuery{
+		SortBy:       model.ItemsSortKey(c.Query("sortBy")),
+		Codec:        c.Query("codec"),
+		OriginPrefix: c.Query("origin"),
+		Kind:         model.ItemKind(c.Query("kind")),
+		Language:     c.Query("language"),
+	}
+
+	switch query.SortBy {
+	case model.SORT_BY_ID, model.SORT_BY_TITLE, model.SORT_BY_DURATION, model.SORT_BY_FILE_SIZE,
+		model.SORT_BY_LAST_MODIFIED, model.SORT_BY_RESOLUTION, model.SORT_BY_RATING:
+	default:
+		return nil, errors.Errorf("invalid sort key %s", query.SortBy)
+	}
+
+	switch query.Kind {
+	case model.ITEM_KIND_ANY, model.ITEM_KIND_REGULAR, model.ITEM_KIND_SUB_ITEM, model.ITEM_KIND_HIGHLIGHT:
+	default:
+		return nil, errors.Errorf("invalid item kind %s", query.Kind)
+	}
+
+	var err error
+	if query.Offset, err = parseOptionalInt(c, "offset", 0); err != nil {
+		return nil, err
+	}
+	if query.Limit, err = parseOptionalInt(c, "limit", 0); err != nil {
+		return nil, err
+	}
+	if query.Offset < 0 || query.Limit < 0 {
+		return nil, errors.Errorf("invalid offset %d or limit %d", query.Offset, query.Limit)
+	}
+
+	switch c.Query("sortOrder") {
+	case "", "asc":
+		query.Descending = false
+	case "desc":
+		query.Descending = true
+	default:
+		return nil, errors.Errorf("invalid sort order %s", c.Query("sortOrder"))
+	}
+
+	if query.AllTags, err = parseIdsList(c, "allTags"); err != nil {
+		return nil, err
+	}
+	if query.AnyTags, err = parseIdsList(c, "anyTags"); err != nil {
+		return nil, err
+	}
+	if query.ExcludedTags, err = parseIdsList(c, "excludedTags"); err != nil {
+		return nil, err
+	}
+	if query.MinDuration, err = parseOptionalFloat(c, "minDuration"); err != nil {
+		return nil, err
+	}
+	if query.MaxDuration, err = parseOptionalFloat(c, "maxDuration"); err != nil {
+		return nil, err
+	}
+	if query.MinResolution, err = parseOptionalIntPtr(c, "minResolution"); err != nil {
+		return nil, err
+	}
+	if query.MaxResolution, err = parseOptionalIntPtr(c, "maxResolution"); err != nil {
+		return nil, err
+	}
+	if query.MinRating, err = parseOptionalIntPtr(c, "minRating"); err != nil {
+		return nil, err
+	}
+	if query.MaxRating, err = parseOptionalIntPtr(c, "maxRating"); err != nil {
+		return nil, err
+	}
+	if query.Favorite, err = parseOptionalBool(c, "favorite"); err != nil {
+		return nil, err
+	}
+	if query.MinYear, err = parseOptionalIntPtr(c, "minYear"); err != nil {
+		return nil, err
+	}
+	if query.MaxYear, err = parseOptionalIntPtr(c, "maxYear"); err != nil {
+		return nil, err
+	}
+	if query.ExternalId, err = parseExternalId(c, "externalId"); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+func parseOptionalInt(c *gin.Context, name string, defaultValue int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func parseOptionalIntPtr(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func parseOptionalFloat(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func parseOptionalBool(c *gin.Context, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Accepts both repeated parameters (?tag=1&tag=2) and comma separated values (?tag=1,2)
+func parseIdsList(c *gin.Context, name string) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	for _, value := range c.QueryArray(name) {
+		for _, part := range strings.Split(value, ",") {
+			if part == "" {
+				continue
+			}
+
+			id, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// Expects provider:id, such as imdb:tt0133093
+func parseExternalId(c *gin.Context, name string) (*model.ExternalId, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	provider, id, ok := strings.Cut(value, ":")
+	if !ok || id == "" {
+		return nil, errors.Errorf("invalid %s %s, expected provider:id", name, value)
+	}
+
+	if err := metadata.ValidateExternalId(strings.ToLower(provider), id); err != nil {
+		return nil, err
+	}
+
+	return &model.ExternalId{Provider: strings.ToLower(provider), Id: id}, nil
+}
@@ -26,13 +26,14 @@ type managementDb interface {
 }
 
 type managementProcessor interface {
-	EnqueueAllItemsCovers(ctx context.Context, force bool) error
-	EnqueueAllItemsFileMetadata(ctx context.Context) error
-	EnqueueAllItemsPreview(ctx context.Context, force bool) error
-	EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) error
+	EnqueueAllItemsCovers(ctx context.Context, force bool) (*model.EnqueueSummary, error)
+	EnqueueAllItemsFileMetadata(ctx context.Context) (*model.EnqueueSummary, error)
+	EnqueueAllItemsPreview(ctx context.Context, force bool) (*model.EnqueueSummary, error)
+	EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) (*model.EnqueueSummary, error)
 	GenerateMixOnDemand(ctx context.Context, ctg model.CurrentTimeGetter, desc string, tags []model.Tag) (*model.Tag, error)
 	EnqueueItemOptimizer()
 	EnqueueSpecTagger()
+	CollectStorageGarbage(ctx context.Context, dryRun bool) (*model.StorageGcReport, error)
 }
 
 func NewHandler(db managementDb, processor managementProcessor) *managementHandler {
@@ -57,6 +58,7 @@ func (s *managementHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("/mix-on-demand", s.generateMixOnDemand)
 	rg.GET("/export-metadata.json", s.exportMetadata)
 	rg.GET("/stats", s.getStats)
+	rg.POST("/storage/gc", s.collectStorageGarbage)
 }
 
 func (s *managementHandler) runSpecTagger(c *gin.Context) {
@@ -97,11 +99,12 @@ func (s *managementHandler) refreshItemsCovers(c *gin.Context) {
 		force = false
 	}
 
-	if server.HandleError(c, s.processor.EnqueueAllItemsCovers(ctx, force)) {
+	summary, err := s.processor.EnqueueAllItemsCovers(ctx, force)
+	if server.HandleError(c, err) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, summary)
 }
 
 func (s *managementHandler) refreshItemsPreview(c *gin.Context) {
@@ -111,11 +114,12 @@ func (s *managementHandler) refreshItemsPreview(c *gin.Context) {
 		force = false
 	}
 
-	if server.HandleError(c, s.processor.EnqueueAllItemsPreview(ctx, force)) {
+	summary, err := s.processor.EnqueueAllItemsPreview(ctx, force)
+	if server.HandleError(c, err) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, summary)
 }
 
 func (s *managementHandler) refreshItemsVideoMetadata(c *gin.Context) {
@@ -126,20 +130,22 @@ func (s *managementHandler) refreshItemsVideoMetadata(c *gin.Context) {
 		force = false
 	}
 
-	if server.HandleError(c, s.processor.EnqueueAllItemsVideoMetadata(ctx, force)) {
+	summary, err := s.processor.EnqueueAllItemsVideoMetadata(ctx, force)
+	if server.HandleError(c, err) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, summary)
 }
 
 func (s *managementHandler) refreshItemsFileMetadata(c *gin.Context) {
 	ctx := server.ContextWithSubject(c)
-	if server.HandleError(c, s.processor.EnqueueAllItemsFileMetadata(ctx)) {
+	summary, err := s.processor.EnqueueAllItemsFileMetadata(ctx)
+	if server.HandleError(c, err) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, summary)
 }
 
 func (s *managementHandler) runItemsOptimizer(c *gin.Context) {
@@ -147,6 +153,23 @@ func (s *managementHandler) runItemsOptimizer(c *gin.Context) {
 	s.processor.EnqueueItemOptimizer()
 }
 
+func (s *managementHandler) collectStorageGarbage(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	// deleting is explicit, a request without dryRun only reports
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "true"))
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	logger.Infof("Triggering storage garbage collection (dry run: %v)", dryRun)
+	report, err := s.processor.CollectStorageGarbage(ctx, dryRun)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 func (s *managementHandler) exportMetadata(c *gin.Context) {
 	ctx := server.ContextWithSubject(c)
 	jsonBytes := bytes.Buffer{}
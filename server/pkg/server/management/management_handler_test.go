@@ -97,24 +97,36 @@ type MockManagementProcessor struct {
 	mock.Mock
 }
 
-func (m *MockManagementProcessor) EnqueueAllItemsCovers(ctx context.Context, force bool) error {
+func (m *MockManagementProcessor) EnqueueAllItemsCovers(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
 	args := m.Called(ctx, force)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EnqueueSummary), args.Error(1)
 }
 
-func (m *MockManagementProcessor) EnqueueAllItemsFileMetadata(ctx context.Context) error {
+func (m *MockManagementProcessor) EnqueueAllItemsFileMetadata(ctx context.Context) (*model.EnqueueSummary, error) {
 	args := m.Called(ctx)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EnqueueSummary), args.Error(1)
 }
 
-func (m *MockManagementProcessor) EnqueueAllItemsPreview(ctx context.Context, force bool) error {
+func (m *MockManagementProcessor) EnqueueAllItemsPreview(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
 	args := m.Called(ctx, force)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EnqueueSummary), args.Error(1)
 }
 
-func (m *MockManagementProcessor) EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) error {
+func (m *MockManagementProcessor) EnqueueAllItemsVideoMetadata(ctx context.Context, force bool) (*model.EnqueueSummary, error) {
 	args := m.Called(ctx, force)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EnqueueSummary), args.Error(1)
 }
 
 func (m *MockManagementProcessor) GenerateMixOnDemand(ctx context.Context, ctg model.CurrentTimeGetter, desc string, tags []model.Tag) (*model.Tag, error) {
@@ -133,6 +145,14 @@ func (m *MockManagementProcessor) EnqueueSpecTagger() {
 	m.Called()
 }
 
+func (m *MockManagementProcessor) CollectStorageGarbage(ctx context.Context, dryRun bool) (*model.StorageGcReport, error) {
+	args := m.Called(ctx, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.StorageGcReport), args.Error(1)
+}
+
 // Test setup functions
 func setupManagementTestHandler() (*managementHandler, *MockManagementDb, *MockManagementProcessor) {
 	mockDb := &MockManagementDb{}
@@ -199,7 +219,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 		router := setupManagementTestRouter(handler)
 
 		t.Run("With Force=true", func(t *testing.T) {
-			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, true).Return(nil)
+			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, true).Return(&model.EnqueueSummary{}, nil)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("POST", "/api/items/refresh-covers?force=true", nil)
@@ -213,7 +233,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 			handler, _, mockProcessor := setupManagementTestHandler()
 			router := setupManagementTestRouter(handler)
 
-			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(nil)
+			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(&model.EnqueueSummary{}, nil)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("POST", "/api/items/refresh-covers?force=false", nil)
@@ -227,7 +247,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 			handler, _, mockProcessor := setupManagementTestHandler()
 			router := setupManagementTestRouter(handler)
 
-			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(nil)
+			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(&model.EnqueueSummary{}, nil)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("POST", "/api/items/refresh-covers", nil)
@@ -241,7 +261,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 			handler, _, mockProcessor := setupManagementTestHandler()
 			router := setupManagementTestRouter(handler)
 
-			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(assert.AnError)
+			mockProcessor.On("EnqueueAllItemsCovers", mock.Anything, false).Return(nil, assert.AnError)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("POST", "/api/items/refresh-covers", nil)
@@ -256,7 +276,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 		handler, _, mockProcessor := setupManagementTestHandler()
 		router := setupManagementTestRouter(handler)
 
-		mockProcessor.On("EnqueueAllItemsPreview", mock.Anything, true).Return(nil)
+		mockProcessor.On("EnqueueAllItemsPreview", mock.Anything, true).Return(&model.EnqueueSummary{}, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/api/items/refresh-preview?force=true", nil)
@@ -270,7 +290,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 		handler, _, mockProcessor := setupManagementTestHandler()
 		router := setupManagementTestRouter(handler)
 
-		mockProcessor.On("EnqueueAllItemsVideoMetadata", mock.Anything, false).Return(nil)
+		mockProcessor.On("EnqueueAllItemsVideoMetadata", mock.Anything, false).Return(&model.EnqueueSummary{}, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/api/items/refresh-video-metadata", nil)
@@ -284,7 +304,7 @@ func TestManagementRefreshOperations(t *testing.T) {
 		handler, _, mockProcessor := setupManagementTestHandler()
 		router := setupManagementTestRouter(handler)
 
-		mockProcessor.On("EnqueueAllItemsFileMetadata", mock.Anything).Return(nil)
+		mockProcessor.On("EnqueueAllItemsFileMetadata", mock.Anything).Return(&model.EnqueueSummary{}, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/api/items/refresh-file-metadata", nil)
@@ -392,6 +412,68 @@ func TestManagementGenerateMixOnDemand(t *testing.T) {
 	})
 }
 
+// Tests for storage garbage collection
+func TestManagementCollectStorageGarbage(t *testing.T) {
+	t.Run("Dry Run", func(t *testing.T) {
+		handler, _, mockProcessor := setupManagementTestHandler()
+		router := setupManagementTestRouter(handler)
+
+		report := &model.StorageGcReport{DryRun: true, ScannedFiles: 10, OrphanFiles: 1, ReclaimableBytes: 1024, Orphans: []string{"covers/3/1.png"}}
+		mockProcessor.On("CollectStorageGarbage", mock.Anything, true).Return(report, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/storage/gc?dryRun=true", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result model.StorageGcReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, *report, result)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		handler, _, mockProcessor := setupManagementTestHandler()
+		router := setupManagementTestRouter(handler)
+
+		mockProcessor.On("CollectStorageGarbage", mock.Anything, false).Return(nil, assert.AnError)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/storage/gc?dryRun=false", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Dry Run By Default", func(t *testing.T) {
+		handler, _, mockProcessor := setupManagementTestHandler()
+		router := setupManagementTestRouter(handler)
+
+		mockProcessor.On("CollectStorageGarbage", mock.Anything, true).Return(&model.StorageGcReport{DryRun: true}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/storage/gc", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Dry Run", func(t *testing.T) {
+		handler, _, mockProcessor := setupManagementTestHandler()
+		router := setupManagementTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/storage/gc?dryRun=maybe", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockProcessor.AssertNotCalled(t, "CollectStorageGarbage", mock.Anything, mock.Anything)
+	})
+}
+
 // Tests for export metadata
 func TestManagementExportMetadata(t *testing.T) {
 	handler, mockDb, _ := setupManagementTestHandler()
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io"
+	"my-collection/server/pkg/bl/metadata"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+)
+
+func NewHandler(db model.ItemMetadataWriter) *metadataHandler {
+	return &metadataHandler{
+		db: db,
+	}
+}
+
+type metadataHandler struct {
+	db model.ItemMetadataWriter
+}
+
+func (s *metadataHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/items/:item/metadata", s.setMetadata)
+}
+
+// setMetadata replaces all the metadata fields of the item, the current values are part of the item
+func (s *metadataHandler) setMetadata(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var request model.ItemMetadata
+	if server.HandleBadRequest(c, json.Unmarshal(body, &request), nil) {
+		return
+	}
+
+	err = metadata.SetMetadata(ctx, s.db, itemId, &request)
+	var validationError *metadata.ValidationError
+	if errors.As(err, &validationError) {
+		server.HandleBadRequest(c, err, nil)
+		return
+	}
+
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
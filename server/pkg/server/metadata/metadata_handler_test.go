@@ -0,0 +1,95 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockMetadataWriter is a mock implementation of model.ItemMetadataWriter interface
+type MockMetadataWriter struct {
+	mock.Mock
+}
+
+func (m *MockMetadataWriter) SetItemMetadata(ctx context.Context, itemId uint64, metadata *model.ItemMetadata) error {
+	args := m.Called(ctx, itemId, metadata)
+	return args.Error(0)
+}
+
+func setupTestRouter(mockDb *MockMetadataWriter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestSetMetadata(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockMetadataWriter)
+		router := setupTestRouter(mockDb)
+
+		expected := &model.ItemMetadata{ReleaseYear: 1999, Language: "en", Description: "plot",
+			ExternalIds: model.ExternalIds{model.EXTERNAL_ID_IMDB: "tt0133093"}}
+		mockDb.On("SetItemMetadata", mock.Anything, uint64(1), expected).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/metadata", bytes.NewBufferString(
+			`{"release_year":1999,"language":"EN","description":" plot ","external_ids":{"IMDB":"tt0133093","tmdb":""}}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Metadata", func(t *testing.T) {
+		mockDb := new(MockMetadataWriter)
+		router := setupTestRouter(mockDb)
+
+		for _, body := range []string{`{"release_year":20000}`, `{"language":"english"}`, `{"external_ids":{"imdb":"123"}}`} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/items/1/metadata", bytes.NewBufferString(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+
+		mockDb.AssertNotCalled(t, "SetItemMetadata", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		mockDb := new(MockMetadataWriter)
+		router := setupTestRouter(mockDb)
+
+		for path, body := range map[string]string{"/api/items/abc/metadata": `{}`, "/api/items/1/metadata": `{"release_year":`} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+
+		mockDb.AssertNotCalled(t, "SetItemMetadata", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Item Not Found", func(t *testing.T) {
+		mockDb := new(MockMetadataWriter)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("SetItemMetadata", mock.Anything, uint64(2), mock.Anything).Return(gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/2/metadata", bytes.NewBufferString(`{}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
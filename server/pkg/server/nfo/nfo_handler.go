@@ -0,0 +1,82 @@
+package nfo
+
+import (
+	"bytes"
+	"context"
+	"my-collection/server/pkg/nfo"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type nfoExporter interface {
+	Build(ctx context.Context, itemId uint64) (*nfo.Nfo, error)
+	ExportItem(ctx context.Context, itemId uint64) error
+	ExportAll(ctx context.Context) (int, error)
+}
+
+type exportResult struct {
+	Exported int `json:"exported"`
+}
+
+func NewHandler(exporter nfoExporter) *nfoHandler {
+	return &nfoHandler{
+		exporter: exporter,
+	}
+}
+
+type nfoHandler struct {
+	exporter nfoExporter
+}
+
+func (s *nfoHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/items/:item/nfo", s.getNfo)
+	rg.POST("/items/:item/nfo", s.exportItem)
+	rg.POST("/nfo/export", s.exportAll)
+}
+
+func (s *nfoHandler) getNfo(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	result, err := s.exporter.Build(ctx, itemId)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	data := bytes.Buffer{}
+	if server.HandleError(c, nfo.Write(&data, result)) {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml", data.Bytes())
+}
+
+func (s *nfoHandler) exportItem(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	if server.HandleError(c, s.exporter.ExportItem(ctx, itemId)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *nfoHandler) exportAll(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	exported, err := s.exporter.ExportAll(ctx)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, exportResult{Exported: exported})
+}
@@ -0,0 +1,123 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my-collection/server/pkg/nfo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockNfoExporter is a mock implementation of nfoExporter interface
+type MockNfoExporter struct {
+	mock.Mock
+}
+
+func (m *MockNfoExporter) Build(ctx context.Context, itemId uint64) (*nfo.Nfo, error) {
+	args := m.Called(ctx, itemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*nfo.Nfo), args.Error(1)
+}
+
+func (m *MockNfoExporter) ExportItem(ctx context.Context, itemId uint64) error {
+	args := m.Called(ctx, itemId)
+	return args.Error(0)
+}
+
+func (m *MockNfoExporter) ExportAll(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func setupTestRouter(mockExporter *MockNfoExporter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockExporter).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestGetNfo(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockExporter := new(MockNfoExporter)
+		router := setupTestRouter(mockExporter)
+
+		mockExporter.On("Build", mock.Anything, uint64(1)).Return(&nfo.Nfo{Title: "Movie", Genres: []string{"Drama"}}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/1/nfo", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+		parsed, err := nfo.Parse(strings.NewReader(w.Body.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, "movie", parsed.XMLName.Local)
+		assert.Equal(t, []string{"Drama"}, parsed.Genres)
+	})
+
+	t.Run("Item Not Found", func(t *testing.T) {
+		mockExporter := new(MockNfoExporter)
+		router := setupTestRouter(mockExporter)
+
+		mockExporter.On("Build", mock.Anything, uint64(2)).Return(nil, gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/2/nfo", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Id", func(t *testing.T) {
+		mockExporter := new(MockNfoExporter)
+		router := setupTestRouter(mockExporter)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/abc/nfo", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestExportNfo(t *testing.T) {
+	t.Run("Item", func(t *testing.T) {
+		mockExporter := new(MockNfoExporter)
+		router := setupTestRouter(mockExporter)
+
+		mockExporter.On("ExportItem", mock.Anything, uint64(3)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/3/nfo", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockExporter.AssertExpectations(t)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		mockExporter := new(MockNfoExporter)
+		router := setupTestRouter(mockExporter)
+
+		mockExporter.On("ExportAll", mock.Anything).Return(7, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/nfo/export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response exportResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 7, response.Exported)
+	})
+}
@@ -0,0 +1,159 @@
+package playback
+
+import (
+	"encoding/json"
+	"io"
+	"my-collection/server/pkg/bl/playback"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("playback-handler")
+
+const defaultContinueWatchingLimit = 20
+
+type playbackHandlerDb interface {
+	model.ItemReader
+	model.WatchProgressReaderWriter
+}
+
+type progressReport struct {
+	PositionSeconds float64 `json:"position_seconds"`
+	Completed       bool    `json:"completed,omitempty"`
+}
+
+func NewHandler(db playbackHandlerDb, watchedThresholdPercent float64) *playbackHandler {
+	return &playbackHandler{
+		db:                      db,
+		watchedThresholdPercent: watchedThresholdPercent,
+	}
+}
+
+type playbackHandler struct {
+	db                      playbackHandlerDb
+	watchedThresholdPercent float64
+}
+
+func (s *playbackHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/continue-watching", s.getContinueWatching)
+	rg.GET("/watch-history", s.getWatchHistory)
+	rg.POST("/items/:item/progress", s.reportProgress)
+	rg.DELETE("/items/:item/progress", s.resetProgress)
+	rg.GET("/items/:item/resume", s.getResumePosition)
+}
+
+func (s *playbackHandler) GetCurrentTime() time.Time {
+	return time.Now()
+}
+
+func (s *playbackHandler) reportProgress(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var report progressReport
+	if server.HandleError(c, json.Unmarshal(body, &report)) {
+		return
+	}
+
+	if report.PositionSeconds < 0 {
+		server.HandleBadRequest(c, errors.Errorf("invalid position %f", report.PositionSeconds), nil)
+		return
+	}
+
+	if server.HandleError(c, playback.ReportProgress(ctx, s.db, s.db, s, itemId,
+		report.PositionSeconds, report.Completed, s.watchedThresholdPercent)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *playbackHandler) resetProgress(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	if server.HandleError(c, s.db.RemoveWatchProgress(ctx, itemId)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *playbackHandler) getResumePosition(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	progress, err := playback.GetResumePosition(ctx, s.db, s.db, itemId)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+func (s *playbackHandler) getContinueWatching(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultContinueWatchingLimit)))
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	if limit < 0 {
+		server.HandleBadRequest(c, errors.Errorf("invalid limit %d", limit), nil)
+		return
+	}
+
+	continueWatching, err := playback.GetContinueWatching(ctx, s.db, s.db, limit)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Continue watching return %d items", len(continueWatching))
+	c.JSON(http.StatusOK, continueWatching)
+}
+
+func (s *playbackHandler) getWatchHistory(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	if offset < 0 || limit < 0 {
+		server.HandleBadRequest(c, errors.Errorf("invalid offset %d or limit %d", offset, limit), nil)
+		return
+	}
+
+	history, err := s.db.GetWatchHistory(ctx, false, offset, limit)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
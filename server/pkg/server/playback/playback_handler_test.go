@@ -0,0 +1,191 @@
+package playback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPlaybackHandlerDb is a mock implementation of playbackHandlerDb interface
+type MockPlaybackHandlerDb struct {
+	mock.Mock
+}
+
+func (m *MockPlaybackHandlerDb) GetItem(ctx context.Context, conds ...interface{}) (*model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Item), args.Error(1)
+}
+
+func (m *MockPlaybackHandlerDb) GetItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockPlaybackHandlerDb) GetAllItems(ctx context.Context) (*[]model.Item, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockPlaybackHandlerDb) GetWatchProgress(ctx context.Context, itemId uint64) (*model.WatchProgress, error) {
+	args := m.Called(ctx, itemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WatchProgress), args.Error(1)
+}
+
+func (m *MockPlaybackHandlerDb) GetWatchHistory(ctx context.Context, onlyInProgress bool, offset int, limit int) (*[]model.WatchProgress, error) {
+	args := m.Called(ctx, onlyInProgress, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.WatchProgress), args.Error(1)
+}
+
+func (m *MockPlaybackHandlerDb) SaveWatchProgress(ctx context.Context, progress *model.WatchProgress) error {
+	args := m.Called(ctx, progress)
+	return args.Error(0)
+}
+
+func (m *MockPlaybackHandlerDb) RemoveWatchProgress(ctx context.Context, itemId uint64) error {
+	args := m.Called(ctx, itemId)
+	return args.Error(0)
+}
+
+func setupTestRouter(mockDb *MockPlaybackHandlerDb) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb, 90).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestReportProgress(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockPlaybackHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("GetItem", mock.Anything, uint64(1)).Return(&model.Item{Id: 1, DurationSeconds: 100}, nil)
+		mockDb.On("GetWatchProgress", mock.Anything, uint64(1)).Return(nil, gorm.ErrRecordNotFound)
+		mockDb.On("SaveWatchProgress", mock.Anything, mock.MatchedBy(func(progress *model.WatchProgress) bool {
+			return progress.ItemId == 1 && progress.PositionSeconds == 95 && progress.Completed
+		})).Return(nil)
+
+		body, _ := json.Marshal(progressReport{PositionSeconds: 95})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/progress", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Position", func(t *testing.T) {
+		mockDb := new(MockPlaybackHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		body, _ := json.Marshal(progressReport{PositionSeconds: -1})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/progress", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDb.AssertNotCalled(t, "SaveWatchProgress", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Item Not Found", func(t *testing.T) {
+		mockDb := new(MockPlaybackHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("GetItem", mock.Anything, uint64(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		body, _ := json.Marshal(progressReport{PositionSeconds: 10})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/progress", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetResumePosition(t *testing.T) {
+	mockDb := new(MockPlaybackHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	mockDb.On("GetItem", mock.Anything, uint64(1)).Return(&model.Item{Id: 1, DurationSeconds: 100}, nil)
+	mockDb.On("GetWatchProgress", mock.Anything, uint64(1)).
+		Return(&model.WatchProgress{ItemId: 1, PositionSeconds: 42, Timestamp: 1000}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/items/1/resume", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"item_id":1`)
+
+	var progress model.WatchProgress
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+	assert.Equal(t, 42.0, progress.PositionSeconds)
+	mockDb.AssertExpectations(t)
+}
+
+func TestGetContinueWatching(t *testing.T) {
+	mockDb := new(MockPlaybackHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	mockDb.On("GetWatchHistory", mock.Anything, true, 0, 5).
+		Return(&[]model.WatchProgress{{ItemId: 1, PositionSeconds: 42}}, nil)
+	mockDb.On("GetItem", mock.Anything, uint64(1)).Return(&model.Item{Id: 1, Title: "item"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/continue-watching?limit=5", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []model.ContinueWatching
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+	assert.Equal(t, "item", response[0].Item.Title)
+	mockDb.AssertExpectations(t)
+}
+
+func TestInvalidPagingParameters(t *testing.T) {
+	mockDb := new(MockPlaybackHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	for _, url := range []string{
+		"/api/continue-watching?limit=-1",
+		"/api/continue-watching?limit=five",
+		"/api/watch-history?offset=-1",
+		"/api/watch-history?offset=first",
+		"/api/watch-history?limit=-1",
+		"/api/watch-history?limit=ten",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+
+	mockDb.AssertNotCalled(t, "GetWatchHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
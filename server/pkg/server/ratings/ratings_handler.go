@@ -0,0 +1,108 @@
+package ratings
+
+import (
+	"encoding/json"
+	"io"
+	"my-collection/server/pkg/bl/ratings"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("ratings-handler")
+
+type ratingsHandlerDb interface {
+	model.ItemReader
+	model.ItemPreferencesWriter
+}
+
+type ratingRequest struct {
+	Rating int `json:"rating"`
+}
+
+type favoriteRequest struct {
+	Favorite bool `json:"favorite"`
+}
+
+func NewHandler(db ratingsHandlerDb) *ratingsHandler {
+	return &ratingsHandler{
+		db: db,
+	}
+}
+
+type ratingsHandler struct {
+	db ratingsHandlerDb
+}
+
+func (s *ratingsHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/items/:item/rating", s.setRating)
+	rg.POST("/items/:item/favorite", s.setFavorite)
+	rg.POST("/ratings/import", s.importRatings)
+}
+
+func (s *ratingsHandler) setRating(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var request ratingRequest
+	if server.HandleError(c, readJson(c, &request)) {
+		return
+	}
+
+	if server.HandleBadRequest(c, ratings.ValidateRating(request.Rating), nil) {
+		return
+	}
+
+	if server.HandleError(c, ratings.SetRating(ctx, s.db, itemId, request.Rating)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *ratingsHandler) setFavorite(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var request favoriteRequest
+	if server.HandleError(c, readJson(c, &request)) {
+		return
+	}
+
+	if server.HandleError(c, s.db.SetItemFavorite(ctx, itemId, request.Favorite)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *ratingsHandler) importRatings(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	var entries []model.RatingImport
+	if server.HandleError(c, readJson(c, &entries)) {
+		return
+	}
+
+	result := ratings.ImportRatings(ctx, s.db, s.db, entries)
+	logger.Infof("Ratings import finished, %d imported, %d failed", result.Imported, len(result.Failed))
+	c.JSON(http.StatusOK, result)
+}
+
+func readJson(c *gin.Context, v any) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,146 @@
+package ratings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockRatingsHandlerDb is a mock implementation of ratingsHandlerDb interface
+type MockRatingsHandlerDb struct {
+	mock.Mock
+}
+
+func (m *MockRatingsHandlerDb) GetItem(ctx context.Context, conds ...interface{}) (*model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Item), args.Error(1)
+}
+
+func (m *MockRatingsHandlerDb) GetItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockRatingsHandlerDb) GetAllItems(ctx context.Context) (*[]model.Item, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockRatingsHandlerDb) SetItemRating(ctx context.Context, itemId uint64, rating int) error {
+	args := m.Called(ctx, itemId, rating)
+	return args.Error(0)
+}
+
+func (m *MockRatingsHandlerDb) SetItemFavorite(ctx context.Context, itemId uint64, favorite bool) error {
+	args := m.Called(ctx, itemId, favorite)
+	return args.Error(0)
+}
+
+func setupTestRouter(mockDb *MockRatingsHandlerDb) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestSetRating(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockRatingsHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("SetItemRating", mock.Anything, uint64(1), 4).Return(nil)
+
+		body, _ := json.Marshal(ratingRequest{Rating: 4})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/rating", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Rating", func(t *testing.T) {
+		mockDb := new(MockRatingsHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		body, _ := json.Marshal(ratingRequest{Rating: 6})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/rating", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDb.AssertNotCalled(t, "SetItemRating", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Item Not Found", func(t *testing.T) {
+		mockDb := new(MockRatingsHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("SetItemRating", mock.Anything, uint64(1), 3).Return(gorm.ErrRecordNotFound)
+
+		body, _ := json.Marshal(ratingRequest{Rating: 3})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/rating", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSetFavorite(t *testing.T) {
+	mockDb := new(MockRatingsHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	mockDb.On("SetItemFavorite", mock.Anything, uint64(1), true).Return(nil)
+
+	body, _ := json.Marshal(favoriteRequest{Favorite: true})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/items/1/favorite", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDb.AssertExpectations(t)
+}
+
+func TestImportRatings(t *testing.T) {
+	mockDb := new(MockRatingsHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	mockDb.On("SetItemRating", mock.Anything, uint64(1), 5).Return(nil)
+	mockDb.On("GetItem", mock.Anything, "title = ? and origin = ?", "missing", "movies").Return(nil, gorm.ErrRecordNotFound)
+
+	body, _ := json.Marshal([]model.RatingImport{
+		{ItemId: 1, Rating: 5},
+		{Title: "missing", Origin: "movies", Rating: 2},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/ratings/import", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result model.RatingImportResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Imported)
+	assert.Len(t, result.Failed, 1)
+	mockDb.AssertExpectations(t)
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+)
+
+type metadataScraper interface {
+	Candidates(ctx context.Context, itemId uint64, title string, year int) ([]model.MetadataCandidate, error)
+	ScrapeItem(ctx context.Context, itemId uint64, id *model.ExternalId) (*model.ScrapeResult, error)
+	EnqueueScrapeMissing()
+}
+
+func NewHandler(scraper metadataScraper) *scraperHandler {
+	return &scraperHandler{
+		scraper: scraper,
+	}
+}
+
+type scraperHandler struct {
+	scraper metadataScraper
+}
+
+func (s *scraperHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/items/:item/scrape/candidates", s.getCandidates)
+	rg.POST("/items/:item/scrape", s.scrapeItem)
+	rg.POST("/scraper/scrape-missing", s.scrapeMissing)
+}
+
+// getCandidates searches by the file name of the item, or by the title and year parameters
+func (s *scraperHandler) getCandidates(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	year := 0
+	if c.Query("year") != "" {
+		year, err = strconv.Atoi(c.Query("year"))
+		if server.HandleBadRequest(c, err, nil) {
+			return
+		}
+	}
+
+	candidates, err := s.scraper.Candidates(ctx, itemId, c.Query("title"), year)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// scrapeItem matches the item automatically, or with the candidate chosen by the provider and id parameters
+func (s *scraperHandler) scrapeItem(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	itemId, err := strconv.ParseUint(c.Param("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var id *model.ExternalId
+	if c.Query("id") != "" {
+		if c.Query("provider") == "" {
+			server.HandleBadRequest(c, errors.New("provider is required with id"), nil)
+			return
+		}
+
+		id = &model.ExternalId{Provider: c.Query("provider"), Id: c.Query("id")}
+	}
+
+	result, err := s.scraper.ScrapeItem(ctx, itemId, id)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *scraperHandler) scrapeMissing(c *gin.Context) {
+	s.scraper.EnqueueScrapeMissing()
+	c.Status(http.StatusAccepted)
+}
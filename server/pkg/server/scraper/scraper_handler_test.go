@@ -0,0 +1,152 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockScraper is a mock implementation of the metadataScraper interface
+type MockScraper struct {
+	mock.Mock
+}
+
+func (m *MockScraper) Candidates(ctx context.Context, itemId uint64, title string, year int) ([]model.MetadataCandidate, error) {
+	args := m.Called(ctx, itemId, title, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.MetadataCandidate), args.Error(1)
+}
+
+func (m *MockScraper) ScrapeItem(ctx context.Context, itemId uint64, id *model.ExternalId) (*model.ScrapeResult, error) {
+	args := m.Called(ctx, itemId, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ScrapeResult), args.Error(1)
+}
+
+func (m *MockScraper) EnqueueScrapeMissing() {
+	m.Called()
+}
+
+func setupTestRouter(mockScraper *MockScraper) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockScraper).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestGetCandidates(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		candidates := []model.MetadataCandidate{{Provider: "tmdb", Id: "603", Title: "The Matrix", ReleaseYear: 1999, Score: 5}}
+		mockScraper.On("Candidates", mock.Anything, uint64(1), "matrix", 1999).Return(candidates, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/1/scrape/candidates?title=matrix&year=1999", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response []model.MetadataCandidate
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, candidates, response)
+	})
+
+	t.Run("Invalid Year", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/items/1/scrape/candidates?year=nineties", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockScraper.AssertNotCalled(t, "Candidates", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestScrapeItem(t *testing.T) {
+	t.Run("Automatic Match", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		result := &model.ScrapeResult{ItemId: 1, Matched: true, Provider: "tmdb", Id: "603", Title: "The Matrix", Tags: 5, Poster: true}
+		mockScraper.On("ScrapeItem", mock.Anything, uint64(1), (*model.ExternalId)(nil)).Return(result, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/scrape", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response model.ScrapeResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, *result, response)
+	})
+
+	t.Run("Chosen Match", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		mockScraper.On("ScrapeItem", mock.Anything, uint64(1), &model.ExternalId{Provider: "tmdb", Id: "604"}).
+			Return(&model.ScrapeResult{ItemId: 1, Matched: true}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/scrape?provider=tmdb&id=604", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockScraper.AssertExpectations(t)
+	})
+
+	t.Run("Missing Provider", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/1/scrape?id=604", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockScraper.AssertNotCalled(t, "ScrapeItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Item Not Found", func(t *testing.T) {
+		mockScraper := new(MockScraper)
+		router := setupTestRouter(mockScraper)
+
+		mockScraper.On("ScrapeItem", mock.Anything, uint64(2), mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/items/2/scrape", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestScrapeMissing(t *testing.T) {
+	mockScraper := new(MockScraper)
+	router := setupTestRouter(mockScraper)
+
+	mockScraper.On("EnqueueScrapeMissing").Return()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/scraper/scrape-missing", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockScraper.AssertExpectations(t)
+}
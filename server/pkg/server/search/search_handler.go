@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/subtitles"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("search-handler")
+
+type searchHandlerDb interface {
+	model.ItemReader
+	model.Searcher
+	model.SubtitlesIndexer
+	RebuildSearchIndex(ctx context.Context) error
+}
+
+func NewHandler(db searchHandlerDb) *searchHandler {
+	return &searchHandler{
+		db: db,
+	}
+}
+
+type searchHandler struct {
+	db searchHandlerDb
+}
+
+func (s *searchHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg = rg.Group("search")
+	rg.GET("", s.search)
+	rg.POST("/rebuild", s.rebuildIndex)
+	rg.POST("/index-subtitles", s.indexSubtitles)
+}
+
+func (s *searchHandler) search(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	query := &model.SearchQuery{Text: c.Query("q")}
+	if query.Text == "" {
+		server.HandleBadRequest(c, errors.Errorf("missing search text"), nil)
+		return
+	}
+
+	var err error
+	if query.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0")); server.HandleBadRequest(c, err, nil) {
+		return
+	}
+	if query.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "0")); server.HandleBadRequest(c, err, nil) {
+		return
+	}
+	if query.Offset < 0 || query.Limit < 0 {
+		server.HandleBadRequest(c, errors.Errorf("invalid offset %d or limit %d", query.Offset, query.Limit), nil)
+		return
+	}
+	if query.Subtitles, err = strconv.ParseBool(c.DefaultQuery("subtitles", "false")); server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	results, err := s.db.Search(ctx, query)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Search %s return %d/%d hits", query.Text, len(results.Hits), results.Total)
+	c.JSON(http.StatusOK, results)
+}
+
+func (s *searchHandler) rebuildIndex(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	logger.Infof("Rebuilding search index")
+	if server.HandleError(c, s.db.RebuildSearchIndex(ctx)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *searchHandler) indexSubtitles(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if c.Query("item") == "" {
+		logger.Infof("Indexing subtitles of all items")
+		if server.HandleError(c, subtitles.IndexAllAvailableSubtitles(ctx, s.db, s.db)) {
+			return
+		}
+
+		c.Status(http.StatusOK)
+		return
+	}
+
+	itemId, err := strconv.ParseUint(c.Query("item"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Indexing subtitles of item %d", itemId)
+	if server.HandleError(c, subtitles.IndexAvailableSubtitles(ctx, s.db, s.db, itemId)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/utils/ptr"
+)
+
+// MockSearchHandlerDb is a mock implementation of searchHandlerDb interface
+type MockSearchHandlerDb struct {
+	mock.Mock
+}
+
+func (m *MockSearchHandlerDb) GetItem(ctx context.Context, conds ...interface{}) (*model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Item), args.Error(1)
+}
+
+func (m *MockSearchHandlerDb) GetItems(ctx context.Context, conds ...interface{}) (*[]model.Item, error) {
+	args := m.Called(append([]interface{}{ctx}, conds...)...)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockSearchHandlerDb) GetAllItems(ctx context.Context) (*[]model.Item, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]model.Item), args.Error(1)
+}
+
+func (m *MockSearchHandlerDb) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchResults, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SearchResults), args.Error(1)
+}
+
+func (m *MockSearchHandlerDb) IndexSubtitle(ctx context.Context, itemId uint64, url string, subtitle model.Subtitle) error {
+	args := m.Called(ctx, itemId, url, subtitle)
+	return args.Error(0)
+}
+
+func (m *MockSearchHandlerDb) RemoveSubtitleIndex(ctx context.Context, url string) error {
+	args := m.Called(ctx, url)
+	return args.Error(0)
+}
+
+func (m *MockSearchHandlerDb) RebuildSearchIndex(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func setupTestRouter(mockDb *MockSearchHandlerDb) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockDb := new(MockSearchHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		expectedResults := &model.SearchResults{
+			Hits: []model.SearchHit{
+				{ItemId: 1, Title: "Matrix", Source: model.SEARCH_SOURCE_ITEM, Snippet: "The <b>Matrix</b>", Rank: -2},
+				{ItemId: 1, Title: "Matrix", Source: model.SEARCH_SOURCE_SUBTITLE, Snippet: "Welcome to the <b>Matrix</b>",
+					Rank: -1, SubtitleUrl: "matrix.srt", StartMillis: ptr.To(int64(1000)), EndMillis: ptr.To(int64(2000))},
+			},
+			Total: 2,
+			Limit: 10,
+		}
+
+		mockDb.On("Search", mock.Anything, &model.SearchQuery{Text: "matrix", Subtitles: true, Limit: 10}).
+			Return(expectedResults, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/search?q=matrix&subtitles=true&limit=10", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response model.SearchResults
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, *expectedResults, response)
+
+		mockDb.AssertExpectations(t)
+	})
+
+	t.Run("Missing Text", func(t *testing.T) {
+		mockDb := new(MockSearchHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/search", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockDb.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		mockDb := new(MockSearchHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		for _, query := range []string{"limit=-1", "limit=ten", "offset=-1", "offset=first", "subtitles=maybe"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/search?q=matrix&"+query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+
+		mockDb.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDb := new(MockSearchHandlerDb)
+		router := setupTestRouter(mockDb)
+
+		mockDb.On("Search", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("no such module: fts5"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/search?q=matrix", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockDb.AssertExpectations(t)
+	})
+}
+
+func TestRebuildIndex(t *testing.T) {
+	mockDb := new(MockSearchHandlerDb)
+	router := setupTestRouter(mockDb)
+
+	mockDb.On("RebuildSearchIndex", mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/search/rebuild", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDb.AssertExpectations(t)
+}
@@ -39,6 +39,21 @@ func HandleError(c *gin.Context, err error) bool {
 	return true
 }
 
+type BadRequest struct {
+	Error   string `json:"error"`
+	Details any    `json:"details,omitempty"`
+}
+
+func HandleBadRequest(c *gin.Context, err error, details any) bool {
+	if err == nil {
+		return false
+	}
+
+	utils.LogWarning("Bad request", err)
+	c.AbortWithStatusJSON(http.StatusBadRequest, BadRequest{Error: err.Error(), Details: details})
+	return true
+}
+
 func logError(err error) {
 	if err == nil {
 		return
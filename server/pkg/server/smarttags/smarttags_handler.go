@@ -0,0 +1,116 @@
+package smarttags
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("smarttags-handler")
+
+type smartTagsManager interface {
+	CreateSmartTag(ctx context.Context, title string, query string) (*model.Tag, error)
+	UpdateSmartTagQuery(ctx context.Context, tagId uint64, query string) (*model.Tag, error)
+	EnqueueSmartTagsRefresh()
+}
+
+type smartTagRequest struct {
+	Title string `json:"title,omitempty"`
+	Query string `json:"query"`
+}
+
+func NewHandler(manager smartTagsManager) *smartTagsHandler {
+	return &smartTagsHandler{
+		manager: manager,
+	}
+}
+
+type smartTagsHandler struct {
+	manager smartTagsManager
+}
+
+func (s *smartTagsHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg = rg.Group("smart-tags")
+	rg.POST("", s.createSmartTag)
+	rg.POST("/refresh", s.refreshSmartTags)
+	rg.POST("/:tag", s.updateSmartTag)
+}
+
+func (s *smartTagsHandler) createSmartTag(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	request, err := readRequest(c)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	if request.Title == "" {
+		server.HandleBadRequest(c, errors.Errorf("missing smart tag title"), nil)
+		return
+	}
+
+	tag, err := s.manager.CreateSmartTag(ctx, request.Title, request.Query)
+	if handleQueryError(c, err) || server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Smart tag %s created with query %s", tag.Title, tag.SmartQuery)
+	c.JSON(http.StatusOK, model.Tag{Id: tag.Id})
+}
+
+func (s *smartTagsHandler) updateSmartTag(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	tagId, err := strconv.ParseUint(c.Param("tag"), 10, 64)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	request, err := readRequest(c)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	tag, err := s.manager.UpdateSmartTagQuery(ctx, tagId, request.Query)
+	if handleQueryError(c, err) || server.HandleError(c, err) {
+		return
+	}
+
+	logger.Infof("Smart tag %s updated with query %s", tag.Title, tag.SmartQuery)
+	c.Status(http.StatusOK)
+}
+
+func (s *smartTagsHandler) refreshSmartTags(c *gin.Context) {
+	logger.Infof("Triggering smart tags refresh")
+	s.manager.EnqueueSmartTagsRefresh()
+}
+
+func readRequest(c *gin.Context) (*smartTagRequest, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var request smartTagRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func handleQueryError(c *gin.Context, err error) bool {
+	var parseError *querylang.ParseError
+	if !errors.As(err, &parseError) {
+		return false
+	}
+
+	return server.HandleBadRequest(c, err, parseError)
+}
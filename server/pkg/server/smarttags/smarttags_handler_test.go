@@ -0,0 +1,184 @@
+package smarttags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockSmartTagsManager is a mock implementation of smartTagsManager interface
+type MockSmartTagsManager struct {
+	mock.Mock
+}
+
+func (m *MockSmartTagsManager) CreateSmartTag(ctx context.Context, title string, query string) (*model.Tag, error) {
+	args := m.Called(ctx, title, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockSmartTagsManager) UpdateSmartTagQuery(ctx context.Context, tagId uint64, query string) (*model.Tag, error) {
+	args := m.Called(ctx, tagId, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tag), args.Error(1)
+}
+
+func (m *MockSmartTagsManager) EnqueueSmartTagsRefresh() {
+	m.Called()
+}
+
+func setupTestRouter(mockManager *MockSmartTagsManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockManager).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func parseError(t *testing.T, query string) error {
+	_, err := querylang.Parse(query)
+	require.Error(t, err)
+	return err
+}
+
+func TestCreateSmartTag(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("CreateSmartTag", mock.Anything, "Long Movies", "duration>2h").
+			Return(&model.Tag{Id: 5, Title: "Long Movies", SmartQuery: "duration>2h"}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags", bytes.NewBufferString(`{"title":"Long Movies","query":"duration>2h"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response model.Tag
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, uint64(5), response.Id)
+		mockManager.AssertExpectations(t)
+	})
+
+	t.Run("Missing Title", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags", bytes.NewBufferString(`{"query":"duration>2h"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockManager.AssertNotCalled(t, "CreateSmartTag", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Query", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("CreateSmartTag", mock.Anything, "Red", "color:red").Return(nil, parseError(t, "color:red"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags", bytes.NewBufferString(`{"title":"Red","query":"color:red"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response struct {
+			Error   string               `json:"error"`
+			Details querylang.ParseError `json:"details"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Details.Message, "unknown field color")
+		assert.Equal(t, 0, response.Details.Position)
+		mockManager.AssertExpectations(t)
+	})
+
+	t.Run("Manager Error", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("CreateSmartTag", mock.Anything, "Long Movies", "duration>2h").Return(nil, assert.AnError)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags", bytes.NewBufferString(`{"title":"Long Movies","query":"duration>2h"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockManager.AssertExpectations(t)
+	})
+}
+
+func TestUpdateSmartTag(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("UpdateSmartTagQuery", mock.Anything, uint64(5), "duration>3h").
+			Return(&model.Tag{Id: 5, Title: "Long Movies", SmartQuery: "duration>3h"}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags/5", bytes.NewBufferString(`{"query":"duration>3h"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockManager.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Query", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("UpdateSmartTagQuery", mock.Anything, uint64(5), "duration>long").
+			Return(nil, parseError(t, "duration>long"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags/5", bytes.NewBufferString(`{"query":"duration>long"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockManager.AssertExpectations(t)
+	})
+
+	t.Run("Tag Not Found", func(t *testing.T) {
+		mockManager := new(MockSmartTagsManager)
+		router := setupTestRouter(mockManager)
+
+		mockManager.On("UpdateSmartTagQuery", mock.Anything, uint64(6), "duration>3h").Return(nil, gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/smart-tags/6", bytes.NewBufferString(`{"query":"duration>3h"}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockManager.AssertExpectations(t)
+	})
+}
+
+func TestRefreshSmartTags(t *testing.T) {
+	mockManager := new(MockSmartTagsManager)
+	router := setupTestRouter(mockManager)
+
+	mockManager.On("EnqueueSmartTagsRefresh").Return()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/smart-tags/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockManager.AssertExpectations(t)
+}
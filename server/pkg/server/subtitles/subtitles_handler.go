@@ -15,6 +15,7 @@ var logger = logging.MustGetLogger("subtitles-handler")
 
 type subtitleHandlerDb interface {
 	model.ItemReader
+	model.SubtitlesIndexer
 }
 
 type subtitleHandlerOp interface {
@@ -115,6 +116,10 @@ func (s *subtitleHandler) downloadSubtitle(c *gin.Context) {
 		return
 	}
 
+	if err := subtitles.IndexSubtitle(ctx, s.db, itemId, url); err != nil {
+		logger.Warningf("Unable to index downloaded subtitle %s - %s", url, err)
+	}
+
 	c.JSON(http.StatusOK, url)
 }
 
@@ -127,5 +132,9 @@ func (s *subtitleHandler) deleteSubtitle(c *gin.Context) {
 		return
 	}
 
+	if err := s.db.RemoveSubtitleIndex(ctx, url); err != nil {
+		logger.Warningf("Unable to remove subtitle %s from the search index - %s", url, err)
+	}
+
 	c.Status(http.StatusOK)
 }
@@ -12,6 +12,7 @@ import (
 	"my-collection/server/pkg/mixondemand"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/server"
+	"my-collection/server/pkg/smarttags"
 	"my-collection/server/pkg/spectagger"
 	"net/http"
 	"strconv"
@@ -177,6 +178,7 @@ func (s *tagsHandler) getSpecialTags(c *gin.Context) {
 		automix.GetDailymixTagId(),
 		mixondemand.GetMixOnDemandTagId(),
 		spectagger.GetSpecTagId(),
+		smarttags.GetSmartTagId(),
 		items.GetHighlightsTagId())
 
 	if server.HandleError(c, err) {
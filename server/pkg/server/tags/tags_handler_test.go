@@ -429,7 +429,7 @@ func TestTagsSpecialOperations(t *testing.T) {
 			{Id: 1, Title: "special-tag-1"},
 			{Id: 2, Title: "special-tag-2"},
 		}
-		mockDb.On("GetTagsWithoutChildren", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(expectedTags, nil)
+		mockDb.On("GetTagsWithoutChildren", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(expectedTags, nil)
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/api/special-tags", nil)
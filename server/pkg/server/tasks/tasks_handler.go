@@ -2,6 +2,8 @@ package tasks
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"my-collection/server/pkg/bl/tasks"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/server"
@@ -9,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
 	"github.com/op/go-logging"
 )
 
@@ -24,6 +27,16 @@ type queueProcessor interface {
 	Continue()
 	Pause()
 	ClearFinishedTasks(ctx context.Context) error
+	RetryTask(ctx context.Context, taskId string) error
+	DiscardTask(ctx context.Context, taskId string) error
+	ReprioritizeTasks(ctx context.Context, taskIds []string, priority model.TaskPriority, position int) error
+	CancelTask(ctx context.Context, taskId string) error
+}
+
+type reorderRequest struct {
+	TaskIds  []string           `json:"tasks"`
+	Priority model.TaskPriority `json:"priority"`
+	Position *int               `json:"position,omitempty"` // within the priority, its end by default
 }
 
 func NewHandler(db queueDb, processor queueProcessor) *tasksHandler {
@@ -45,6 +58,12 @@ func (s *tasksHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("/queue/continue", s.queueContinue)
 	rg.POST("/queue/pause", s.queuePause)
 	rg.POST("/queue/clear-finished", s.clearFinishedTasks)
+	rg.GET("/queue/failed", s.getFailedTasks)
+	rg.POST("/queue/failed/:task/retry", s.retryTask)
+	rg.DELETE("/queue/failed/:task", s.discardTask)
+	rg.POST("/queue/tasks/:task/bump", s.bumpTask)
+	rg.DELETE("/queue/tasks/:task", s.cancelTask)
+	rg.POST("/queue/reorder", s.reorderTasks)
 
 }
 
@@ -92,3 +111,83 @@ func (s *tasksHandler) queuePause(c *gin.Context) {
 	s.processor.Pause()
 	c.Status(http.StatusOK)
 }
+
+// getFailedTasks returns the dead letter tasks with the error of their last attempt
+func (s *tasksHandler) getFailedTasks(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	t, err := s.db.FindTasks(ctx, "dead_letter = ?", true)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+func (s *tasksHandler) retryTask(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if server.HandleError(c, s.processor.RetryTask(ctx, c.Param("task"))) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *tasksHandler) discardTask(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if server.HandleError(c, s.processor.DiscardTask(ctx, c.Param("task"))) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// bumpTask runs a queued task next, after the other high priority tasks
+func (s *tasksHandler) bumpTask(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if server.HandleError(c, s.processor.ReprioritizeTasks(ctx, []string{c.Param("task")}, model.TASK_PRIORITY_HIGH, -1)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// cancelTask removes a queued task, or kills the processes of a running one
+func (s *tasksHandler) cancelTask(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	if server.HandleError(c, s.processor.CancelTask(ctx, c.Param("task"))) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// reorderTasks moves queued tasks to a priority, in the order of the request and at the position
+// within the tasks of the priority
+func (s *tasksHandler) reorderTasks(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	body, err := io.ReadAll(c.Request.Body)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	var request reorderRequest
+	if server.HandleBadRequest(c, json.Unmarshal(body, &request), nil) {
+		return
+	}
+
+	if len(request.TaskIds) == 0 || !request.Priority.IsValid() || (request.Position != nil && *request.Position < 0) {
+		server.HandleBadRequest(c, errors.Errorf("expected tasks, a valid priority and a non negative position"), nil)
+		return
+	}
+
+	position := -1
+	if request.Position != nil {
+		position = *request.Position
+	}
+
+	if server.HandleError(c, s.processor.ReprioritizeTasks(ctx, request.TaskIds, request.Priority, position)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
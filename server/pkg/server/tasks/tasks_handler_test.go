@@ -0,0 +1,263 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockQueueDb is a mock implementation of the queueDb interface, the item reader isn't used by
+// the tested endpoints
+type MockQueueDb struct {
+	mock.Mock
+	model.ItemReader
+}
+
+func (m *MockQueueDb) GetTasks(ctx context.Context, offset int, limit int) (*[]model.Task, error) {
+	args := m.Called(ctx, offset, limit)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+func (m *MockQueueDb) TasksCount(ctx context.Context, query interface{}, conds ...interface{}) (int64, error) {
+	args := m.Called(ctx, query, conds)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQueueDb) FindTasks(ctx context.Context, conds ...interface{}) (*[]model.Task, error) {
+	args := m.Called(ctx, conds)
+	return args.Get(0).(*[]model.Task), args.Error(1)
+}
+
+// MockQueueProcessor is a mock implementation of the queueProcessor interface
+type MockQueueProcessor struct {
+	mock.Mock
+}
+
+func (m *MockQueueProcessor) IsPaused() bool {
+	return m.Called().Bool(0)
+}
+
+func (m *MockQueueProcessor) PoolsMetadata() []model.PoolMetadata {
+	return m.Called().Get(0).([]model.PoolMetadata)
+}
+
+func (m *MockQueueProcessor) Continue() {
+	m.Called()
+}
+
+func (m *MockQueueProcessor) Pause() {
+	m.Called()
+}
+
+func (m *MockQueueProcessor) ClearFinishedTasks(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockQueueProcessor) RetryTask(ctx context.Context, taskId string) error {
+	return m.Called(ctx, taskId).Error(0)
+}
+
+func (m *MockQueueProcessor) DiscardTask(ctx context.Context, taskId string) error {
+	return m.Called(ctx, taskId).Error(0)
+}
+
+func (m *MockQueueProcessor) ReprioritizeTasks(ctx context.Context, taskIds []string, priority model.TaskPriority, position int) error {
+	return m.Called(ctx, taskIds, priority, position).Error(0)
+}
+
+func (m *MockQueueProcessor) CancelTask(ctx context.Context, taskId string) error {
+	return m.Called(ctx, taskId).Error(0)
+}
+
+func setupTestRouter(mockDb *MockQueueDb, mockProcessor *MockQueueProcessor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockDb, mockProcessor).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestGetQueueMetadata(t *testing.T) {
+	mockDb := new(MockQueueDb)
+	mockProcessor := new(MockQueueProcessor)
+	router := setupTestRouter(mockDb, mockProcessor)
+
+	pools := []model.PoolMetadata{{Name: "metadata", Workers: 4, Busy: 2, Queued: 10, Processed: 3, AverageMillis: 20}}
+	mockDb.On("TasksCount", mock.Anything, "", mock.Anything).Return(int64(20), nil)
+	mockDb.On("TasksCount", mock.Anything, "processing_end is null", mock.Anything).Return(int64(12), nil)
+	mockDb.On("TasksCount", mock.Anything, "dead_letter = ?", []interface{}{true}).Return(int64(1), nil)
+	mockProcessor.On("IsPaused").Return(false)
+	mockProcessor.On("PoolsMetadata").Return(pools)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/queue/metadata", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.QueueMetadata
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(12), *response.UnfinishedTasks)
+	assert.Equal(t, int64(1), *response.FailedTasks)
+	assert.Equal(t, pools, response.Pools)
+}
+
+func TestGetFailedTasks(t *testing.T) {
+	mockDb := new(MockQueueDb)
+	router := setupTestRouter(mockDb, new(MockQueueProcessor))
+
+	failed := []model.Task{{Id: "1", TaskType: model.REFRESH_COVER_TASK, Attempts: 3, Error: "exit code 1", DeadLetter: true}}
+	mockDb.On("FindTasks", mock.Anything, []interface{}{"dead_letter = ?", true}).Return(&failed, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/queue/failed", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []model.Task
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, failed, response)
+}
+
+func TestRetryTask(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("RetryTask", mock.Anything, "abc").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/queue/failed/abc/retry", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Not A Dead Letter", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("RetryTask", mock.Anything, "abc").Return(gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/queue/failed/abc/retry", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDiscardTask(t *testing.T) {
+	mockProcessor := new(MockQueueProcessor)
+	router := setupTestRouter(new(MockQueueDb), mockProcessor)
+	mockProcessor.On("DiscardTask", mock.Anything, "abc").Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/queue/failed/abc", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestBumpTask(t *testing.T) {
+	mockProcessor := new(MockQueueProcessor)
+	router := setupTestRouter(new(MockQueueDb), mockProcessor)
+	mockProcessor.On("ReprioritizeTasks", mock.Anything, []string{"abc"}, model.TASK_PRIORITY_HIGH, -1).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/queue/tasks/abc/bump", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestCancelTask(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("CancelTask", mock.Anything, "abc").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/queue/tasks/abc", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Task Finished", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("CancelTask", mock.Anything, "abc").Return(gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/queue/tasks/abc", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestReorderTasks(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("ReprioritizeTasks", mock.Anything, []string{"b", "a"}, model.TASK_PRIORITY_LOW, -1).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/queue/reorder", bytes.NewBufferString(`{"tasks":["b","a"],"priority":-1}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("With Position", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("ReprioritizeTasks", mock.Anything, []string{"a"}, model.TASK_PRIORITY_NORMAL, 0).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/queue/reorder", bytes.NewBufferString(`{"tasks":["a"],"priority":0,"position":0}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockProcessor.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Request", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+
+		for _, body := range []string{`{"tasks":[],"priority":1}`, `{"tasks":["a"],"priority":5}`, `{"tasks":["a"],"priority":1,"position":-2}`, `not json`} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/queue/reorder", bytes.NewBufferString(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+
+		mockProcessor.AssertNotCalled(t, "ReprioritizeTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Task Not Queued", func(t *testing.T) {
+		mockProcessor := new(MockQueueProcessor)
+		router := setupTestRouter(new(MockQueueDb), mockProcessor)
+		mockProcessor.On("ReprioritizeTasks", mock.Anything, []string{"a"}, mock.Anything, mock.Anything).Return(gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/queue/reorder", bytes.NewBufferString(`{"tasks":["a"],"priority":1}`))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,89 @@
+package trash
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type trashManager interface {
+	List(ctx context.Context) (*model.Trash, error)
+	RestoreItem(ctx context.Context, itemId uint64) error
+	RestoreTag(ctx context.Context, tagId uint64) error
+	PurgeItem(ctx context.Context, itemId uint64) error
+	PurgeTag(ctx context.Context, tagId uint64) error
+	PurgeExpired(ctx context.Context) (*model.PurgeResult, error)
+}
+
+func NewHandler(trash trashManager) *trashHandler {
+	return &trashHandler{
+		trash: trash,
+	}
+}
+
+type trashHandler struct {
+	trash trashManager
+}
+
+func (s *trashHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/trash", s.getTrash)
+	rg.POST("/trash/items/:item/restore", s.restoreItem)
+	rg.POST("/trash/tags/:tag/restore", s.restoreTag)
+	rg.DELETE("/trash/items/:item", s.purgeItem)
+	rg.DELETE("/trash/tags/:tag", s.purgeTag)
+	rg.POST("/trash/purge", s.purgeExpired)
+}
+
+func (s *trashHandler) getTrash(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	trash, err := s.trash.List(ctx)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+func (s *trashHandler) restoreItem(c *gin.Context) {
+	s.withId(c, "item", s.trash.RestoreItem)
+}
+
+func (s *trashHandler) restoreTag(c *gin.Context) {
+	s.withId(c, "tag", s.trash.RestoreTag)
+}
+
+func (s *trashHandler) purgeItem(c *gin.Context) {
+	s.withId(c, "item", s.trash.PurgeItem)
+}
+
+func (s *trashHandler) purgeTag(c *gin.Context) {
+	s.withId(c, "tag", s.trash.PurgeTag)
+}
+
+func (s *trashHandler) purgeExpired(c *gin.Context) {
+	ctx := server.ContextWithSubject(c)
+	result, err := s.trash.PurgeExpired(ctx)
+	if server.HandleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *trashHandler) withId(c *gin.Context, param string, action func(ctx context.Context, id uint64) error) {
+	ctx := server.ContextWithSubject(c)
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if server.HandleBadRequest(c, err, nil) {
+		return
+	}
+
+	if server.HandleError(c, action(ctx, id)) {
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
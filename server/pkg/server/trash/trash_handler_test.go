@@ -0,0 +1,172 @@
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-collection/server/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockTrashManager is a mock implementation of trashManager interface
+type MockTrashManager struct {
+	mock.Mock
+}
+
+func (m *MockTrashManager) List(ctx context.Context) (*model.Trash, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Trash), args.Error(1)
+}
+
+func (m *MockTrashManager) RestoreItem(ctx context.Context, itemId uint64) error {
+	args := m.Called(ctx, itemId)
+	return args.Error(0)
+}
+
+func (m *MockTrashManager) RestoreTag(ctx context.Context, tagId uint64) error {
+	args := m.Called(ctx, tagId)
+	return args.Error(0)
+}
+
+func (m *MockTrashManager) PurgeItem(ctx context.Context, itemId uint64) error {
+	args := m.Called(ctx, itemId)
+	return args.Error(0)
+}
+
+func (m *MockTrashManager) PurgeTag(ctx context.Context, tagId uint64) error {
+	args := m.Called(ctx, tagId)
+	return args.Error(0)
+}
+
+func (m *MockTrashManager) PurgeExpired(ctx context.Context) (*model.PurgeResult, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PurgeResult), args.Error(1)
+}
+
+func setupTestRouter(mockTrash *MockTrashManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(mockTrash).RegisterRoutes(router.Group("/api"))
+	return router
+}
+
+func TestGetTrash(t *testing.T) {
+	mockTrash := new(MockTrashManager)
+	router := setupTestRouter(mockTrash)
+
+	mockTrash.On("List", mock.Anything).Return(&model.Trash{
+		Items: []model.TrashedItem{{Item: model.Item{Id: 1, Title: "item"}, DeletedAt: 1000}},
+		Tags:  []model.TrashedTag{},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/trash", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.Trash
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, int64(1000), response.Items[0].DeletedAt)
+	assert.Equal(t, "item", response.Items[0].Item.Title)
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("Item", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		mockTrash.On("RestoreItem", mock.Anything, uint64(3)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/items/3/restore", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTrash.AssertExpectations(t)
+	})
+
+	t.Run("Tag Not In Trash", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		mockTrash.On("RestoreTag", mock.Anything, uint64(4)).Return(gorm.ErrRecordNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/tags/4/restore", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Id", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/items/abc/restore", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTrash.AssertNotCalled(t, "RestoreItem", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPurge(t *testing.T) {
+	t.Run("Item", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		mockTrash.On("PurgeItem", mock.Anything, uint64(3)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/trash/items/3", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTrash.AssertExpectations(t)
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		mockTrash.On("PurgeTag", mock.Anything, uint64(5)).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/trash/tags/5", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTrash.AssertExpectations(t)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		mockTrash := new(MockTrashManager)
+		router := setupTestRouter(mockTrash)
+
+		mockTrash.On("PurgeExpired", mock.Anything).Return(&model.PurgeResult{Items: 2, Tags: 1}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/trash/purge", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response model.PurgeResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, model.PurgeResult{Items: 2, Tags: 1}, response)
+	})
+}
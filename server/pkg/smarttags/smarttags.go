@@ -0,0 +1,153 @@
+package smarttags
+
+import (
+	"context"
+	"my-collection/server/pkg/bl/special_tags"
+	"my-collection/server/pkg/bl/tags"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+	"my-collection/server/pkg/utils"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("smarttags")
+
+const refreshInterval = 15 * time.Minute
+
+func GetSmartTagId() uint64 {
+	return special_tags.SmartTag.Id
+}
+
+type smartTagsDb interface {
+	model.TagReaderWriter
+	model.TagItemsSetter
+	model.ItemsQuerier
+}
+
+func New(ctx context.Context, db smartTagsDb) (*SmartTags, error) {
+	s, err := db.GetTag(ctx, special_tags.SmartTag)
+	if err != nil {
+		if err := db.CreateOrUpdateTag(ctx, special_tags.SmartTag); err != nil {
+			return nil, err
+		}
+	} else {
+		special_tags.SmartTag = s
+	}
+
+	return &SmartTags{
+		db:             db,
+		triggerChannel: make(chan bool),
+	}, nil
+}
+
+// SmartTags keeps the items of every child of the Smart tag in sync with the
+// query stored on it, e.g. `tag:Unwatched AND height>=2160 AND duration<30m`.
+type SmartTags struct {
+	db             smartTagsDb
+	triggerChannel chan bool
+}
+
+func (s *SmartTags) EnqueueSmartTagsRefresh() {
+	s.triggerChannel <- true
+}
+
+func (s *SmartTags) Run(ctx context.Context) error {
+	ctx = utils.ContextWithSubject(ctx, "smarttags")
+
+	for {
+		select {
+		case <-s.triggerChannel:
+			s.refreshAll(ctx)
+		case <-time.After(refreshInterval):
+			s.refreshAll(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *SmartTags) CreateSmartTag(ctx context.Context, title string, query string) (*model.Tag, error) {
+	if _, err := querylang.Parse(query); err != nil {
+		return nil, err
+	}
+
+	tag, err := tags.GetOrCreateChildTag(ctx, s.db, special_tags.SmartTag.Id, title)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setQuery(ctx, tag, query)
+}
+
+func (s *SmartTags) UpdateSmartTagQuery(ctx context.Context, tagId uint64, query string) (*model.Tag, error) {
+	if _, err := querylang.Parse(query); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.db.GetTag(ctx, tagId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsSmartTag(tag) {
+		return nil, errors.Errorf("tag %d is not a smart tag", tagId)
+	}
+
+	return s.setQuery(ctx, tag, query)
+}
+
+func (s *SmartTags) setQuery(ctx context.Context, tag *model.Tag, query string) (*model.Tag, error) {
+	tag.SmartQuery = query
+	if err := s.db.UpdateTag(ctx, &model.Tag{Id: tag.Id, SmartQuery: query}); err != nil {
+		return nil, err
+	}
+
+	return tag, s.RefreshSmartTag(ctx, tag)
+}
+
+func IsSmartTag(tag *model.Tag) bool {
+	return tags.IsBelongToCategory(tag, special_tags.SmartTag)
+}
+
+func (s *SmartTags) RefreshSmartTag(ctx context.Context, tag *model.Tag) error {
+	expression, err := querylang.Parse(tag.SmartQuery)
+	if err != nil {
+		return err
+	}
+
+	page, err := s.db.QueryItems(ctx, &model.ItemsQuery{Expression: expression})
+	if err != nil {
+		return err
+	}
+
+	itemIds := make([]uint64, len(page.Items))
+	for i, item := range page.Items {
+		itemIds[i] = item.Id
+	}
+
+	logger.Debugf("Smart tag %s (%s) matches %d items", tag.Title, tag.SmartQuery, len(itemIds))
+	return s.db.SetTagItems(ctx, tag.Id, itemIds)
+}
+
+func (s *SmartTags) refreshAll(ctx context.Context) {
+	logger.Debugf("Smart tags refresh started")
+	smartTags, err := s.db.GetTagsWithoutChildren(ctx, "parent_id = ?", special_tags.SmartTag.Id)
+	if err != nil {
+		utils.LogError("Error getting smart tags", err)
+		return
+	}
+
+	for _, tag := range *smartTags {
+		if tag.SmartQuery == "" {
+			continue
+		}
+
+		if err := s.RefreshSmartTag(ctx, &tag); err != nil {
+			utils.LogError("Error refreshing smart tag", err)
+		}
+	}
+	logger.Debugf("Smart tags refresh finished")
+}
@@ -0,0 +1,81 @@
+package smarttags
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/querylang"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func getTagItems(t *testing.T, db db.Database, tagId uint64) []uint64 {
+	tag, err := db.GetTag(context.Background(), tagId)
+	assert.NoError(t, err)
+	result := make([]uint64, 0)
+	for _, item := range tag.Items {
+		result = append(result, item.Id)
+	}
+	return result
+}
+
+func TestSmartTags(t *testing.T) {
+	db := setupNewDb(t, "smart-tags.sqlite")
+	ctx := context.Background()
+
+	s, err := New(ctx, db)
+	assert.NoError(t, err)
+	assert.NotZero(t, GetSmartTagId())
+
+	unwatched := &model.Tag{Title: "Unwatched"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, unwatched))
+
+	short4k := &model.Item{Title: "short-4k", Origin: "movies", Height: 2160, DurationSeconds: 600,
+		Tags: []*model.Tag{unwatched}}
+	long4k := &model.Item{Title: "long-4k", Origin: "movies", Height: 2160, DurationSeconds: 7200,
+		Tags: []*model.Tag{unwatched}}
+	shortHd := &model.Item{Title: "short-hd", Origin: "movies", Height: 1080, DurationSeconds: 600,
+		Tags: []*model.Tag{unwatched}}
+	for _, item := range []*model.Item{short4k, long4k, shortHd} {
+		assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	}
+
+	tag, err := s.CreateSmartTag(ctx, "Unwatched 4K under 30 minutes", `tag:Unwatched AND height>=2160 AND duration<30m`)
+	assert.NoError(t, err)
+	assert.True(t, IsSmartTag(tag))
+	assert.Equal(t, []uint64{short4k.Id}, getTagItems(t, db, tag.Id))
+
+	saved, err := db.GetTag(ctx, tag.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, `tag:Unwatched AND height>=2160 AND duration<30m`, saved.SmartQuery)
+
+	assert.NoError(t, db.RemoveTagFromItem(ctx, short4k.Id, unwatched.Id))
+	s.refreshAll(ctx)
+	assert.Empty(t, getTagItems(t, db, tag.Id))
+
+	_, err = s.UpdateSmartTagQuery(ctx, tag.Id, `tag:Unwatched AND duration<30m`)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{shortHd.Id}, getTagItems(t, db, tag.Id))
+
+	_, err = s.UpdateSmartTagQuery(ctx, tag.Id, `tag:Unwatched AND`)
+	var parseError *querylang.ParseError
+	assert.ErrorAs(t, err, &parseError)
+
+	_, err = s.UpdateSmartTagQuery(ctx, unwatched.Id, `tag:Unwatched`)
+	assert.Error(t, err)
+}
@@ -6,12 +6,15 @@ import (
 	"my-collection/server/pkg/automix"
 	"my-collection/server/pkg/bl/directories"
 	"my-collection/server/pkg/bl/items"
+	"my-collection/server/pkg/bl/metadata"
+	"my-collection/server/pkg/bl/ratings"
 	"my-collection/server/pkg/bl/special_tags"
 	"my-collection/server/pkg/bl/tag_annotations"
 	"my-collection/server/pkg/bl/tags"
 	"my-collection/server/pkg/mixondemand"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/utils"
+	"regexp"
 	"time"
 
 	"github.com/op/go-logging"
@@ -130,8 +133,20 @@ func (d *Spectagger) autoSpectag(ctx context.Context) error {
 			continue
 		}
 
+		ratingTag, ratingsToRemove, err := getRatingTags(ctx, cachedTarw, &item)
+		if err != nil {
+			utils.LogError("Error getting rating tag", err)
+			continue
+		}
+
+		decadeTag, decadesToRemove, err := getDecadeTags(ctx, cachedTarw, &item)
+		if err != nil {
+			utils.LogError("Error getting decade tag", err)
+			continue
+		}
+
 		categoryTagsToAdd, categoryTagsToRemove := getCategoryTags(ctx, cachedTarw, categories, &item)
-		tagsToAdd := append(categoryTagsToAdd, videoCodecTag, audioCodecTag, durationTag, typeTag)
+		tagsToAdd := append(categoryTagsToAdd, videoCodecTag, audioCodecTag, durationTag, typeTag, ratingTag, decadeTag)
 		tagsToAdd = append(tagsToAdd, resolutionTags...)
 		tagsToAdd = removeNils(tagsToAdd)
 
@@ -140,7 +155,8 @@ func (d *Spectagger) autoSpectag(ctx context.Context) error {
 			continue
 		}
 
-		tagsToRemove := categoryTagsToRemove
+		tagsToRemove := append(categoryTagsToRemove, ratingsToRemove...)
+		tagsToRemove = append(tagsToRemove, decadesToRemove...)
 		if typeToRemove != nil {
 			tagsToRemove = append(tagsToRemove, typeToRemove)
 		}
@@ -177,6 +193,7 @@ func (d *Spectagger) GetUserCategories(ctx context.Context) (*[]model.Tag, error
 			category.Id == automix.GetDailymixTagId() ||
 			category.Id == mixondemand.GetMixOnDemandTagId() ||
 			category.Id == GetSpecTagId() ||
+			category.Id == special_tags.SmartTag.Id ||
 			category.Id == items.GetHighlightsTagId() {
 			continue
 		}
@@ -366,6 +383,77 @@ func getTypeTag(ctx context.Context, tarw model.TagAnnotationReaderWriter, item
 	}, tagToRemove, nil
 }
 
+// getRatingTags returns the bucket of the item's current rating, and the other buckets
+// so a changed rating moves the item between them.
+func getRatingTags(ctx context.Context, tarw model.TagAnnotationReaderWriter, item *model.Item) (*model.Tag, []*model.Tag, error) {
+	ta, err := tag_annotations.GetOrCreateTagAnnoation(ctx, tarw, &model.TagAnnotation{Title: "Rating"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ratingTag *model.Tag
+	tagsToRemove := make([]*model.Tag, 0)
+	for rating := 0; rating <= ratings.MaxRating; rating++ {
+		tag := &model.Tag{
+			ParentID:    &special_tags.SpecTag.Id,
+			Title:       getRatingTitle(rating),
+			Annotations: []*model.TagAnnotation{ta},
+		}
+
+		if rating == item.Rating {
+			ratingTag = tag
+		} else {
+			tagsToRemove = append(tagsToRemove, tag)
+		}
+	}
+
+	return ratingTag, tagsToRemove, nil
+}
+
+func getRatingTitle(rating int) string {
+	if rating == 0 {
+		return "Unrated"
+	}
+
+	return fmt.Sprintf("Rated %d", rating)
+}
+
+// getDecadeTags returns the decade of the item's release year, and the other decades the item
+// is tagged with so a changed or cleared year moves the item between them.
+func getDecadeTags(ctx context.Context, tarw model.TagAnnotationReaderWriter, item *model.Item) (*model.Tag, []*model.Tag, error) {
+	var decadeTag *model.Tag
+	title := ""
+	if decade := metadata.Decade(item); decade != 0 {
+		ta, err := tag_annotations.GetOrCreateTagAnnoation(ctx, tarw, &model.TagAnnotation{Title: "Decades"})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		title = getDecadeTitle(decade)
+		decadeTag = &model.Tag{
+			ParentID:    &special_tags.SpecTag.Id,
+			Title:       title,
+			Annotations: []*model.TagAnnotation{ta},
+		}
+	}
+
+	tagsToRemove := make([]*model.Tag, 0)
+	for _, tag := range item.Tags {
+		if tag.ParentID != nil && *tag.ParentID == special_tags.SpecTag.Id &&
+			tag.Title != title && decadeTitlePattern.MatchString(tag.Title) {
+			tagsToRemove = append(tagsToRemove, &model.Tag{ParentID: &special_tags.SpecTag.Id, Title: tag.Title})
+		}
+	}
+
+	return decadeTag, tagsToRemove, nil
+}
+
+var decadeTitlePattern = regexp.MustCompile(`^\d{3}0s$`)
+
+func getDecadeTitle(decade int) string {
+	return fmt.Sprintf("%ds", decade)
+}
+
 func getDurationTag(ctx context.Context, tarw model.TagAnnotationReaderWriter, item *model.Item) (*model.Tag, error) {
 	if item.DurationSeconds == 0 {
 		return nil, nil
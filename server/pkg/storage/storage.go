@@ -19,8 +19,17 @@ const (
 
 var logger = logging.MustGetLogger("storage")
 
+// storage directories named by the id of the entity owning the files, see the covers, main cover
+// and preview tasks, the tag thumbnails and the tag auto images
+var (
+	ItemDirectories         = []string{"covers", "main-covers", "previews"}
+	TagDirectories          = []string{"thumbnails", "tags-image-types"}
+	TagImageTypeDirectories = []string{"tit-icon"}
+)
+
 type Storage struct {
-	rootDirectory string
+	rootDirectory     string
+	templateDirectory string
 }
 
 func New(rootDirectory string) (*Storage, error) {
@@ -45,7 +54,8 @@ func New(rootDirectory string) (*Storage, error) {
 	logger.Infof("Srorage initialized in %s", rootDirectory)
 
 	return &Storage{
-		rootDirectory: rootDirectory,
+		rootDirectory:     rootDirectory,
+		templateDirectory: storageTemplateDirectory,
 	}, nil
 }
 
@@ -82,6 +92,22 @@ func (s *Storage) GetFileForWriting(name string) (string, error) {
 	return path, nil
 }
 
+// IsTemplateFile is true for files copied from the storage template, they're used
+// without being referenced by any entity
+func (s *Storage) IsTemplateFile(name string) bool {
+	info, err := os.Stat(filepath.Join(s.templateDirectory, strings.TrimPrefix(name, STORAGE_URL_PREFIX)))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Remove deletes a storage file or directory, either a storage url or a name relative to the storage
+func (s *Storage) Remove(name string) error {
+	if err := os.RemoveAll(s.GetFile(name)); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	return nil
+}
+
 func (s *Storage) GetTempFile() string {
 	return filepath.Join(s.rootDirectory, TEMP_DIRECTORY, uuid.New().String())
 }
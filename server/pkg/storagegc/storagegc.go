@@ -0,0 +1,246 @@
+package storagegc
+
+import (
+	"context"
+	"io/fs"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	"my-collection/server/pkg/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("storagegc")
+
+// files younger than this may belong to a task that didn't save its entity yet
+const DefaultMinAge = 24 * time.Hour
+
+// the processor queue and the cropped frames live in the storage, they're never referenced by entities
+var protectedDirectories = []string{"tasks", "frames"}
+
+type gcDb interface {
+	model.ItemReader
+	model.TagReader
+	model.TrashReader
+	model.TagImageTypeReader
+}
+
+type gcStorage interface {
+	model.StorageRemover
+	GetStorageDirectory(name string) string
+	IsTemplateFile(name string) bool
+}
+
+// New creates a collector that deletes orphaned storage files every interval,
+// a non positive interval only collects on demand
+func New(db gcDb, storage gcStorage, interval time.Duration, minAge time.Duration) *StorageGc {
+	return &StorageGc{
+		db:       db,
+		storage:  storage,
+		interval: interval,
+		minAge:   minAge,
+	}
+}
+
+type StorageGc struct {
+	db       gcDb
+	storage  gcStorage
+	interval time.Duration
+	minAge   time.Duration
+	lock     sync.Mutex
+}
+
+func (g *StorageGc) Run(ctx context.Context) error {
+	if g.interval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+
+	ctx = utils.ContextWithSubject(ctx, "storagegc")
+	for {
+		select {
+		case <-time.After(g.interval):
+			if _, err := g.CollectStorageGarbage(ctx, false); err != nil {
+				utils.LogError("Error collecting storage garbage", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// CollectStorageGarbage finds storage files not referenced by any item, tag or tag image type,
+// including the ones in the trash, and deletes them unless dryRun is set
+func (g *StorageGc) CollectStorageGarbage(ctx context.Context, dryRun bool) (*model.StorageGcReport, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	referenced, err := g.referencedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.StorageGcReport{DryRun: dryRun, Orphans: make([]string, 0)}
+	root := g.storage.GetStorageDirectory("")
+	cutoff := time.Now().Add(-g.minAge)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if isProtected(name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		report.ScannedFiles++
+		if referenced[name] || g.storage.IsTemplateFile(name) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		report.OrphanFiles++
+		report.ReclaimableBytes += info.Size()
+		report.Orphans = append(report.Orphans, name)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	logger.Infof("Found %d orphaned files out of %d, %d bytes reclaimable", report.OrphanFiles, report.ScannedFiles, report.ReclaimableBytes)
+	if dryRun {
+		return report, nil
+	}
+
+	for _, name := range report.Orphans {
+		if err := g.storage.Remove(name); err != nil {
+			utils.LogError("Error removing orphaned storage file", err)
+			continue
+		}
+		report.DeletedFiles++
+	}
+
+	removeEmptyDirectories(root)
+	return report, nil
+}
+
+func (g *StorageGc) referencedFiles(ctx context.Context) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	add := func(url string) {
+		if !strings.HasPrefix(url, storage.STORAGE_URL_PREFIX) {
+			return
+		}
+
+		referenced[filepath.Clean(strings.TrimPrefix(strings.TrimPrefix(url, storage.STORAGE_URL_PREFIX), "/"))] = true
+	}
+
+	items, err := g.db.GetAllItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trashedItems, err := g.db.GetDeletedItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range append(*items, *trashedItems...) {
+		for _, cover := range item.Covers {
+			add(cover.Url)
+		}
+		if item.MainCoverUrl != nil {
+			add(*item.MainCoverUrl)
+		}
+		add(item.PreviewUrl)
+	}
+
+	tags, err := g.db.GetAllTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trashedTags, err := g.db.GetDeletedTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range append(*tags, *trashedTags...) {
+		for _, image := range tag.Images {
+			add(image.Url)
+			add(image.ThumbnailUrl)
+		}
+	}
+
+	tits, err := g.db.GetAllTagImageTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tit := range *tits {
+		add(tit.IconUrl)
+	}
+
+	return referenced, nil
+}
+
+func isProtected(name string) bool {
+	for _, dir := range protectedDirectories {
+		if name == dir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeEmptyDirectories removes directories left empty by the collection, deepest first,
+// the top level directories are kept
+func removeEmptyDirectories(root string) {
+	dirs := make([]string, 0)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		name, _ := filepath.Rel(root, path)
+		if isProtected(name) {
+			return filepath.SkipDir
+		}
+
+		if strings.Contains(name, string(filepath.Separator)) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err == nil && len(entries) == 0 {
+			os.Remove(dir)
+		}
+	}
+}
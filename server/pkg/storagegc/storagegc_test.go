@@ -0,0 +1,87 @@
+package storagegc
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+func putFile(t *testing.T, s *storage.Storage, name string, size int, age time.Duration) {
+	path := s.GetFile(name)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	assert.NoError(t, os.WriteFile(path, make([]byte, size), 0640))
+	mtime := time.Now().Add(-age)
+	assert.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestCollectStorageGarbage(t *testing.T) {
+	db := setupNewDb(t, "storagegc.sqlite")
+	ctx := context.Background()
+	s, err := storage.New(t.TempDir())
+	assert.NoError(t, err)
+
+	item := &model.Item{Title: "item", Origin: "origin", PreviewUrl: ".internal-storage/previews/1/preview.mp4",
+		MainCoverUrl: ptr.To(".internal-storage/main-covers/1/main.png"),
+		Covers:       []model.Cover{{Url: ".internal-storage/covers/1/1.png"}}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	trashed := &model.Item{Title: "trashed", Origin: "origin", Covers: []model.Cover{{Url: ".internal-storage/covers/2/1.png"}}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, trashed))
+	assert.NoError(t, db.RemoveItem(ctx, trashed.Id))
+	tit := &model.TagImageType{Nickname: "icon", IconUrl: ".internal-storage/tags-image-types/1/icon.png"}
+	assert.NoError(t, db.CreateOrUpdateTagImageType(ctx, tit))
+
+	old := 48 * time.Hour
+	putFile(t, s, "previews/1/preview.mp4", 10, old)
+	putFile(t, s, "main-covers/1/main.png", 10, old)
+	putFile(t, s, "covers/1/1.png", 10, old)
+	putFile(t, s, "covers/2/1.png", 10, old)
+	putFile(t, s, "tags-image-types/1/icon.png", 10, old)
+	putFile(t, s, "covers/1/2.png", 100, old)
+	putFile(t, s, "covers/3/1.png", 200, old)
+	putFile(t, s, "covers/1/3.png", 300, time.Minute)
+	putFile(t, s, "tasks/queue", 400, old)
+	putFile(t, s, "frames/movie.mp4_2024-01-01_10_00_00.png", 500, old)
+
+	gc := New(db, s, 0, DefaultMinAge)
+	report, err := gc.CollectStorageGarbage(ctx, true)
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 8, report.ScannedFiles)
+	assert.Equal(t, 2, report.OrphanFiles)
+	assert.Equal(t, int64(300), report.ReclaimableBytes)
+	assert.ElementsMatch(t, []string{"covers/1/2.png", "covers/3/1.png"}, report.Orphans)
+	assert.Equal(t, 0, report.DeletedFiles)
+	assert.FileExists(t, s.GetFile("covers/3/1.png"))
+
+	report, err = gc.CollectStorageGarbage(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.DeletedFiles)
+	assert.NoFileExists(t, s.GetFile("covers/1/2.png"))
+	assert.NoDirExists(t, s.GetFile("covers/3"))
+	assert.FileExists(t, s.GetFile("covers/1/1.png"))
+	assert.FileExists(t, s.GetFile("covers/1/3.png"))
+	assert.FileExists(t, s.GetFile("covers/2/1.png"))
+	assert.FileExists(t, s.GetFile("tasks/queue"))
+	assert.FileExists(t, s.GetFile("frames/movie.mp4_2024-01-01_10_00_00.png"))
+	assert.DirExists(t, s.GetFile("covers"))
+}
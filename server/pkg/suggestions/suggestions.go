@@ -2,63 +2,161 @@ package suggestions
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"math/rand"
 	"my-collection/server/pkg/bl/items"
 	"my-collection/server/pkg/bl/special_tags"
 	"my-collection/server/pkg/bl/tags"
 	"my-collection/server/pkg/model"
+	"sort"
 )
 
-func GetSuggestionsForItem(ctx context.Context, ir model.ItemReader, tr model.TagReader, itemId uint64, count int) ([]*model.Item, error) {
+const (
+	sameDirectoryScore = 1.0
+	durationScore      = 0.5
+	resolutionScore    = 0.25
+
+	// duration and resolution only count when they are at least that close
+	minClosenessRatio = 0.8
+)
+
+// GetSuggestionsForItem ranks the items of the library by their similarity to the given item,
+// shared tags count by how rare they are, and items from the same directory or with a close
+// duration and resolution get a bonus. Items without any similarity are returned last in
+// random order, so the result is full as long as the library has enough items.
+func GetSuggestionsForItem(ctx context.Context, ir model.ItemReader, tr model.TagReader, itemId uint64, count int) ([]model.Suggestion, error) {
 	item, err := ir.GetItem(ctx, itemId)
 	if err != nil {
 		return nil, err
 	}
 
-	t, err := tags.GetFullTags(ctx, tr, item.Tags)
+	itemTags, err := tags.GetFullTags(ctx, tr, item.Tags)
 	if err != nil {
 		return nil, err
 	}
 
-	return GetSuggestionsForTags(ctx, ir, tr, t, count)
-}
-
-func GetSuggestionsForTags(ctx context.Context, ir model.ItemReader, tr model.TagReader, tags *[]model.Tag, count int) ([]*model.Item, error) {
-	relatedItems, err := getItemsOfTags(ctx, ir, tags)
+	allItems, err := ir.GetAllItems(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(relatedItems) < count {
-		randomItems, err := items.GetRandomItems(ctx, ir, count-len(relatedItems), func(item *model.Item) bool {
-			return !items.IsHighlight(item) && !items.IsSplittedItem(item)
+	candidates := make([]*model.Item, 0, len(*allItems))
+	for i := range *allItems {
+		candidate := &(*allItems)[i]
+		if isCandidate(item, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	idf := getTagsIdf(candidates)
+	result := make([]model.Suggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, scoreItem(item, itemTags, candidate, idf))
+	}
+
+	rand.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	if count < len(result) {
+		result = result[:count]
+	}
+
+	return result, nil
+}
+
+func isCandidate(item *model.Item, candidate *model.Item) bool {
+	if candidate.Id == item.Id || items.IsHighlight(candidate) || items.IsSplittedItem(candidate) {
+		return false
+	}
+
+	if candidate.MainItemId != nil && *candidate.MainItemId == item.Id {
+		return false
+	}
+
+	return true
+}
+
+func isRelevantTag(tag *model.Tag) bool {
+	return tag.ParentID != nil && !special_tags.IsSpecial(*tag.ParentID)
+}
+
+// getTagsIdf returns the inverse document frequency of every tag of the candidates,
+// a tag attached to a few items says more about them than a tag attached to most.
+func getTagsIdf(candidates []*model.Item) map[uint64]float64 {
+	counts := make(map[uint64]int)
+	for _, candidate := range candidates {
+		for _, tag := range candidate.Tags {
+			if isRelevantTag(tag) {
+				counts[tag.Id]++
+			}
+		}
+	}
+
+	result := make(map[uint64]float64, len(counts))
+	for tagId, count := range counts {
+		result[tagId] = math.Log(1 + float64(len(candidates))/float64(count))
+	}
+
+	return result
+}
+
+func scoreItem(item *model.Item, itemTags *[]model.Tag, candidate *model.Item, idf map[uint64]float64) model.Suggestion {
+	suggestion := model.Suggestion{
+		Item:    candidate,
+		Reasons: make([]model.SuggestionReason, 0),
+	}
+
+	addReason := func(kind model.SuggestionReasonKind, score float64, description string) {
+		suggestion.Score += score
+		suggestion.Reasons = append(suggestion.Reasons, model.SuggestionReason{
+			Kind:        kind,
+			Description: description,
+			Score:       score,
 		})
-		if err != nil {
-			return nil, err
+	}
+
+	for _, tag := range *itemTags {
+		if !isRelevantTag(&tag) || !items.TagExists(candidate.Tags, &tag) {
+			continue
 		}
 
-		relatedItems = append(relatedItems, randomItems...)
+		addReason(model.SUGGESTION_REASON_TAG, idf[tag.Id], fmt.Sprintf("Tagged %s", tag.Title))
 	}
 
-	// Safety check: if we still don't have enough items, adjust count
-	if len(relatedItems) == 0 {
-		return []*model.Item{}, nil
+	if item.Origin != "" && candidate.Origin == item.Origin {
+		addReason(model.SUGGESTION_REASON_DIRECTORY, sameDirectoryScore, fmt.Sprintf("Same directory %s", item.Origin))
 	}
 
-	if count > len(relatedItems) {
-		count = len(relatedItems)
+	if closeness := getCloseness(item.DurationSeconds, candidate.DurationSeconds); closeness >= minClosenessRatio {
+		addReason(model.SUGGESTION_REASON_DURATION, durationScore*closeness,
+			fmt.Sprintf("Similar duration %.0f%%", closeness*100))
 	}
 
-	resultIndexes := getUniqueRandomNumbers(len(relatedItems), count)
-	result := make([]*model.Item, len(resultIndexes))
-	for i, n := range resultIndexes {
-		result[i] = relatedItems[n-1]
+	if closeness := getCloseness(float64(item.Width*item.Height), float64(candidate.Width*candidate.Height)); closeness >= minClosenessRatio {
+		addReason(model.SUGGESTION_REASON_RESOLUTION, resolutionScore*closeness,
+			fmt.Sprintf("Similar resolution %dx%d", candidate.Width, candidate.Height))
 	}
 
-	return result, nil
+	return suggestion
+}
+
+// getCloseness returns 1 for equal values down to 0 for values far apart, unknown
+// values are never close.
+func getCloseness(a float64, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+
+	return math.Min(a, b) / math.Max(a, b)
 }
 
-func getItemsOfTags(ctx context.Context, ir model.ItemReader, t *[]model.Tag) ([]*model.Item, error) {
+func GetItemsOfTags(ctx context.Context, ir model.ItemReader, t *[]model.Tag) ([]*model.Item, error) {
 	relatedItems := make([]*model.Item, 0)
 
 	for _, tag := range *t {
@@ -81,38 +179,3 @@ func getItemsOfTags(ctx context.Context, ir model.ItemReader, t *[]model.Tag) ([
 
 	return relatedItems, nil
 }
-
-func getUniqueRandomNumbers(max int, count int) []int {
-	// Safety check: can't generate more unique numbers than available
-	if count > max {
-		count = max
-	}
-
-	// If no numbers can be generated, return empty slice
-	if max <= 0 || count <= 0 {
-		return []int{}
-	}
-
-	result := make([]int, count)
-
-	for i := 0; i < count; i++ {
-		num := rand.Intn(max) + 1
-		for numberExists(result, num) {
-			num = rand.Intn(max) + 1
-		}
-
-		result[i] = num
-	}
-
-	return result
-}
-
-func numberExists(nums []int, num int) bool {
-	for _, n := range nums {
-		if n == num {
-			return true
-		}
-	}
-
-	return false
-}
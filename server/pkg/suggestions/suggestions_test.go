@@ -1,54 +1,79 @@
 package suggestions
 
 import (
+	"context"
+	"my-collection/server/pkg/model"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/utils/pointer"
 )
 
-func TestGetUniqueRandomNumbers_NoInfiniteLoop(t *testing.T) {
-	t.Run("Request more numbers than available", func(t *testing.T) {
-		// This should not hang and should return at most 3 numbers
-		result := getUniqueRandomNumbers(3, 10)
-		assert.LessOrEqual(t, len(result), 3, "Should not return more numbers than available")
-		assert.GreaterOrEqual(t, len(result), 1, "Should return at least 1 number when max > 0")
-	})
+func TestGetSuggestionsForItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	t.Run("Request zero numbers", func(t *testing.T) {
-		result := getUniqueRandomNumbers(5, 0)
-		assert.Empty(t, result, "Should return empty slice when count is 0")
-	})
+	category := pointer.Uint64(1)
+	common := model.Tag{Id: 10, Title: "common", ParentID: category}
+	rare := model.Tag{Id: 11, Title: "rare", ParentID: category}
 
-	t.Run("Max is zero", func(t *testing.T) {
-		result := getUniqueRandomNumbers(0, 5)
-		assert.Empty(t, result, "Should return empty slice when max is 0")
-	})
+	item := &model.Item{Id: 1, Origin: "movies", DurationSeconds: 100, Width: 1920, Height: 1080,
+		Tags: []*model.Tag{{Id: common.Id}, {Id: rare.Id}}}
+	allItems := []model.Item{
+		*item,
+		{Id: 2, Origin: "other", Tags: []*model.Tag{{Id: common.Id, ParentID: category}}},
+		{Id: 3, Origin: "other", Tags: []*model.Tag{{Id: common.Id, ParentID: category}}},
+		{Id: 4, Origin: "other", Tags: []*model.Tag{{Id: common.Id, ParentID: category}}},
+		{Id: 5, Origin: "other", Tags: []*model.Tag{{Id: rare.Id, ParentID: category}}},
+		{Id: 6, Origin: "movies", DurationSeconds: 95, Width: 1920, Height: 1080},
+		{Id: 7, Origin: "other", DurationSeconds: 10},
+		{Id: 8, Origin: "movies", MainItemId: pointer.Uint64(1)},
+	}
 
-	t.Run("Normal case", func(t *testing.T) {
-		result := getUniqueRandomNumbers(10, 5)
-		assert.Len(t, result, 5, "Should return exactly 5 numbers")
-
-		// Verify all numbers are unique
-		seen := make(map[int]bool)
-		for _, num := range result {
-			assert.False(t, seen[num], "Number %d should be unique", num)
-			assert.GreaterOrEqual(t, num, 1, "Number should be >= 1")
-			assert.LessOrEqual(t, num, 10, "Number should be <= max")
-			seen[num] = true
-		}
-	})
+	ir := model.NewMockItemReader(ctrl)
+	tr := model.NewMockTagReader(ctrl)
+	ir.EXPECT().GetItem(gomock.Any(), item.Id).Return(item, nil).AnyTimes()
+	ir.EXPECT().GetAllItems(gomock.Any()).Return(&allItems, nil).AnyTimes()
+	tr.EXPECT().GetTags(gomock.Any(), gomock.Any()).Return(&[]model.Tag{common, rare}, nil).AnyTimes()
 
-	t.Run("Equal count and max", func(t *testing.T) {
-		result := getUniqueRandomNumbers(5, 5)
-		assert.Len(t, result, 5, "Should return exactly 5 numbers")
+	t.Run("ranked by similarity", func(t *testing.T) {
+		result, err := GetSuggestionsForItem(context.Background(), ir, tr, item.Id, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, len(result), "the item itself and its sub-items are not suggested")
 
-		// Should contain all numbers from 1 to 5
-		seen := make(map[int]bool)
-		for _, num := range result {
-			seen[num] = true
-		}
-		for i := 1; i <= 5; i++ {
-			assert.True(t, seen[i], "Should contain number %d", i)
+		// a rare tag outweighs the same directory, duration and resolution
+		assert.Equal(t, uint64(5), result[0].Item.Id)
+		assert.Equal(t, 1, len(result[0].Reasons))
+		assert.Equal(t, model.SUGGESTION_REASON_TAG, result[0].Reasons[0].Kind)
+		assert.Equal(t, "Tagged rare", result[0].Reasons[0].Description)
+
+		assert.Equal(t, uint64(6), result[1].Item.Id)
+		assert.Equal(t, 3, len(result[1].Reasons))
+
+		// while a common tag counts less
+		for _, suggestion := range result[2:5] {
+			assert.Less(t, suggestion.Score, result[1].Score)
+			assert.Contains(t, []uint64{2, 3, 4}, suggestion.Item.Id)
 		}
+
+		assert.Equal(t, uint64(7), result[5].Item.Id)
+		assert.Equal(t, 0.0, result[5].Score)
+		assert.Empty(t, result[5].Reasons)
+	})
+
+	t.Run("limited by count", func(t *testing.T) {
+		result, err := GetSuggestionsForItem(context.Background(), ir, tr, item.Id, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, uint64(5), result[0].Item.Id)
+		assert.Equal(t, uint64(6), result[1].Item.Id)
 	})
 }
+
+func TestGetCloseness(t *testing.T) {
+	assert.Equal(t, 1.0, getCloseness(10, 10))
+	assert.Equal(t, 0.5, getCloseness(10, 20))
+	assert.Equal(t, 0.5, getCloseness(20, 10))
+	assert.Equal(t, 0.0, getCloseness(0, 10))
+}
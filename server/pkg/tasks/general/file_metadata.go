@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"my-collection/server/pkg/fingerprint"
 	"my-collection/server/pkg/model"
 	"my-collection/server/pkg/relativasor"
 	"os"
@@ -70,7 +71,13 @@ func updateMetadata(item *model.Item) error {
 		return errors.Wrap(err, 1)
 	}
 
+	fingerprint, err := fingerprint.File(path)
+	if err != nil {
+		return err
+	}
+
 	item.LastModified = file.ModTime().UnixMilli()
 	item.FileSize = file.Size()
+	item.Fingerprint = fingerprint
 	return nil
 }
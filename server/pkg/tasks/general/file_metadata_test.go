@@ -66,6 +66,7 @@ func TestUpdateFileMetadata(t *testing.T) {
 	mockIRW.EXPECT().UpdateItem(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, item *model.Item) error {
 		assert.Greater(t, item.LastModified, int64(0))
 		assert.GreaterOrEqual(t, item.FileSize, int64(0))
+		assert.NotEmpty(t, item.Fingerprint)
 		return nil
 	})
 
@@ -171,6 +172,7 @@ func TestUpdateMetadata(t *testing.T) {
 	err = updateMetadata(item)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedSize, item.FileSize)
+	assert.NotEmpty(t, item.Fingerprint)
 	// ModTime should be set (might be slightly different due to timing)
 	assert.GreaterOrEqual(t, item.LastModified, expectedModTime)
 }
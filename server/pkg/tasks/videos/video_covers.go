@@ -70,12 +70,12 @@ func refreshVideoCovers(ctx context.Context, irw model.ItemReaderWriter, uploade
 	return nil
 }
 
-func getDurationForItem(item *model.Item, videoFile string) (float64, error) {
+func getDurationForItem(ctx context.Context, item *model.Item, videoFile string) (float64, error) {
 	if items.IsSubItem(item) || items.IsHighlight(item) {
 		return item.DurationSeconds, nil
 	}
 
-	duration, err := ffmpeg.GetDurationInSeconds(videoFile)
+	duration, err := ffmpeg.GetDurationInSeconds(ctx, videoFile)
 	if err != nil {
 		vcLogger.Errorf("Error getting duration of a video %s", videoFile)
 		return 0, err
@@ -89,7 +89,7 @@ func refreshItemCover(ctx context.Context, irw model.ItemReaderWriter, uploader
 	videoFile := relativasor.GetAbsoluteFile(item.Url)
 	vcLogger.Infof("Setting cover for item %d [coverNumber: %d] [videoFile: %s]", item.Id, coverNumber, videoFile)
 
-	duration, err := getDurationForItem(item, videoFile)
+	duration, err := getDurationForItem(ctx, item, videoFile)
 	if err != nil {
 		return err
 	}
@@ -107,7 +107,7 @@ func refreshItemCover(ctx context.Context, irw model.ItemReaderWriter, uploader
 	}
 
 	screenshotSecond := startOffset + ((duration / float64(coversCount+1)) * float64(coverNumber))
-	if err := ffmpeg.TakeScreenshot(videoFile, screenshotSecond, storageFile); err != nil {
+	if err := ffmpeg.TakeScreenshot(ctx, videoFile, screenshotSecond, storageFile); err != nil {
 		vcLogger.Errorf("Error taking screenshot for item %d, error %v", item.Id, err)
 		return err
 	}
@@ -88,7 +88,7 @@ func TestGetDurationForItem_SubItem(t *testing.T) {
 		DurationSeconds: 50.0,
 	}
 
-	duration, err := getDurationForItem(item, "/path/to/video.mp4")
+	duration, err := getDurationForItem(context.Background(), item, "/path/to/video.mp4")
 	assert.NoError(t, err)
 	assert.Equal(t, 50.0, duration)
 }
@@ -100,7 +100,7 @@ func TestGetDurationForItem_Highlight(t *testing.T) {
 		DurationSeconds:       30.0,
 	}
 
-	duration, err := getDurationForItem(item, "/path/to/video.mp4")
+	duration, err := getDurationForItem(context.Background(), item, "/path/to/video.mp4")
 	assert.NoError(t, err)
 	assert.Equal(t, 30.0, duration)
 }
@@ -72,7 +72,7 @@ func cropVideoFrame(ctx context.Context, irw model.ItemReaderWriter, uploader mo
 		return err
 	}
 
-	if err := ffmpeg.CropScreenshot(videoFile, p.Second, rect, storageFile); err != nil {
+	if err := ffmpeg.CropScreenshot(ctx, videoFile, p.Second, rect, storageFile); err != nil {
 		cropLogger.Errorf("Error cropping frame for item %d, error %v", item.Id, err)
 		return err
 	}
@@ -0,0 +1,93 @@
+package video_tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"my-collection/server/pkg/ffmpeg"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/phash"
+	"my-collection/server/pkg/relativasor"
+	"os"
+	"path/filepath"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+)
+
+var vhLogger = logging.MustGetLogger("video-hash")
+
+// VideoHashFrames is the number of frames hashed along every item, items are only comparable
+// when hashed with the same number of frames
+const VideoHashFrames = 8
+
+type videoHashParams struct {
+	ItemId uint64 `json:"id"`
+}
+
+func VideoHashDesc(id uint64, title string) string {
+	return fmt.Sprintf("Hash frames of %s", title)
+}
+
+func MarshalVideoHashParams(id uint64) (string, error) {
+	p := videoHashParams{ItemId: id}
+	res, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+func unmarshalVideoHashParams(params string) (videoHashParams, error) {
+	var p videoHashParams
+	if err := json.Unmarshal([]byte(params), &p); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func RefreshVideoHash(ctx context.Context, ir model.ItemReader, hw model.VideoHashWriter, params string) error {
+	p, err := unmarshalVideoHashParams(params)
+	if err != nil {
+		return err
+	}
+
+	return refreshVideoHash(ctx, ir, hw, p)
+}
+
+func refreshVideoHash(ctx context.Context, ir model.ItemReader, hw model.VideoHashWriter, p videoHashParams) error {
+	item, err := ir.GetItem(ctx, p.ItemId)
+	if err != nil {
+		return err
+	}
+
+	if item.DurationSeconds == 0 {
+		return errors.Errorf("item %d has no duration, refresh its metadata first", item.Id)
+	}
+
+	tempDir, err := os.MkdirTemp("", "video-hash-")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer os.RemoveAll(tempDir)
+
+	videoFile := relativasor.GetAbsoluteFile(item.Url)
+	vhLogger.Infof("Hashing %d frames of item %d", VideoHashFrames, item.Id)
+
+	hashes := make([]uint64, VideoHashFrames)
+	for i := range hashes {
+		second := item.StartPosition + item.DurationSeconds*float64(i+1)/float64(VideoHashFrames+1)
+		frameFile := filepath.Join(tempDir, fmt.Sprintf("frame-%d.png", i))
+		if err := ffmpeg.TakeScreenshot(ctx, videoFile, second, frameFile); err != nil {
+			vhLogger.Errorf("Error taking screenshot for item %d, error %v", item.Id, err)
+			return err
+		}
+
+		hashes[i], err = phash.FileDHash(frameFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	return hw.SaveVideoHash(ctx, &model.VideoHash{ItemId: item.Id, Hashes: phash.FormatHashes(hashes)})
+}
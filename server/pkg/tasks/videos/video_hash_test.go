@@ -0,0 +1,61 @@
+package video_tasks
+
+import (
+	"context"
+	"encoding/json"
+	"my-collection/server/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVideoHashDesc(t *testing.T) {
+	result := VideoHashDesc(123, "test.mp4")
+	assert.Equal(t, "Hash frames of test.mp4", result)
+}
+
+func TestMarshalVideoHashParams(t *testing.T) {
+	params, err := MarshalVideoHashParams(123)
+	assert.NoError(t, err)
+
+	var p videoHashParams
+	err = json.Unmarshal([]byte(params), &p)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(123), p.ItemId)
+}
+
+func TestUnmarshalVideoHashParams_InvalidJSON(t *testing.T) {
+	_, err := unmarshalVideoHashParams("invalid json")
+	assert.Error(t, err)
+}
+
+func TestRefreshVideoHash_GetItemError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIR := model.NewMockItemReader(ctrl)
+	mockHW := model.NewMockVideoHashWriter(ctrl)
+	ctx := context.Background()
+
+	mockIR.EXPECT().GetItem(ctx, uint64(123)).Return(nil, assert.AnError)
+
+	params, _ := MarshalVideoHashParams(123)
+	err := RefreshVideoHash(ctx, mockIR, mockHW, params)
+	assert.Error(t, err)
+}
+
+func TestRefreshVideoHash_NoDuration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIR := model.NewMockItemReader(ctrl)
+	mockHW := model.NewMockVideoHashWriter(ctrl)
+	ctx := context.Background()
+
+	mockIR.EXPECT().GetItem(ctx, uint64(123)).Return(&model.Item{Id: 123}, nil)
+
+	params, _ := MarshalVideoHashParams(123)
+	err := RefreshVideoHash(ctx, mockIR, mockHW, params)
+	assert.Error(t, err)
+}
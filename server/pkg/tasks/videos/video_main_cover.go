@@ -63,7 +63,7 @@ func updateVideoMainCover(ctx context.Context, irw model.ItemReaderWriter, uploa
 		return err
 	}
 
-	if err := ffmpeg.TakeScreenshot(videoFile, p.Second, storageFile); err != nil {
+	if err := ffmpeg.TakeScreenshot(ctx, videoFile, p.Second, storageFile); err != nil {
 		vcLogger.Errorf("Error taking screenshot for item %d, error %v", item.Id, err)
 		return err
 	}
@@ -59,7 +59,7 @@ func updateVideoMetadata(ctx context.Context, irw model.ItemReaderWriter, p vide
 			return err
 		}
 	} else {
-		if err := updateMainItemMetadata(item); err != nil {
+		if err := updateMainItemMetadata(ctx, item); err != nil {
 			return err
 		}
 	}
@@ -82,23 +82,23 @@ func updateNonMainItemMetadata(ctx context.Context, ir model.ItemReader, item *m
 	return nil
 }
 
-func updateMainItemMetadata(item *model.Item) error {
+func updateMainItemMetadata(ctx context.Context, item *model.Item) error {
 	videoFile := relativasor.GetAbsoluteFile(item.Url)
 	vmLogger.Infof("Refreshing video metadata for item %d  [videoFile: %s]", item.Id, videoFile)
 
-	duration, err := ffmpeg.GetDurationInSeconds(videoFile)
+	duration, err := ffmpeg.GetDurationInSeconds(ctx, videoFile)
 	if err != nil {
 		vmLogger.Errorf("Error getting duration of a video %s", videoFile)
 		return err
 	}
 
-	rawVideoMetadata, err := ffmpeg.GetVideoMetadata(videoFile)
+	rawVideoMetadata, err := ffmpeg.GetVideoMetadata(ctx, videoFile)
 	if err != nil {
 		vmLogger.Errorf("Error getting video metadata of %s", videoFile)
 		return err
 	}
 
-	rawAudioMetadata, err := ffmpeg.GetAudioMetadata(videoFile)
+	rawAudioMetadata, err := ffmpeg.GetAudioMetadata(ctx, videoFile)
 	if err != nil {
 		vmLogger.Errorf("Error getting audio metadata of %s", videoFile)
 		return err
@@ -10,6 +10,7 @@ import (
 	"my-collection/server/pkg/relativasor"
 	"os"
 
+	"github.com/go-errors/errors"
 	"github.com/op/go-logging"
 )
 
@@ -68,7 +69,7 @@ func refreshVideoPreview(ctx context.Context, irw model.ItemReaderWriter, upload
 	pLogger.Infof("Setting preview for item %d [videoFile: %s] [count: %d] [duration: %d]",
 		item.Id, item.Url, p.SceneCount, p.SceneDuration)
 
-	videoParts, err := getPreviewParts(uploader, item, p.SceneCount, p.SceneDuration)
+	videoParts, err := getPreviewParts(ctx, uploader, item, p.SceneCount, p.SceneDuration)
 	defer func() {
 		for _, file := range videoParts {
 			os.Remove(file)
@@ -85,25 +86,32 @@ func refreshVideoPreview(ctx context.Context, irw model.ItemReaderWriter, upload
 		return err
 	}
 
-	if err := ffmpeg.JoinVideoFiles(videoParts, storageFile); err != nil {
+	// the preview is built aside and replaces the previous one only when it's complete
+	joinedFile := fmt.Sprintf("%s.mp4", uploader.GetTempFile())
+	defer os.Remove(joinedFile)
+	if err := ffmpeg.JoinVideoFiles(ctx, videoParts, joinedFile); err != nil {
 		pLogger.Errorf("Error joining video files for item %d, error %v", item.Id, err)
 		return err
 	}
 
 	tempFile := fmt.Sprintf("%s.mp4", uploader.GetTempFile())
-	if err := ffmpeg.OptimizeVideoForPreview(storageFile, tempFile); err != nil {
+	if err := ffmpeg.OptimizeVideoForPreview(ctx, joinedFile, tempFile); err != nil {
 		pLogger.Errorf("Error optimizing video file for item %d, error %v", item.Id, err)
 		return err
 	}
 
+	if err := os.Rename(joinedFile, storageFile); err != nil {
+		return errors.Wrap(err, 0)
+	}
+
 	item.PreviewUrl = uploader.GetStorageUrl(relativeFile)
 	return irw.UpdateItem(ctx, item)
 }
 
-func getPreviewParts(uploader model.StorageUploader, item *model.Item,
+func getPreviewParts(ctx context.Context, uploader model.StorageUploader, item *model.Item,
 	previewSceneCount int, previewSceneDuration int) ([]string, error) {
 	videoFile := relativasor.GetAbsoluteFile(item.Url)
-	duration, err := getDurationForItem(item, videoFile)
+	duration, err := getDurationForItem(ctx, item, videoFile)
 	if err != nil {
 		return nil, err
 	}
@@ -119,9 +127,10 @@ func getPreviewParts(uploader model.StorageUploader, item *model.Item,
 		tempFile := fmt.Sprintf("%s.mp4", uploader.GetTempFile())
 		result = append(result, tempFile)
 
-		if err := ffmpeg.ExtractPartOfVideo(videoFile, startSecond, previewSceneDuration, tempFile); err != nil {
+		if err := ffmpeg.ExtractPartOfVideo(ctx, videoFile, startSecond, previewSceneDuration, tempFile); err != nil {
 			pLogger.Errorf("Error extracting part of video for item %d, error %v", item.Id, err)
-			return nil, err
+			// the parts extracted so far are returned to be removed
+			return result, err
 		}
 	}
 
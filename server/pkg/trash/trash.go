@@ -0,0 +1,240 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/model"
+	"my-collection/server/pkg/storage"
+	"my-collection/server/pkg/utils"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/op/go-logging"
+	"gorm.io/gorm"
+)
+
+var logger = logging.MustGetLogger("trash")
+
+const purgeInterval = time.Hour
+
+// items whose parent is in the trash are listed, restored and purged with their parent
+const notTrashedWithParent = `(highlight_parent_item_id is null or highlight_parent_item_id in (select id from items where deleted_at is null))
+	and (main_item_id is null or main_item_id in (select id from items where deleted_at is null))`
+
+type trashDb interface {
+	model.TrashReaderWriter
+}
+
+// New creates the trash, items and tags deleted more than retention ago are purged periodically,
+// a non positive retention keeps them until purged explicitly
+func New(db trashDb, remover model.StorageRemover, retention time.Duration) *Trash {
+	return &Trash{
+		db:        db,
+		remover:   remover,
+		retention: retention,
+	}
+}
+
+type Trash struct {
+	db        trashDb
+	remover   model.StorageRemover
+	retention time.Duration
+}
+
+func (t *Trash) Run(ctx context.Context) error {
+	ctx = utils.ContextWithSubject(ctx, "trash")
+	for {
+		select {
+		case <-time.After(purgeInterval):
+			if t.retention <= 0 {
+				continue
+			}
+
+			if _, err := t.PurgeExpired(ctx); err != nil {
+				utils.LogError("Error purging trash", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (t *Trash) List(ctx context.Context) (*model.Trash, error) {
+	items, err := t.db.GetDeletedItems(ctx, notTrashedWithParent)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := t.db.GetDeletedTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Trash{
+		Items: make([]model.TrashedItem, 0, len(*items)),
+		Tags:  make([]model.TrashedTag, 0, len(*tags)),
+	}
+
+	for _, item := range *items {
+		result.Items = append(result.Items, model.TrashedItem{Item: item, DeletedAt: item.DeletedAt.Time.UnixMilli()})
+	}
+
+	for _, tag := range *tags {
+		result.Tags = append(result.Tags, model.TrashedTag{Tag: tag, DeletedAt: tag.DeletedAt.Time.UnixMilli()})
+	}
+
+	return result, nil
+}
+
+func (t *Trash) RestoreItem(ctx context.Context, itemId uint64) error {
+	logger.Infof("Restoring item %d from trash", itemId)
+	return t.db.RestoreItem(ctx, itemId)
+}
+
+func (t *Trash) RestoreTag(ctx context.Context, tagId uint64) error {
+	logger.Infof("Restoring tag %d from trash", tagId)
+	return t.db.RestoreTag(ctx, tagId)
+}
+
+// PurgeItem deletes a trashed item for good, along with its highlights, sub items and their storage files
+func (t *Trash) PurgeItem(ctx context.Context, itemId uint64) error {
+	items, err := t.db.GetDeletedItems(ctx, "id = ? or highlight_parent_item_id = ? or main_item_id = ?", itemId, itemId, itemId)
+	if err != nil {
+		return err
+	}
+
+	if !containsItem(*items, itemId) {
+		return errors.Wrap(fmt.Errorf("item %d is not in the trash: %w", itemId, gorm.ErrRecordNotFound), 0)
+	}
+
+	logger.Infof("Purging item %d", itemId)
+	if err := t.db.PurgeItem(ctx, itemId); err != nil {
+		return err
+	}
+
+	for _, item := range *items {
+		t.removeFiles(fmt.Sprint(item.Id), storage.ItemDirectories, itemStorageUrls(&item))
+	}
+
+	return nil
+}
+
+// PurgeTag deletes a trashed tag for good, along with its storage files
+func (t *Trash) PurgeTag(ctx context.Context, tagId uint64) error {
+	tags, err := t.db.GetDeletedTags(ctx, "id = ?", tagId)
+	if err != nil {
+		return err
+	}
+
+	if len(*tags) == 0 {
+		return errors.Wrap(fmt.Errorf("tag %d is not in the trash: %w", tagId, gorm.ErrRecordNotFound), 0)
+	}
+
+	logger.Infof("Purging tag %d", tagId)
+	if err := t.db.PurgeTag(ctx, tagId); err != nil {
+		return err
+	}
+
+	t.removeFiles(fmt.Sprint(tagId), storage.TagDirectories, tagStorageUrls(&(*tags)[0]))
+	return nil
+}
+
+// PurgeExpired purges the items and tags deleted more than the retention ago
+func (t *Trash) PurgeExpired(ctx context.Context) (*model.PurgeResult, error) {
+	return t.PurgeOlderThan(ctx, time.Now().Add(-t.retention))
+}
+
+func (t *Trash) PurgeOlderThan(ctx context.Context, cutoff time.Time) (*model.PurgeResult, error) {
+	result := &model.PurgeResult{}
+	items, err := t.db.GetDeletedItems(ctx, fmt.Sprintf("deleted_at < ? and %s", notTrashedWithParent), cutoff)
+	if err != nil {
+		return result, err
+	}
+
+	tags, err := t.db.GetDeletedTags(ctx, "deleted_at < ?", cutoff)
+	if err != nil {
+		return result, err
+	}
+
+	var lastError error
+	for _, item := range *items {
+		if err := t.PurgeItem(ctx, item.Id); err != nil {
+			utils.LogError(fmt.Sprintf("Error purging item %d", item.Id), err)
+			lastError = err
+			continue
+		}
+		result.Items++
+	}
+
+	for _, tag := range *tags {
+		if err := t.PurgeTag(ctx, tag.Id); err != nil {
+			utils.LogError(fmt.Sprintf("Error purging tag %d", tag.Id), err)
+			lastError = err
+			continue
+		}
+		result.Tags++
+	}
+
+	if result.Items > 0 || result.Tags > 0 {
+		logger.Infof("Purged %d items and %d tags from trash", result.Items, result.Tags)
+	}
+
+	return result, lastError
+}
+
+func (t *Trash) removeFiles(id string, directories []string, urls []string) {
+	for _, dir := range directories {
+		if err := t.remover.Remove(filepath.Join(dir, id)); err != nil {
+			utils.LogError("Error removing storage directory", err)
+		}
+	}
+
+	for _, url := range urls {
+		if err := t.remover.Remove(url); err != nil {
+			utils.LogError("Error removing storage file", err)
+		}
+	}
+}
+
+func containsItem(items []model.Item, itemId uint64) bool {
+	for _, item := range items {
+		if item.Id == itemId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func itemStorageUrls(item *model.Item) []string {
+	urls := make([]string, 0)
+	for _, cover := range item.Covers {
+		urls = appendStorageUrl(urls, cover.Url)
+	}
+
+	if item.MainCoverUrl != nil {
+		urls = appendStorageUrl(urls, *item.MainCoverUrl)
+	}
+
+	return appendStorageUrl(urls, item.PreviewUrl)
+}
+
+func tagStorageUrls(tag *model.Tag) []string {
+	urls := make([]string, 0)
+	for _, image := range tag.Images {
+		urls = appendStorageUrl(urls, image.Url)
+		urls = appendStorageUrl(urls, image.ThumbnailUrl)
+	}
+
+	return urls
+}
+
+func appendStorageUrl(urls []string, url string) []string {
+	if !strings.HasPrefix(url, storage.STORAGE_URL_PREFIX) {
+		return urls
+	}
+
+	return append(urls, url)
+}
@@ -0,0 +1,112 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"my-collection/server/pkg/db"
+	"my-collection/server/pkg/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"k8s.io/utils/ptr"
+)
+
+func setupNewDb(t *testing.T, filename string) db.Database {
+	assert.NoError(t, os.MkdirAll(".tests", 0750))
+	dbpath := fmt.Sprintf(".tests/%s", filename)
+	_, err := os.Create(dbpath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(dbpath))
+	db, err := db.New(filepath.Join("", dbpath), false)
+	assert.NoError(t, err)
+	return db
+}
+
+type removerRecorder struct {
+	removed []string
+}
+
+func (r *removerRecorder) Remove(name string) error {
+	r.removed = append(r.removed, name)
+	return nil
+}
+
+func TestListAndRestore(t *testing.T) {
+	db := setupNewDb(t, "trash-list.sqlite")
+	ctx := context.Background()
+	trash := New(db, &removerRecorder{}, 0)
+
+	item := &model.Item{Title: "item", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	highlight := &model.Item{Title: "highlight", Origin: "origin", HighlightParentItemId: &item.Id}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, highlight))
+	category := &model.Tag{Title: "category"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, category))
+
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+	assert.NoError(t, db.RemoveTag(ctx, category.Id))
+
+	result, err := trash.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1) // the highlight is listed with its parent
+	assert.Equal(t, item.Id, result.Items[0].Item.Id)
+	assert.NotZero(t, result.Items[0].DeletedAt)
+	assert.Len(t, result.Tags, 1)
+	assert.Equal(t, category.Id, result.Tags[0].Tag.Id)
+
+	assert.NoError(t, trash.RestoreItem(ctx, item.Id))
+	assert.NoError(t, trash.RestoreTag(ctx, category.Id))
+	result, err = trash.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+	assert.Empty(t, result.Tags)
+
+	restored, err := db.GetItem(ctx, item.Id)
+	assert.NoError(t, err)
+	assert.Len(t, restored.Highlights, 1)
+	assert.ErrorIs(t, trash.RestoreItem(ctx, item.Id), gorm.ErrRecordNotFound)
+}
+
+func TestPurge(t *testing.T) {
+	db := setupNewDb(t, "trash-purge.sqlite")
+	ctx := context.Background()
+	remover := &removerRecorder{}
+	trash := New(db, remover, time.Hour)
+
+	item := &model.Item{Title: "item", Origin: "origin", MainCoverUrl: ptr.To(".internal-storage/main-covers/1/main.png"),
+		Covers: []model.Cover{{Url: ".internal-storage/covers/1/1.png"}, {Url: "http://example.com/cover.png"}}}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, item))
+	live := &model.Item{Title: "live", Origin: "origin"}
+	assert.NoError(t, db.CreateOrUpdateItem(ctx, live))
+
+	assert.ErrorIs(t, trash.PurgeItem(ctx, live.Id), gorm.ErrRecordNotFound)
+	assert.NoError(t, db.RemoveItem(ctx, item.Id))
+
+	result, err := trash.PurgeExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, model.PurgeResult{}, *result)
+
+	result, err = trash.PurgeOlderThan(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Items)
+	assert.ElementsMatch(t, []string{
+		"covers/1", "main-covers/1", "previews/1",
+		".internal-storage/covers/1/1.png", ".internal-storage/main-covers/1/main.png",
+	}, remover.removed)
+
+	deleted, err := db.GetDeletedItems(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, *deleted)
+	_, err = db.GetItem(ctx, live.Id)
+	assert.NoError(t, err)
+
+	category := &model.Tag{Title: "category"}
+	assert.NoError(t, db.CreateOrUpdateTag(ctx, category))
+	assert.NoError(t, db.RemoveTag(ctx, category.Id))
+	assert.NoError(t, trash.PurgeTag(ctx, category.Id))
+	assert.ErrorIs(t, trash.PurgeTag(ctx, category.Id), gorm.ErrRecordNotFound)
+}
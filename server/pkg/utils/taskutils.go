@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+	"my-collection/server/pkg/model"
+)
+
+const taskPriorityContextKey contextKey = "task-priority"
+
+// ContextWithTaskPriority sets the priority of the tasks enqueued with the context
+func ContextWithTaskPriority(parent context.Context, priority model.TaskPriority) context.Context {
+	return context.WithValue(parent, taskPriorityContextKey, priority)
+}
+
+func GetTaskPriority(ctx context.Context) model.TaskPriority {
+	priority, ok := ctx.Value(taskPriorityContextKey).(model.TaskPriority)
+	if !ok {
+		return model.TASK_PRIORITY_NORMAL
+	}
+
+	return priority
+}
@@ -209,13 +209,13 @@ func (f *ServerIntegrationFramework) GetAllItemsViaAPI() ([]model.Item, error) {
 		return nil, fmt.Errorf("API call failed with status %d", resp.StatusCode)
 	}
 
-	var items []model.Item
-	err = f.parseJSONResponse(resp, &items)
+	var page model.ItemsPage
+	err = f.parseJSONResponse(resp, &page)
 	if err != nil {
 		return nil, err
 	}
 
-	return items, nil
+	return page.Items, nil
 }
 
 // GetItemViaAPI gets an item by ID via HTTP API
@@ -57,7 +57,7 @@ func NewIntegrationTestFramework(t *testing.T) *IntegrationTestFramework {
 	require.NoError(t, err)
 
 	// Create FsManager with filter that accepts all files
-	fsManager, err := fssync.NewFsManager(context.Background(), database, testFileFilter{}, time.Hour) // Long interval since we sync manually
+	fsManager, err := fssync.NewFsManager(context.Background(), database, testFileFilter{}, time.Hour, false, 0, nil) // Long interval since we sync manually
 	require.NoError(t, err)
 
 	return &IntegrationTestFramework{
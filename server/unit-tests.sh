@@ -1,3 +1,3 @@
 #!/bin/bash
 
-go test ./pkg/... -count=1 -cover  $@ || exit 1
+go test -tags sqlite_fts5 ./pkg/... -count=1 -cover  $@ || exit 1